@@ -2,75 +2,270 @@ package apply
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/codeowners"
 	"github.com/UnitVectorY-Labs/bulkfilepr/internal/config"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
 	"github.com/UnitVectorY-Labs/bulkfilepr/internal/git"
 	"github.com/UnitVectorY-Labs/bulkfilepr/internal/hash"
 )
 
+// autostashMessage is the message used for stashes created by autostash.
+const autostashMessage = "bulkfilepr autostash"
+
+// ContentTrailer is the commit trailer that records the SHA-256 of the
+// content a commit wrote, so commits can be traced to a standard version.
+const ContentTrailer = "Bulkfilepr-Content-SHA256"
+
 // Result represents the outcome of an apply operation.
 type Result struct {
 	// DefaultBranch is the detected default branch name.
 	DefaultBranch string
+	// DefaultBranchSource describes how the default branch was determined.
+	DefaultBranchSource string
+	// BaseBranch is the branch the change is based on and the PR targets.
+	BaseBranch string
 	// Action describes what action was taken or would be taken.
 	Action string
 	// BranchName is the name of the branch that was/would be created.
 	BranchName string
 	// PRURL is the URL of the created PR (only set in non-dry-run mode).
 	PRURL string
+	// PatchFile is the path of the exported mbox (only set in export mode).
+	PatchFile string
+	// CommitSHA is the SHA of the created commit (only set for direct pushes
+	// and local-only commits).
+	CommitSHA string
 	// NoActionReason explains why no action was taken (if applicable).
 	NoActionReason string
+	// Stashed reports whether local changes were autostashed for the run.
+	Stashed bool
+	// AutoMerge reports whether auto-merge was enabled on the created PR.
+	AutoMerge bool
+	// AutoMergeReason explains why auto-merge could not be enabled (if requested).
+	AutoMergeReason string
+	// Warnings lists problems that did not stop the run, such as metadata
+	// that could not be applied to the created PR.
+	Warnings []string
 }
 
 // Applier handles the apply logic for updating files in a repository.
 type Applier struct {
 	cfg        *config.Config
 	gitOps     git.Operations
+	forgeOps   forge.Forge
 	repoDir    string
 	newContent []byte
+	now        func() time.Time
+	forks      forkTarget
+	// codeOwners are the CODEOWNERS owners of the file on the current base,
+	// requested as reviewers alongside the configured ones.
+	codeOwners []string
+	// facts describe the change on the current base for the PR body.
+	facts changeFacts
+	// message, title and body are the rendered commit message, PR title and
+	// PR body for the current base.
+	message string
+	title   string
+	body    string
 }
 
 // NewApplier creates a new Applier instance.
-func NewApplier(cfg *config.Config, gitOps git.Operations, newContent []byte) *Applier {
+func NewApplier(cfg *config.Config, gitOps git.Operations, forgeOps forge.Forge, newContent []byte) *Applier {
 	return &Applier{
 		cfg:        cfg,
 		gitOps:     gitOps,
+		forgeOps:   forgeOps,
 		repoDir:    cfg.Repo,
 		newContent: newContent,
+		now:        time.Now,
+		forks:      newForkTarget(cfg.GetForkRemote()),
 	}
 }
 
-// Run executes the apply operation and returns the result.
+// Run executes the apply operation against the default branch and returns the result.
+// If restoring autostashed changes fails after the update, the result is
+// returned alongside the error.
 func (a *Applier) Run() (*Result, error) {
-	result := &Result{}
+	results, err := a.runWithAutostash(func(defaultBranch string) ([]*Result, error) {
+		result, err := a.runBase(defaultBranch, defaultBranch)
+		if err != nil {
+			return nil, err
+		}
+		return []*Result{result}, nil
+	})
+	if len(results) == 0 {
+		return nil, err
+	}
+	return results[0], err
+}
+
+// RunAll executes the apply operation once per configured base branch and
+// returns one result per base. When no bases are configured it behaves like Run.
+// Results for bases processed before a failure are returned alongside the error.
+func (a *Applier) RunAll() ([]*Result, error) {
+	if len(a.cfg.Bases) == 0 {
+		result, err := a.Run()
+		if result == nil {
+			return nil, err
+		}
+		return []*Result{result}, err
+	}
+
+	return a.runWithAutostash(func(defaultBranch string) ([]*Result, error) {
+		bases, err := a.resolveBases()
+		if err != nil {
+			return nil, err
+		}
+
+		results := make([]*Result, 0, len(bases))
+		for _, base := range bases {
+			result, err := a.runBase(defaultBranch, base)
+			if err != nil {
+				return results, fmt.Errorf("base %s: %w", base, err)
+			}
+			results = append(results, result)
+		}
+		return results, nil
+	})
+}
 
+// runWithAutostash detects the default branch and calls fn with it. When
+// autostash is enabled and the working tree is dirty, local changes (including
+// untracked files) are stashed first, and afterwards the original branch is
+// checked out again and the stash is popped.
+func (a *Applier) runWithAutostash(fn func(defaultBranch string) ([]*Result, error)) ([]*Result, error) {
 	// Step 1: Detect default branch
-	defaultBranch, err := a.gitOps.GetDefaultBranch()
+	defaultBranch, source, err := a.detectDefaultBranch()
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect default branch: %w", err)
 	}
-	result.DefaultBranch = defaultBranch
+	run := func() ([]*Result, error) {
+		results, err := fn(defaultBranch)
+		for _, result := range results {
+			result.DefaultBranchSource = source
+		}
+		return results, err
+	}
 
-	// Step 2: Verify on default branch or switch if clean
+	if !a.cfg.AutoStash {
+		return run()
+	}
+	clean, err := a.gitOps.IsWorkingTreeClean()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check working tree status: %w", err)
+	}
+	if clean {
+		return run()
+	}
+
+	originalBranch, err := a.gitOps.GetCurrentBranch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if err := a.gitOps.Stash(autostashMessage); err != nil {
+		return nil, fmt.Errorf("failed to stash local changes: %w", err)
+	}
+
+	results, runErr := run()
+	for _, result := range results {
+		result.Stashed = true
+	}
+
+	if err := a.restoreAutostash(originalBranch); err != nil {
+		if runErr != nil {
+			return results, fmt.Errorf("%w; additionally, %v", runErr, err)
+		}
+		return results, err
+	}
+	return results, runErr
+}
+
+// detectDefaultBranch returns the default branch and the source it came from.
+// A configured override wins; otherwise the forge is asked, falling back to
+// detection from git alone if the forge cannot answer.
+func (a *Applier) detectDefaultBranch() (string, string, error) {
+	if a.cfg.DefaultBranch != "" {
+		return a.cfg.DefaultBranch, string(git.SourceOverride), nil
+	}
+
+	name, forgeErr := a.forgeOps.DefaultBranch()
+	if forgeErr == nil {
+		return name, a.forgeOps.Name(), nil
+	}
+	name, source, err := a.gitOps.GetDefaultBranch(a.cfg.Remote)
+	if err != nil {
+		return "", "", errors.Join(fmt.Errorf("%s: %w", a.forgeOps.Name(), forgeErr), err)
+	}
+	return name, string(source), nil
+}
+
+// restoreAutostash switches back to the original branch and pops the stash.
+// If the pop conflicts, the partial pop is reset away and the untracked files
+// it restored are removed, so the working tree is left clean, the changes
+// remain safely in the stash and 'git stash pop' can be run again.
+func (a *Applier) restoreAutostash(originalBranch string) error {
+	if err := a.gitOps.SwitchBranch(originalBranch); err != nil {
+		return fmt.Errorf("failed to switch back to %q to restore stashed changes: your changes remain in the stash (see 'git stash list'): %w", originalBranch, err)
+	}
+	if err := a.gitOps.StashPop(); err != nil {
+		if resetErr := a.undoStashPop(); resetErr != nil {
+			return fmt.Errorf("restoring stashed changes on %q failed and the working tree could not be reset: resolve manually, your changes remain in the stash: %w", originalBranch, err)
+		}
+		return fmt.Errorf("restoring stashed changes on %q conflicted: the working tree was reset and your changes remain in the stash, run 'git stash pop' to resolve manually: %w", originalBranch, err)
+	}
+	return nil
+}
+
+// undoStashPop resets the changes of a failed stash pop. Untracked files are
+// not touched by the reset, so the ones the stash holds are removed as well.
+func (a *Applier) undoStashPop() error {
+	if err := a.gitOps.ResetHard("HEAD"); err != nil {
+		return err
+	}
+	untracked, err := a.gitOps.StashedUntrackedFiles()
+	if err != nil {
+		return err
+	}
+	return a.gitOps.CleanFiles(untracked)
+}
+
+// runBase executes the apply operation using base as the branch to start from
+// and the target of the PR.
+func (a *Applier) runBase(defaultBranch, base string) (*Result, error) {
+	result := &Result{DefaultBranch: defaultBranch, BaseBranch: base}
+
+	branchKind := "default branch"
+	if base != defaultBranch {
+		branchKind = "base branch"
+	}
+
+	// Step 2: Verify on base branch or switch if clean
 	currentBranch, err := a.gitOps.GetCurrentBranch()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current branch: %w", err)
 	}
 
-	if currentBranch != defaultBranch {
+	if currentBranch != base {
 		// Check if working tree is clean
 		clean, err := a.gitOps.IsWorkingTreeClean()
 		if err != nil {
 			return nil, fmt.Errorf("failed to check working tree status: %w", err)
 		}
 		if !clean {
-			return nil, fmt.Errorf("not on default branch and working tree is dirty: current branch is %q, expected %q. Please commit or stash your changes", currentBranch, defaultBranch)
+			return nil, fmt.Errorf("not on %s and working tree is dirty: current branch is %q, expected %q. Please commit or stash your changes", branchKind, currentBranch, base)
 		}
-		// Working tree is clean, switch to default branch
-		if err := a.gitOps.SwitchBranch(defaultBranch); err != nil {
-			return nil, fmt.Errorf("failed to switch to default branch %q: %w", defaultBranch, err)
+		// Working tree is clean, switch to base branch
+		if err := a.gitOps.SwitchBranch(base); err != nil {
+			return nil, fmt.Errorf("failed to switch to %s %q: %w", branchKind, base, err)
 		}
 	}
 
@@ -83,7 +278,7 @@ func (a *Applier) Run() (*Result, error) {
 		return nil, fmt.Errorf("working tree is not clean: please commit or stash your changes")
 	}
 
-	// Step 4: Evaluate mode conditions
+	// Step 4: Evaluate mode conditions against the base branch's copy of the file
 	shouldUpdate, reason, err := a.evaluateMode()
 	if err != nil {
 		return nil, err
@@ -93,13 +288,30 @@ func (a *Applier) Run() (*Result, error) {
 		result.NoActionReason = reason
 		return result, nil
 	}
+	if err := a.loadChangeFacts(defaultBranch, base); err != nil {
+		return nil, err
+	}
+
+	// Direct push commits onto the base branch itself, so there is no branch
+	// to name or PR to open
+	if a.cfg.Direct {
+		return a.pushDirect(result, base)
+	}
 
 	// Step 5: Determine branch name
-	branchName := a.determineBranchName()
+	branchName := a.determineBranchName(defaultBranch, base)
 	result.BranchName = branchName
+	if err := a.renderMessages(branchName); err != nil {
+		return nil, err
+	}
 
-	// Step 6: Check if branch already exists (idempotency)
-	branchExists, err := a.gitOps.BranchExists(branchName, a.cfg.Remote)
+	// Step 6: Check if branch already exists (idempotency), on the remote it
+	// is pushed to
+	pushRemote := a.cfg.Remote
+	if a.cfg.Fork {
+		pushRemote = a.forks.remote
+	}
+	branchExists, err := a.gitOps.BranchExists(branchName, pushRemote)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check if branch exists: %w", err)
 	}
@@ -111,20 +323,41 @@ func (a *Applier) Run() (*Result, error) {
 	// Step 7: Execute update (or report dry-run)
 	if a.cfg.DryRun {
 		result.Action = "would update"
+		if a.cfg.ExportPatch != "" {
+			result.Action = "would export"
+			result.PatchFile = a.patchFileName(defaultBranch, base)
+		}
 		return result, nil
 	}
 
+	if err := a.checkCommit(result, base); err != nil {
+		return nil, err
+	}
+
+	// Read the owners from the base's CODEOWNERS before the file changes, in
+	// case the file being updated is CODEOWNERS itself
+	if err := a.loadCodeOwners(result); err != nil {
+		return nil, err
+	}
+
+	// Refuse metadata the forge cannot apply before the branch is created
+	if a.cfg.ExportPatch == "" {
+		if err := forge.CheckMetadata(a.forgeOps, a.prRequest(base, branchName)); err != nil {
+			return nil, fmt.Errorf("failed to create PR: %w", err)
+		}
+	}
+
 	// Step 8: Create branch
 	if err := a.gitOps.CreateBranch(branchName); err != nil {
 		return nil, fmt.Errorf("failed to create branch: %w", err)
 	}
 
-	// Use a cleanup function to switch back to default branch on error
+	// Use a cleanup function to switch back to base branch on error
 	var updateErr error
 	defer func() {
 		if updateErr != nil {
-			// Best effort: switch back to default branch on error
-			_ = a.gitOps.SwitchBranch(defaultBranch)
+			// Best effort: switch back to base branch on error
+			_ = a.gitOps.SwitchBranch(base)
 		}
 	}()
 
@@ -141,32 +374,430 @@ func (a *Applier) Run() (*Result, error) {
 	}
 
 	// Step 11: Commit
-	if err := a.gitOps.Commit(a.cfg.GetCommitMessage()); err != nil {
+	if err := a.gitOps.Commit(a.commitMessage(base, branchName), a.commitOptions()); err != nil {
 		updateErr = fmt.Errorf("failed to commit: %w", err)
 		return nil, updateErr
 	}
 
-	// Step 12: Push
-	if err := a.gitOps.Push(a.cfg.Remote, branchName); err != nil {
-		updateErr = fmt.Errorf("failed to push: %w", err)
-		return nil, updateErr
+	// In export mode, write the commit out as a patch and remove the branch
+	if a.cfg.ExportPatch != "" {
+		patchFile, err := a.exportPatch(defaultBranch, base, branchName)
+		if err != nil {
+			// Best effort: drop the branch so a later run can retry the export
+			_ = a.gitOps.SwitchBranch(base)
+			_ = a.gitOps.DeleteBranch(branchName)
+			return nil, err
+		}
+		result.PatchFile = patchFile
+		result.Action = "exported"
+		return result, nil
 	}
 
-	// Step 13: Create PR
-	prURL, err := a.gitOps.CreatePR(defaultBranch, branchName, a.cfg.GetPRTitle(), a.cfg.GetPRBody(), a.cfg.Draft)
+	// In local-only mode, stop after committing and record what publish needs
+	if a.cfg.NoPush {
+		commitSHA, err := a.gitOps.HeadCommit()
+		if err != nil {
+			updateErr = fmt.Errorf("failed to get commit: %w", err)
+			return nil, updateErr
+		}
+		pending := pendingBranch{PR: a.prRequest(base, branchName), Fork: a.cfg.Fork}
+		if a.cfg.AutoMerge {
+			pending.AutoMerge = a.cfg.GetMergeMethod()
+		}
+		if err := recordPending(a.gitOps, branchName, pending); err != nil {
+			updateErr = fmt.Errorf("failed to record pending publication: %w", err)
+			return nil, updateErr
+		}
+		result.CommitSHA = commitSHA
+		result.Action = "committed"
+		_ = a.gitOps.SwitchBranch(base)
+		return result, nil
+	}
+
+	// Step 12 & 13: Push and create PR, from the fork if configured
+	remote, req := a.cfg.Remote, a.prRequest(base, branchName)
+	if a.cfg.Fork {
+		if _, err := a.forks.ensure(a.gitOps, a.forgeOps); err != nil {
+			updateErr = fmt.Errorf("failed to set up fork: %w", err)
+			return nil, updateErr
+		}
+		remote, req.Head = a.forks.remote, a.forks.head(branchName)
+	}
+	pr, warnings, err := submit(a.gitOps, a.forgeOps, remote, branchName, req)
 	if err != nil {
-		updateErr = fmt.Errorf("failed to create PR: %w", err)
+		updateErr = err
 		return nil, updateErr
 	}
-	result.PRURL = prURL
+	result.PRURL = pr.URL
+	result.Warnings = append(result.Warnings, warnings...)
 	result.Action = "updated"
+	if a.cfg.AutoMerge {
+		enableAutoMerge(a.forgeOps, pr, a.cfg.GetMergeMethod(), result)
+	}
 
-	// Step 14: Switch back to default branch (best effort)
-	_ = a.gitOps.SwitchBranch(defaultBranch)
+	// Step 14: Switch back to base branch (best effort)
+	_ = a.gitOps.SwitchBranch(base)
 
 	return result, nil
 }
 
+// commitMessage returns the commit message, with any trailers the forge needs
+// to track the change. Exported patches are left as configured.
+func (a *Applier) commitMessage(base, head string) string {
+	message := a.message
+	if uploader, ok := a.forgeOps.(forge.ChangeUploader); ok && a.cfg.ExportPatch == "" {
+		message = uploader.CommitMessage(message, a.prRequest(base, head))
+	}
+	return message
+}
+
+// commitOptions returns the commit's identity and signing settings and its
+// trailers: the new content's hash, the configured trailers and co-authors,
+// and the sign-off if requested.
+func (a *Applier) commitOptions() git.CommitOptions {
+	opts := git.CommitOptions{
+		Signoff:        a.cfg.Signoff,
+		AuthorName:     a.cfg.AuthorName,
+		AuthorEmail:    a.cfg.AuthorEmail,
+		CommitterName:  a.cfg.GetCommitterName(),
+		CommitterEmail: a.cfg.GetCommitterEmail(),
+		Sign:           a.cfg.SignsCommits(),
+		SigningKey:     a.cfg.SigningKey,
+		SigningFormat:  a.cfg.SigningFormat,
+	}
+	if !a.cfg.NoContentTrailer {
+		opts.Trailers = append(opts.Trailers, git.Trailer{Key: ContentTrailer, Value: a.facts.NewSHA256})
+	}
+	for _, trailer := range a.cfg.Trailers {
+		// Validated with the config
+		key, value, _ := config.ParseTrailer(trailer)
+		opts.Trailers = append(opts.Trailers, git.Trailer{Key: key, Value: value})
+	}
+	for _, coAuthor := range a.cfg.CoAuthors {
+		opts.Trailers = append(opts.Trailers, git.Trailer{Key: "Co-authored-by", Value: coAuthor})
+	}
+	return opts
+}
+
+// checkCommit fails before anything changes when the commit could not be
+// made or would be rejected: when git has no identity to commit with, or when
+// the forge reports that the base requires signed commits and signing is not
+// configured. If the forge cannot tell, a warning is added to the result.
+func (a *Applier) checkCommit(result *Result, base string) error {
+	if err := a.gitOps.CheckIdentity(a.commitOptions()); err != nil {
+		if errors.Is(err, git.ErrNoIdentity) {
+			return fmt.Errorf("%w: set user.name and user.email in git config, or pass --author-name and --author-email", err)
+		}
+		return err
+	}
+
+	// Exported patches are applied by someone else, who signs them
+	checker, ok := a.forgeOps.(forge.ProtectionChecker)
+	if !ok || a.cfg.SignsCommits() || a.cfg.ExportPatch != "" {
+		return nil
+	}
+	signing, err := a.gitOps.CommitSigningEnabled()
+	if err != nil {
+		return err
+	}
+	if signing {
+		return nil
+	}
+	required, err := checker.RequiresSignedCommits(base)
+	if err != nil {
+		// The check is best effort, e.g. gh may be missing or unauthenticated
+		result.Warnings = append(result.Warnings, fmt.Sprintf("could not check whether %s requires signed commits: %v", base, err))
+		return nil
+	}
+	if required {
+		return fmt.Errorf("branch %q requires signed commits but signing is not configured: pass --sign or --signing-key, or set commit.gpgsign", base)
+	}
+	return nil
+}
+
+// submit pushes the branch to the remote and creates its PR or, for forges
+// that review pushed commits directly, uploads the branch as a change. The
+// PR head in req may name the branch in a fork. Metadata the forge could not
+// apply to the created PR is returned as warnings.
+func submit(gitOps git.Operations, forgeOps forge.Forge, remote, branch string, req forge.PRRequest) (*forge.PullRequest, []string, error) {
+	// Branches recorded with --no-push may be published through another forge
+	if err := forge.CheckMetadata(forgeOps, req); err != nil {
+		return nil, nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+	if uploader, ok := forgeOps.(forge.ChangeUploader); ok {
+		output, err := gitOps.PushRefspec(remote, uploader.UploadRefspec(req))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to upload change: %w", err)
+		}
+		change, err := uploader.ParseUpload(output)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to upload change: %w", err)
+		}
+		return change, nil, nil
+	}
+
+	if err := gitOps.Push(remote, branch); err != nil {
+		return nil, nil, fmt.Errorf("failed to push: %w", err)
+	}
+	pr, err := forgeOps.CreatePR(req)
+	var metaErr *forge.MetadataError
+	if errors.As(err, &metaErr) && pr != nil {
+		warnings := make([]string, len(metaErr.Errs))
+		for i, e := range metaErr.Errs {
+			warnings[i] = e.Error()
+		}
+		return pr, warnings, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+	return pr, nil, nil
+}
+
+// enableAutoMerge turns on auto-merge for a created PR and records in the
+// result whether the forge allowed it. A refusal leaves the PR open for a
+// manual merge, so it is not an error.
+func enableAutoMerge(forgeOps forge.Forge, pr *forge.PullRequest, method string, result *Result) {
+	merger, ok := forgeOps.(forge.AutoMerger)
+	if !ok {
+		result.AutoMergeReason = fmt.Sprintf("forge %s does not support auto-merge", forgeOps.Name())
+		return
+	}
+	if err := merger.EnableAutoMerge(pr, forge.MergeMethod(method)); err != nil {
+		result.AutoMergeReason = err.Error()
+		return
+	}
+	result.AutoMerge = true
+}
+
+// prRequest builds the PR request for a branch from the configuration.
+func (a *Applier) prRequest(base, head string) forge.PRRequest {
+	return forge.PRRequest{
+		Base:               base,
+		Head:               head,
+		Title:              a.title,
+		Body:               a.body,
+		Draft:              a.cfg.Draft,
+		Labels:             a.cfg.Labels,
+		Assignees:          a.cfg.Assignees,
+		Reviewers:          a.reviewers(),
+		Milestone:          a.cfg.Milestone,
+		Projects:           a.cfg.Projects,
+		WorkItems:          a.cfg.WorkItems,
+		AutoComplete:       a.cfg.AutoComplete,
+		Topic:              a.cfg.Topic,
+		Hashtags:           a.cfg.Hashtags,
+		RemoveSourceBranch: a.cfg.RemoveSourceBranch,
+	}
+}
+
+// loadCodeOwners reads the reviewers for the file from the repository's
+// CODEOWNERS file, unless disabled or no PR will be opened. Team owners are
+// dropped with a warning on forges that cannot request reviews from teams.
+func (a *Applier) loadCodeOwners(result *Result) error {
+	a.codeOwners = nil
+	if a.cfg.NoCodeOwners || a.cfg.ExportPatch != "" {
+		return nil
+	}
+	file, err := codeowners.Load(a.repoDir)
+	if err != nil {
+		return fmt.Errorf("failed to read CODEOWNERS: %w", err)
+	}
+	if file == nil {
+		return nil
+	}
+	for _, owner := range file.Owners(a.cfg.RepoPath) {
+		// Email owners cannot be requested as reviewers by name
+		name := strings.TrimPrefix(owner, "@")
+		if strings.Contains(name, "@") {
+			continue
+		}
+		if forge.IsTeam(name) && !forge.Supports(a.forgeOps, forge.MetadataTeamReviewers) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("CODEOWNERS team %s is not requested as a reviewer: forge %s does not support team reviewers", owner, a.forgeOps.Name()))
+			continue
+		}
+		a.codeOwners = append(a.codeOwners, name)
+	}
+	return nil
+}
+
+// reviewers returns the configured reviewers followed by the CODEOWNERS
+// owners of the file, without duplicates.
+func (a *Applier) reviewers() []string {
+	if len(a.codeOwners) == 0 {
+		return a.cfg.Reviewers
+	}
+	seen := map[string]bool{}
+	var reviewers []string
+	for _, reviewer := range append(append([]string{}, a.cfg.Reviewers...), a.codeOwners...) {
+		key := strings.ToLower(strings.TrimPrefix(reviewer, "@"))
+		if !seen[key] {
+			seen[key] = true
+			reviewers = append(reviewers, reviewer)
+		}
+	}
+	return reviewers
+}
+
+// pushDirect commits the new content onto the base branch and pushes it.
+// It refuses to push unless the remote tip is the commit the change is based
+// on, and the push itself is guarded by a lease on that commit. On failure the
+// local base branch is reset to where it started.
+func (a *Applier) pushDirect(result *Result, base string) (*Result, error) {
+	result.BranchName = base
+	if err := a.renderMessages(base); err != nil {
+		return nil, err
+	}
+
+	localSHA, err := a.gitOps.HeadCommit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local commit: %w", err)
+	}
+	remoteSHA, err := a.gitOps.RemoteBranchHead(a.cfg.Remote, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote commit: %w", err)
+	}
+	if remoteSHA == "" {
+		return nil, fmt.Errorf("branch %q does not exist on remote %q", base, a.cfg.Remote)
+	}
+	if remoteSHA != localSHA {
+		return nil, fmt.Errorf("local %s is at %s but %s/%s is at %s: pull or rebase before pushing directly", base, localSHA, a.cfg.Remote, base, remoteSHA)
+	}
+
+	if a.cfg.DryRun {
+		result.Action = "would push"
+		return result, nil
+	}
+	if err := a.checkCommit(result, base); err != nil {
+		return nil, err
+	}
+
+	// Use a cleanup function to drop the local commit on error
+	var updateErr error
+	defer func() {
+		if updateErr != nil {
+			// Best effort: restore the base branch to the remote tip
+			_ = a.gitOps.ResetHard(localSHA)
+		}
+	}()
+
+	if err := git.WriteFile(a.repoDir, a.cfg.RepoPath, a.newContent); err != nil {
+		updateErr = fmt.Errorf("failed to write file: %w", err)
+		return nil, updateErr
+	}
+	if err := a.gitOps.AddFile(a.cfg.RepoPath); err != nil {
+		updateErr = fmt.Errorf("failed to stage file: %w", err)
+		return nil, updateErr
+	}
+	if err := a.gitOps.Commit(a.commitMessage(base, base), a.commitOptions()); err != nil {
+		updateErr = fmt.Errorf("failed to commit: %w", err)
+		return nil, updateErr
+	}
+	commitSHA, err := a.gitOps.HeadCommit()
+	if err != nil {
+		updateErr = fmt.Errorf("failed to get commit: %w", err)
+		return nil, updateErr
+	}
+	if err := a.gitOps.PushWithLease(a.cfg.Remote, base, remoteSHA); err != nil {
+		updateErr = fmt.Errorf("failed to push: %w", err)
+		return nil, updateErr
+	}
+
+	result.CommitSHA = commitSHA
+	result.Action = "pushed"
+	return result, nil
+}
+
+// exportPatch writes the commit on branch as an mbox into the export directory,
+// using the PR title and body as the cover letter. The branch is deleted
+// afterwards so the repository is left as it was found.
+func (a *Applier) exportPatch(defaultBranch, base, branch string) (string, error) {
+	description := a.title + "\n\n" + a.body
+	if err := a.gitOps.SetBranchConfig(branch, "description", description); err != nil {
+		return "", fmt.Errorf("failed to set cover letter: %w", err)
+	}
+	patch, err := a.gitOps.FormatPatch(base, branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to export patch: %w", err)
+	}
+
+	patchFile := a.patchFileName(defaultBranch, base)
+	if err := os.MkdirAll(a.cfg.ExportPatch, 0755); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %w", err)
+	}
+	if err := os.WriteFile(patchFile, []byte(patch), 0644); err != nil {
+		return "", fmt.Errorf("failed to write patch: %w", err)
+	}
+
+	if err := a.gitOps.SwitchBranch(base); err != nil {
+		return "", fmt.Errorf("failed to switch back to %s: %w", base, err)
+	}
+	if err := a.gitOps.DeleteBranch(branch); err != nil {
+		return "", fmt.Errorf("failed to delete branch after export: %w", err)
+	}
+	return patchFile, nil
+}
+
+// patchFileName returns the mbox path for the repository and base, named after
+// the repository directory with the base appended for non-default bases.
+func (a *Applier) patchFileName(defaultBranch, base string) string {
+	name := "repo"
+	if absRepo, err := filepath.Abs(a.repoDir); err == nil {
+		name = filepath.Base(absRepo)
+	}
+	if base != defaultBranch {
+		name += "-" + slugify(base)
+	}
+	return filepath.Join(a.cfg.ExportPatch, name+".mbox")
+}
+
+// resolveBases expands the configured bases into concrete branch names.
+// Entries containing glob characters are matched against the remote's branches.
+func (a *Applier) resolveBases() ([]string, error) {
+	var remoteBranches []string
+	seen := make(map[string]bool)
+	bases := []string{}
+
+	for _, pattern := range a.cfg.Bases {
+		if !strings.ContainsAny(pattern, "*?[") {
+			if !seen[pattern] {
+				seen[pattern] = true
+				bases = append(bases, pattern)
+			}
+			continue
+		}
+
+		if remoteBranches == nil {
+			branches, err := a.gitOps.ListRemoteBranches(a.cfg.Remote)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list remote branches: %w", err)
+			}
+			sort.Strings(branches)
+			remoteBranches = branches
+		}
+
+		matched := false
+		for _, branch := range remoteBranches {
+			ok, err := path.Match(pattern, branch)
+			if err != nil {
+				return nil, fmt.Errorf("invalid base pattern %q: %w", pattern, err)
+			}
+			if !ok {
+				continue
+			}
+			matched = true
+			if !seen[branch] {
+				seen[branch] = true
+				bases = append(bases, branch)
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("no branches on remote %q match base pattern %q", a.cfg.Remote, pattern)
+		}
+	}
+	return bases, nil
+}
+
 // evaluateMode checks if the update should proceed based on the mode.
 // Returns (shouldUpdate, reason, error).
 func (a *Applier) evaluateMode() (bool, string, error) {
@@ -208,7 +839,7 @@ func (a *Applier) evaluateMode() (bool, string, error) {
 			return false, "", fmt.Errorf("failed to read existing file: %w", err)
 		}
 		existingHash := hash.SHA256Bytes(existingContent)
-		
+
 		// Check if the existing hash matches any of the expected hashes
 		expectedHashes := a.cfg.GetExpectedHashes()
 		hashMatches := false
@@ -218,14 +849,14 @@ func (a *Applier) evaluateMode() (bool, string, error) {
 				break
 			}
 		}
-		
+
 		if !hashMatches {
 			if len(expectedHashes) == 1 {
 				return false, fmt.Sprintf("file hash mismatch: expected %s, got %s", expectedHashes[0], existingHash), nil
 			}
 			return false, fmt.Sprintf("file hash mismatch: expected one of [%s], got %s", strings.Join(expectedHashes, ", "), existingHash), nil
 		}
-		
+
 		if bytes.Equal(existingContent, a.newContent) {
 			return false, "file content is already identical", nil
 		}
@@ -236,13 +867,59 @@ func (a *Applier) evaluateMode() (bool, string, error) {
 	}
 }
 
-// determineBranchName returns the branch name to use.
-func (a *Applier) determineBranchName() string {
+// CampaignBranch returns the branch the change is made on for a repository's
+// default branch, which names the campaign across repositories. It does not
+// depend on the repository as long as the branch template has no {base}.
+func (a *Applier) CampaignBranch() string {
+	return a.determineBranchName("", "")
+}
+
+// determineBranchName returns the branch name to use for the given base.
+// Branches for bases other than the default branch get a base-specific suffix
+// unless the template already includes {base}.
+func (a *Applier) determineBranchName(defaultBranch, base string) string {
+	suffix := ""
+	if base != defaultBranch {
+		suffix = "-" + slugify(base)
+	}
+
 	if a.cfg.Branch != "" {
-		return a.cfg.Branch
+		return a.cfg.Branch + suffix
 	}
-	// Generate branch name from hash of new file content
+	template := a.cfg.GetBranchTemplate()
+	if strings.Contains(template, "{base}") {
+		suffix = ""
+	}
+
+	// Generate branch name from the template; the hash is taken from the new
+	// file content and the path slug keeps different destinations apart
 	contentHash := hash.SHA256Bytes(a.newContent)
-	truncatedHash := hash.TruncatedHash(contentHash, 12)
-	return fmt.Sprintf("bulkfilepr/%s", truncatedHash)
+	replacer := strings.NewReplacer(
+		"{prefix}", a.cfg.GetBranchPrefix(),
+		"{path-slug}", slugify(a.cfg.RepoPath),
+		"{hash}", hash.TruncatedHash(contentHash, 12),
+		"{mode}", string(a.cfg.Mode),
+		"{date}", a.now().Format("20060102"),
+		"{base}", slugify(base),
+	)
+	return replacer.Replace(template) + suffix
+}
+
+// slugify converts a value into a lowercase, branch-safe slug.
+// Runs of characters other than letters and digits become a single dash.
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
 }
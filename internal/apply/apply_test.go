@@ -1,12 +1,16 @@
 package apply
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/UnitVectorY-Labs/bulkfilepr/internal/config"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
 	"github.com/UnitVectorY-Labs/bulkfilepr/internal/git"
 	"github.com/UnitVectorY-Labs/bulkfilepr/internal/hash"
 )
@@ -14,6 +18,7 @@ import (
 func TestApplierUpsertModeNewFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	newContent := []byte("new content\n")
 
 	cfg := &config.Config{
@@ -24,7 +29,7 @@ func TestApplierUpsertModeNewFile(t *testing.T) {
 		Remote:   "origin",
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	result, err := applier.Run()
 
 	if err != nil {
@@ -50,6 +55,7 @@ func TestApplierUpsertModeNewFile(t *testing.T) {
 func TestApplierUpsertModeIdenticalContent(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	content := []byte("existing content\n")
 
 	// Create existing file with same content
@@ -69,7 +75,7 @@ func TestApplierUpsertModeIdenticalContent(t *testing.T) {
 		Remote:   "origin",
 	}
 
-	applier := NewApplier(cfg, mock, content)
+	applier := NewApplier(cfg, mock, forgeMock, content)
 	result, err := applier.Run()
 
 	if err != nil {
@@ -89,6 +95,7 @@ func TestApplierUpsertModeIdenticalContent(t *testing.T) {
 func TestApplierExistsModeFileNotExist(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	newContent := []byte("new content\n")
 
 	cfg := &config.Config{
@@ -99,7 +106,7 @@ func TestApplierExistsModeFileNotExist(t *testing.T) {
 		Remote:   "origin",
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	result, err := applier.Run()
 
 	if err != nil {
@@ -116,6 +123,7 @@ func TestApplierExistsModeFileNotExist(t *testing.T) {
 func TestApplierExistsModeFileExists(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	oldContent := []byte("old content\n")
 	newContent := []byte("new content\n")
 
@@ -136,7 +144,7 @@ func TestApplierExistsModeFileExists(t *testing.T) {
 		Remote:   "origin",
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	result, err := applier.Run()
 
 	if err != nil {
@@ -150,6 +158,7 @@ func TestApplierExistsModeFileExists(t *testing.T) {
 func TestApplierMatchModeHashMismatch(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	existingContent := []byte("existing content\n")
 	newContent := []byte("new content\n")
 
@@ -171,7 +180,7 @@ func TestApplierMatchModeHashMismatch(t *testing.T) {
 		ExpectSHA256: "wronghash123456789",
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	result, err := applier.Run()
 
 	if err != nil {
@@ -188,6 +197,7 @@ func TestApplierMatchModeHashMismatch(t *testing.T) {
 func TestApplierMatchModeHashMatch(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	existingContent := []byte("existing content\n")
 	newContent := []byte("new content\n")
 
@@ -212,7 +222,7 @@ func TestApplierMatchModeHashMatch(t *testing.T) {
 		ExpectSHA256: expectedHash,
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	result, err := applier.Run()
 
 	if err != nil {
@@ -226,6 +236,7 @@ func TestApplierMatchModeHashMatch(t *testing.T) {
 func TestApplierDryRun(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	newContent := []byte("new content\n")
 
 	cfg := &config.Config{
@@ -237,7 +248,7 @@ func TestApplierDryRun(t *testing.T) {
 		DryRun:   true,
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	result, err := applier.Run()
 
 	if err != nil {
@@ -256,14 +267,15 @@ func TestApplierDryRun(t *testing.T) {
 	if len(mock.Commits) != 0 {
 		t.Errorf("Commits length = %d, want 0", len(mock.Commits))
 	}
-	if len(mock.CreatedPRs) != 0 {
-		t.Errorf("CreatedPRs length = %d, want 0", len(mock.CreatedPRs))
+	if len(forgeMock.CreatedPRs) != 0 {
+		t.Errorf("CreatedPRs length = %d, want 0", len(forgeMock.CreatedPRs))
 	}
 }
 
 func TestApplierNotOnDefaultBranchButClean(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	mock.CurrentBranch = "feature-branch"
 	newContent := []byte("new content\n")
 
@@ -275,7 +287,7 @@ func TestApplierNotOnDefaultBranchButClean(t *testing.T) {
 		Remote:   "origin",
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	result, err := applier.Run()
 
 	if err != nil {
@@ -296,6 +308,7 @@ func TestApplierNotOnDefaultBranchButClean(t *testing.T) {
 func TestApplierNotOnDefaultBranchAndDirty(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	mock.CurrentBranch = "feature-branch"
 	mock.IsClean = false
 	newContent := []byte("new content\n")
@@ -308,7 +321,7 @@ func TestApplierNotOnDefaultBranchAndDirty(t *testing.T) {
 		Remote:   "origin",
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	_, err := applier.Run()
 
 	if err == nil {
@@ -319,6 +332,7 @@ func TestApplierNotOnDefaultBranchAndDirty(t *testing.T) {
 func TestApplierDirtyWorkingTree(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	mock.IsClean = false
 	newContent := []byte("new content\n")
 
@@ -330,7 +344,7 @@ func TestApplierDirtyWorkingTree(t *testing.T) {
 		Remote:   "origin",
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	_, err := applier.Run()
 
 	if err == nil {
@@ -341,6 +355,7 @@ func TestApplierDirtyWorkingTree(t *testing.T) {
 func TestApplierCustomBranchName(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	newContent := []byte("new content\n")
 
 	cfg := &config.Config{
@@ -352,7 +367,7 @@ func TestApplierCustomBranchName(t *testing.T) {
 		Branch:   "custom-branch-name",
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	result, err := applier.Run()
 
 	if err != nil {
@@ -369,6 +384,7 @@ func TestApplierCustomBranchName(t *testing.T) {
 func TestApplierAutoBranchName(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	newContent := []byte("new content\n")
 
 	cfg := &config.Config{
@@ -379,25 +395,139 @@ func TestApplierAutoBranchName(t *testing.T) {
 		Remote:   "origin",
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	result, err := applier.Run()
 
 	if err != nil {
 		t.Fatalf("Run() error = %v", err)
 	}
-	// Branch name should be bulkfilepr/{12-char-hash}
+	// Branch name should be bulkfilepr/{path-slug}-{12-char-hash}
 	expectedHash := hash.SHA256Bytes(newContent)
 	truncatedHash := hash.TruncatedHash(expectedHash, 12)
-	expectedBranch := "bulkfilepr/" + truncatedHash
+	expectedBranch := "bulkfilepr/test-file-txt-" + truncatedHash
 
 	if result.BranchName != expectedBranch {
 		t.Errorf("BranchName = %q, want %q", result.BranchName, expectedBranch)
 	}
 }
 
+func TestApplierAutoBranchNameDiffersByPath(t *testing.T) {
+	newContent := []byte("new content\n")
+
+	names := make(map[string]string)
+	for _, repoPath := range []string{"LICENSE", "docs/LICENSE"} {
+		cfg := &config.Config{
+			Mode:     config.ModeUpsert,
+			RepoPath: repoPath,
+			NewFile:  "/path/to/new.txt",
+			Repo:     t.TempDir(),
+			Remote:   "origin",
+			DryRun:   true,
+		}
+
+		result, err := NewApplier(cfg, git.NewMockOperations(), forge.NewMockForge(), newContent).Run()
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if other, ok := names[result.BranchName]; ok {
+			t.Errorf("BranchName %q for %q collides with %q", result.BranchName, repoPath, other)
+		}
+		names[result.BranchName] = repoPath
+	}
+}
+
+func TestApplierBranchTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	newContent := []byte("new content\n")
+
+	cfg := &config.Config{
+		Mode:           config.ModeExists,
+		RepoPath:       ".github/workflows/CI.yml",
+		NewFile:        "/path/to/new.txt",
+		Repo:           tmpDir,
+		Remote:         "origin",
+		DryRun:         true,
+		BranchTemplate: "{prefix}/{date}/{mode}/{path-slug}/{hash}",
+		BranchPrefix:   "standards",
+	}
+	if err := git.WriteFile(tmpDir, cfg.RepoPath, []byte("old content\n")); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
+	applier.now = func() time.Time { return time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC) }
+	result, err := applier.Run()
+
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	truncatedHash := hash.TruncatedHash(hash.SHA256Bytes(newContent), 12)
+	expectedBranch := "standards/20240307/exists/github-workflows-ci-yml/" + truncatedHash
+	if result.BranchName != expectedBranch {
+		t.Errorf("BranchName = %q, want %q", result.BranchName, expectedBranch)
+	}
+	if got := applier.CampaignBranch(); got != expectedBranch {
+		t.Errorf("CampaignBranch() = %q, want %q", got, expectedBranch)
+	}
+}
+
+func TestApplierCampaignBranch(t *testing.T) {
+	mock := git.NewMockOperations()
+	mock.DefaultBranch = "main"
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "LICENSE",
+		NewFile:  "/path/to/new.txt",
+		Repo:     t.TempDir(),
+		Remote:   "origin",
+		Branch:   "bulkfilepr/license",
+		Bases:    []string{"release/1.x"},
+		DryRun:   true,
+	}
+
+	// The campaign branch is the one made for the default branch, even when
+	// only another base was updated
+	applier := NewApplier(cfg, mock, forge.NewMockForge(), []byte("new content\n"))
+	results, err := applier.RunAll()
+	if err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	if len(results) != 1 || results[0].BranchName != "bulkfilepr/license-release-1-x" {
+		t.Fatalf("results = %+v, want one for release/1.x", results)
+	}
+	if got := applier.CampaignBranch(); got != "bulkfilepr/license" {
+		t.Errorf("CampaignBranch() = %q, want %q", got, "bulkfilepr/license")
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "LICENSE", expected: "license"},
+		{input: ".github/workflows/ci.yml", expected: "github-workflows-ci-yml"},
+		{input: "docs//My File.md", expected: "docs-my-file-md"},
+		{input: "release/1.x", expected: "release-1-x"},
+		{input: "--", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := slugify(tt.input); got != tt.expected {
+				t.Errorf("slugify(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestApplierDraftPR(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	newContent := []byte("new content\n")
 
 	cfg := &config.Config{
@@ -409,16 +539,16 @@ func TestApplierDraftPR(t *testing.T) {
 		Draft:    true,
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	_, err := applier.Run()
 
 	if err != nil {
 		t.Fatalf("Run() error = %v", err)
 	}
-	if len(mock.CreatedPRs) != 1 {
-		t.Fatalf("CreatedPRs length = %d, want 1", len(mock.CreatedPRs))
+	if len(forgeMock.CreatedPRs) != 1 {
+		t.Fatalf("CreatedPRs length = %d, want 1", len(forgeMock.CreatedPRs))
 	}
-	if !mock.CreatedPRs[0].Draft {
+	if !forgeMock.CreatedPRs[0].Draft {
 		t.Error("CreatedPRs[0].Draft = false, want true")
 	}
 }
@@ -426,6 +556,7 @@ func TestApplierDraftPR(t *testing.T) {
 func TestApplierBranchAlreadyExists(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	newContent := []byte("new content\n")
 
 	cfg := &config.Config{
@@ -440,7 +571,7 @@ func TestApplierBranchAlreadyExists(t *testing.T) {
 	// Mark the branch as existing
 	mock.BranchExistsMap["existing-branch"] = true
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	result, err := applier.Run()
 
 	if err != nil {
@@ -464,6 +595,7 @@ func TestApplierBranchAlreadyExists(t *testing.T) {
 func TestApplierCustomCommitMessage(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	newContent := []byte("new content\n")
 
 	cfg := &config.Config{
@@ -475,7 +607,7 @@ func TestApplierCustomCommitMessage(t *testing.T) {
 		CommitMessage: "fix: update workflow",
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	_, err := applier.Run()
 
 	if err != nil {
@@ -489,9 +621,196 @@ func TestApplierCustomCommitMessage(t *testing.T) {
 	}
 }
 
+func TestApplierCommitTrailers(t *testing.T) {
+	newContent := []byte("new content\n")
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want git.CommitOptions
+	}{
+		{
+			name: "default",
+			want: git.CommitOptions{Trailers: []git.Trailer{{Key: ContentTrailer, Value: hash.SHA256Bytes(newContent)}}},
+		},
+		{
+			name: "configured",
+			cfg: config.Config{
+				Signoff:   true,
+				Trailers:  []string{"Change-Type = automated", "Refs=ENG-42"},
+				CoAuthors: []string{"Jane Doe <jane@example.com>"},
+			},
+			want: git.CommitOptions{
+				Signoff: true,
+				Trailers: []git.Trailer{
+					{Key: ContentTrailer, Value: hash.SHA256Bytes(newContent)},
+					{Key: "Change-Type", Value: "automated"},
+					{Key: "Refs", Value: "ENG-42"},
+					{Key: "Co-authored-by", Value: "Jane Doe <jane@example.com>"},
+				},
+			},
+		},
+		{
+			name: "without content trailer",
+			cfg:  config.Config{NoContentTrailer: true},
+			want: git.CommitOptions{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := git.NewMockOperations()
+			cfg := tt.cfg
+			cfg.Mode = config.ModeUpsert
+			cfg.RepoPath = "test/file.txt"
+			cfg.Repo = t.TempDir()
+			cfg.Remote = "origin"
+
+			if _, err := NewApplier(&cfg, mock, forge.NewMockForge(), newContent).Run(); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if len(mock.CommitOpts) != 1 {
+				t.Fatalf("CommitOpts length = %d, want 1", len(mock.CommitOpts))
+			}
+			if !reflect.DeepEqual(mock.CommitOpts[0], tt.want) {
+				t.Errorf("CommitOpts[0] = %+v, want %+v", mock.CommitOpts[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestApplierCommitIdentity(t *testing.T) {
+	mock := git.NewMockOperations()
+	cfg := &config.Config{
+		Mode:          config.ModeUpsert,
+		RepoPath:      "test/file.txt",
+		Repo:          t.TempDir(),
+		Remote:        "origin",
+		AuthorName:    "Standards Bot",
+		AuthorEmail:   "bot@example.com",
+		SigningKey:    "~/.ssh/bot.pub",
+		SigningFormat: "ssh",
+	}
+	if _, err := NewApplier(cfg, mock, forge.NewMockForge(), []byte("new content\n")).Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	opts := mock.CommitOpts[0]
+	// The committer defaults to the author, so a bot identity covers both
+	if opts.AuthorName != "Standards Bot" || opts.AuthorEmail != "bot@example.com" ||
+		opts.CommitterName != "Standards Bot" || opts.CommitterEmail != "bot@example.com" {
+		t.Errorf("CommitOpts identity = %+v, want the bot as author and committer", opts)
+	}
+	if !opts.Sign || opts.SigningKey != "~/.ssh/bot.pub" || opts.SigningFormat != "ssh" {
+		t.Errorf("CommitOpts signing = %+v, want SSH signing with the key", opts)
+	}
+}
+
+func TestApplierCommitPreflight(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.Config
+		gitMock     func(*git.MockOperations)
+		forgeMock   func(*forge.MockForge)
+		wantErr     string
+		wantWarning string
+	}{
+		{
+			name:    "no identity",
+			gitMock: func(m *git.MockOperations) { m.IdentityErr = git.ErrNoIdentity },
+			wantErr: "--author-name and --author-email",
+		},
+		{
+			name:    "no identity when pushing directly",
+			cfg:     config.Config{Direct: true},
+			gitMock: func(m *git.MockOperations) { m.IdentityErr = git.ErrNoIdentity },
+			wantErr: "--author-name and --author-email",
+		},
+		{
+			name:    "no identity in dry run",
+			cfg:     config.Config{DryRun: true},
+			gitMock: func(m *git.MockOperations) { m.IdentityErr = git.ErrNoIdentity },
+		},
+		{
+			name:    "no identity and branch exists",
+			cfg:     config.Config{Branch: "existing"},
+			gitMock: func(m *git.MockOperations) { m.IdentityErr = git.ErrNoIdentity },
+		},
+		{
+			name:      "signatures required",
+			forgeMock: func(m *forge.MockForge) { m.SignedBranches = map[string]bool{"main": true} },
+			wantErr:   `branch "main" requires signed commits`,
+		},
+		{
+			name:      "signatures required and signing",
+			cfg:       config.Config{Sign: true},
+			forgeMock: func(m *forge.MockForge) { m.SignedBranches = map[string]bool{"main": true} },
+		},
+		{
+			name:      "signatures required and commit.gpgsign set",
+			gitMock:   func(m *git.MockOperations) { m.SigningEnabled = true },
+			forgeMock: func(m *forge.MockForge) { m.SignedBranches = map[string]bool{"main": true} },
+		},
+		{
+			name:      "signatures required and exporting",
+			cfg:       config.Config{ExportPatch: "export"},
+			forgeMock: func(m *forge.MockForge) { m.SignedBranches = map[string]bool{"main": true} },
+		},
+		{
+			name: "protection unknown",
+			forgeMock: func(m *forge.MockForge) {
+				m.ProtectionErr = errors.New(`exec: "gh": executable file not found in $PATH`)
+			},
+			wantWarning: "could not check whether main requires signed commits",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := git.NewMockOperations()
+			if tt.cfg.Branch != "" {
+				mock.BranchExistsMap[tt.cfg.Branch] = true
+			}
+			if tt.gitMock != nil {
+				tt.gitMock(mock)
+			}
+			forgeMock := forge.NewMockForge()
+			if tt.forgeMock != nil {
+				tt.forgeMock(forgeMock)
+			}
+			cfg := tt.cfg
+			cfg.Mode = config.ModeUpsert
+			cfg.RepoPath = "test/file.txt"
+			cfg.Repo = t.TempDir()
+			cfg.Remote = "origin"
+			if cfg.ExportPatch != "" {
+				cfg.ExportPatch = filepath.Join(cfg.Repo, cfg.ExportPatch)
+			}
+			if cfg.Direct {
+				mock.RemoteHeads["main"] = mock.HeadSHA
+			}
+
+			result, err := NewApplier(&cfg, mock, forgeMock, []byte("new content\n")).Run()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Run() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				if len(mock.CreatedBranches) != 0 || len(mock.Commits) != 0 {
+					t.Error("Run() changed the repository despite failing the preflight")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if tt.wantWarning != "" && (len(result.Warnings) == 0 || !strings.Contains(result.Warnings[0], tt.wantWarning)) {
+				t.Errorf("Warnings = %q, want one containing %q", result.Warnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
 func TestApplierMatchModeMultipleHashesFirstMatches(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	existingContent := []byte("existing content\n")
 	newContent := []byte("new content\n")
 
@@ -517,7 +836,7 @@ func TestApplierMatchModeMultipleHashesFirstMatches(t *testing.T) {
 		ExpectSHA256: expectedHash + "," + otherHash,
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	result, err := applier.Run()
 
 	if err != nil {
@@ -531,6 +850,7 @@ func TestApplierMatchModeMultipleHashesFirstMatches(t *testing.T) {
 func TestApplierMatchModeMultipleHashesSecondMatches(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	existingContent := []byte("existing content\n")
 	newContent := []byte("new content\n")
 
@@ -556,7 +876,7 @@ func TestApplierMatchModeMultipleHashesSecondMatches(t *testing.T) {
 		ExpectSHA256: otherHash + "," + expectedHash,
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	result, err := applier.Run()
 
 	if err != nil {
@@ -570,6 +890,7 @@ func TestApplierMatchModeMultipleHashesSecondMatches(t *testing.T) {
 func TestApplierMatchModeMultipleHashesNoneMatch(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	existingContent := []byte("existing content\n")
 	newContent := []byte("new content\n")
 
@@ -591,7 +912,7 @@ func TestApplierMatchModeMultipleHashesNoneMatch(t *testing.T) {
 		ExpectSHA256: "wronghash123456789,anotherwronghash987654321",
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	result, err := applier.Run()
 
 	if err != nil {
@@ -612,6 +933,7 @@ func TestApplierMatchModeMultipleHashesNoneMatch(t *testing.T) {
 func TestApplierMatchModeThreeHashesMiddleMatches(t *testing.T) {
 	tmpDir := t.TempDir()
 	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
 	existingContent := []byte("existing content\n")
 	newContent := []byte("new content\n")
 
@@ -636,7 +958,7 @@ func TestApplierMatchModeThreeHashesMiddleMatches(t *testing.T) {
 		ExpectSHA256: "hash1," + expectedHash + ",hash3",
 	}
 
-	applier := NewApplier(cfg, mock, newContent)
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
 	result, err := applier.Run()
 
 	if err != nil {
@@ -647,3 +969,891 @@ func TestApplierMatchModeThreeHashesMiddleMatches(t *testing.T) {
 	}
 }
 
+func TestApplierRunAllMultipleBases(t *testing.T) {
+	tmpDir := t.TempDir()
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	// Each base starts without the file, as a real checkout would
+	mock.OnSwitchBranch = func(string) { _ = os.RemoveAll(filepath.Join(tmpDir, "test")) }
+	newContent := []byte("new content\n")
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     tmpDir,
+		Remote:   "origin",
+		Bases:    []string{"main", "release/1.x"},
+	}
+
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
+	results, err := applier.RunAll()
+
+	if err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results length = %d, want 2", len(results))
+	}
+	if len(forgeMock.CreatedPRs) != 2 {
+		t.Fatalf("CreatedPRs length = %d, want 2", len(forgeMock.CreatedPRs))
+	}
+	if forgeMock.CreatedPRs[0].Base != "main" || forgeMock.CreatedPRs[1].Base != "release/1.x" {
+		t.Errorf("CreatedPRs bases = %q, %q, want main, release/1.x", forgeMock.CreatedPRs[0].Base, forgeMock.CreatedPRs[1].Base)
+	}
+	if results[1].BaseBranch != "release/1.x" {
+		t.Errorf("results[1].BaseBranch = %q, want %q", results[1].BaseBranch, "release/1.x")
+	}
+	if results[0].BranchName == results[1].BranchName {
+		t.Errorf("BranchName %q is shared between bases, want base-specific names", results[0].BranchName)
+	}
+	if !strings.HasSuffix(results[1].BranchName, "-release-1-x") {
+		t.Errorf("BranchName = %q, want suffix %q", results[1].BranchName, "-release-1-x")
+	}
+	if !contains(mock.SwitchedBranches, "release/1.x") {
+		t.Errorf("SwitchedBranches = %v, expected a switch to release/1.x", mock.SwitchedBranches)
+	}
+}
+
+func TestApplierRunAllBaseGlob(t *testing.T) {
+	tmpDir := t.TempDir()
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	mock.RemoteBranches = []string{"main", "release/2.x", "release/1.x", "feature/x"}
+	newContent := []byte("new content\n")
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     tmpDir,
+		Remote:   "origin",
+		DryRun:   true,
+		Bases:    []string{"release/*", "release/1.x"},
+	}
+
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
+	results, err := applier.RunAll()
+
+	if err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results length = %d, want 2", len(results))
+	}
+	if results[0].BaseBranch != "release/1.x" || results[1].BaseBranch != "release/2.x" {
+		t.Errorf("bases = %q, %q, want release/1.x, release/2.x", results[0].BaseBranch, results[1].BaseBranch)
+	}
+}
+
+func TestApplierRunAllBaseGlobNoMatch(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	mock.RemoteBranches = []string{"main"}
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     t.TempDir(),
+		Remote:   "origin",
+		Bases:    []string{"release/*"},
+	}
+
+	_, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).RunAll()
+	if err == nil {
+		t.Error("RunAll() expected error when no branches match the base pattern, got nil")
+	}
+}
+
+func TestApplierRunAllCustomBranchPerBase(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		DryRun:   true,
+		Repo:     t.TempDir(),
+		Remote:   "origin",
+		Branch:   "chore/update",
+		Bases:    []string{"main", "release/1.x"},
+	}
+
+	results, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).RunAll()
+	if err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	if results[0].BranchName != "chore/update" {
+		t.Errorf("results[0].BranchName = %q, want %q", results[0].BranchName, "chore/update")
+	}
+	if results[1].BranchName != "chore/update-release-1-x" {
+		t.Errorf("results[1].BranchName = %q, want %q", results[1].BranchName, "chore/update-release-1-x")
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestApplierDirectPush(t *testing.T) {
+	tmpDir := t.TempDir()
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	mock.RemoteHeads["main"] = mock.HeadSHA
+	baseSHA := mock.HeadSHA
+	newContent := []byte("new content\n")
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     tmpDir,
+		Remote:   "origin",
+		Direct:   true,
+	}
+
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
+	result, err := applier.Run()
+
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Action != "pushed" {
+		t.Errorf("Action = %q, want %q", result.Action, "pushed")
+	}
+	if result.CommitSHA == "" || result.CommitSHA == baseSHA {
+		t.Errorf("CommitSHA = %q, want the new commit", result.CommitSHA)
+	}
+	if result.PRURL != "" {
+		t.Errorf("PRURL = %q, want empty", result.PRURL)
+	}
+	if len(mock.CreatedBranches) != 0 {
+		t.Errorf("CreatedBranches length = %d, want 0", len(mock.CreatedBranches))
+	}
+	if len(forgeMock.CreatedPRs) != 0 {
+		t.Errorf("CreatedPRs length = %d, want 0", len(forgeMock.CreatedPRs))
+	}
+	if len(mock.LeasePushes) != 1 || mock.LeasePushes[0].Branch != "main" || mock.LeasePushes[0].ExpectedSHA != baseSHA {
+		t.Errorf("LeasePushes = %v, want one push of main leased on %s", mock.LeasePushes, baseSHA)
+	}
+}
+
+func TestApplierDirectPushGerritChangeID(t *testing.T) {
+	mock := git.NewMockOperations()
+	mock.RemoteHeads["main"] = mock.HeadSHA
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     t.TempDir(),
+		Remote:   "origin",
+		Direct:   true,
+	}
+
+	result, err := NewApplier(cfg, mock, forge.NewGerrit(), []byte("new content\n")).Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Action != "pushed" {
+		t.Errorf("Action = %q, want %q", result.Action, "pushed")
+	}
+	if len(mock.Commits) != 1 || !strings.Contains(mock.Commits[0], "\n\nChange-Id: I") {
+		t.Errorf("Commits = %q, want a Change-Id trailer", mock.Commits)
+	}
+}
+
+func TestApplierDirectPushRemoteMoved(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	mock.RemoteHeads["main"] = "ffffffffffffffffffffffffffffffffffffffff"
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     t.TempDir(),
+		Remote:   "origin",
+		Direct:   true,
+	}
+
+	_, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).Run()
+	if err == nil {
+		t.Fatal("Run() expected error when remote tip differs from local, got nil")
+	}
+	if len(mock.Commits) != 0 {
+		t.Errorf("Commits length = %d, want 0", len(mock.Commits))
+	}
+}
+
+func TestApplierDirectPushFailureResets(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	mock.RemoteHeads["main"] = mock.HeadSHA
+	mock.PushErr = errors.New("rejected")
+	baseSHA := mock.HeadSHA
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     t.TempDir(),
+		Remote:   "origin",
+		Direct:   true,
+	}
+
+	_, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).Run()
+	if err == nil {
+		t.Fatal("Run() expected error when push fails, got nil")
+	}
+	if len(mock.Resets) != 1 || mock.Resets[0] != baseSHA {
+		t.Errorf("Resets = %v, want [%s]", mock.Resets, baseSHA)
+	}
+}
+
+func TestApplierDirectPushDryRun(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	mock.RemoteHeads["main"] = mock.HeadSHA
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     t.TempDir(),
+		Remote:   "origin",
+		Direct:   true,
+		DryRun:   true,
+	}
+
+	result, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Action != "would push" {
+		t.Errorf("Action = %q, want %q", result.Action, "would push")
+	}
+	if len(mock.Commits) != 0 {
+		t.Errorf("Commits length = %d, want 0", len(mock.Commits))
+	}
+}
+
+func TestApplierExportPatch(t *testing.T) {
+	repoDir := filepath.Join(t.TempDir(), "my-repo")
+	exportDir := filepath.Join(t.TempDir(), "patches")
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	mock.PatchToReturn = "From 0000 Mon Sep 17 00:00:00 2001\n"
+	newContent := []byte("new content\n")
+
+	cfg := &config.Config{
+		Mode:        config.ModeUpsert,
+		RepoPath:    "test/file.txt",
+		NewFile:     "/path/to/new.txt",
+		Repo:        repoDir,
+		Remote:      "origin",
+		PRTitle:     "Standardize file",
+		PRBody:      "Details",
+		ExportPatch: exportDir,
+	}
+
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
+	result, err := applier.Run()
+
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Action != "exported" {
+		t.Errorf("Action = %q, want %q", result.Action, "exported")
+	}
+	wantFile := filepath.Join(exportDir, "my-repo.mbox")
+	if result.PatchFile != wantFile {
+		t.Errorf("PatchFile = %q, want %q", result.PatchFile, wantFile)
+	}
+	patch, err := os.ReadFile(wantFile)
+	if err != nil {
+		t.Fatalf("failed to read patch: %v", err)
+	}
+	if !strings.HasPrefix(string(patch), "Standardize file\n\nDetails\n") {
+		t.Errorf("patch = %q, want cover letter from PR title and body", patch)
+	}
+	if len(mock.Pushes) != 0 || len(forgeMock.CreatedPRs) != 0 {
+		t.Errorf("Pushes = %v, CreatedPRs = %v, want none", mock.Pushes, forgeMock.CreatedPRs)
+	}
+	if len(mock.DeletedBranches) != 1 || mock.DeletedBranches[0] != result.BranchName {
+		t.Errorf("DeletedBranches = %v, want [%s]", mock.DeletedBranches, result.BranchName)
+	}
+	if mock.CurrentBranch != "main" {
+		t.Errorf("CurrentBranch = %q, want %q", mock.CurrentBranch, "main")
+	}
+}
+
+func TestApplierAutoStash(t *testing.T) {
+	tmpDir := t.TempDir()
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	mock.CurrentBranch = "feature-branch"
+	mock.IsClean = false
+	newContent := []byte("new content\n")
+
+	cfg := &config.Config{
+		Mode:      config.ModeUpsert,
+		RepoPath:  "test/file.txt",
+		NewFile:   "/path/to/new.txt",
+		Repo:      tmpDir,
+		Remote:    "origin",
+		AutoStash: true,
+	}
+
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
+	result, err := applier.Run()
+
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Action != "updated" {
+		t.Errorf("Action = %q, want %q", result.Action, "updated")
+	}
+	if !result.Stashed {
+		t.Error("Stashed = false, want true")
+	}
+	if mock.StashPops != 1 || len(mock.Stashes) != 0 {
+		t.Errorf("StashPops = %d, Stashes = %v, want the stash popped", mock.StashPops, mock.Stashes)
+	}
+	if mock.CurrentBranch != "feature-branch" {
+		t.Errorf("CurrentBranch = %q, want %q", mock.CurrentBranch, "feature-branch")
+	}
+}
+
+func TestApplierAutoStashCleanTree(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+
+	cfg := &config.Config{
+		Mode:      config.ModeUpsert,
+		RepoPath:  "test/file.txt",
+		NewFile:   "/path/to/new.txt",
+		Repo:      t.TempDir(),
+		Remote:    "origin",
+		AutoStash: true,
+	}
+
+	result, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Stashed {
+		t.Error("Stashed = true, want false for a clean working tree")
+	}
+	if len(mock.Stashes) != 0 || mock.StashPops != 0 {
+		t.Errorf("Stashes = %v, StashPops = %d, want no stash", mock.Stashes, mock.StashPops)
+	}
+}
+
+func TestApplierAutoStashPopConflict(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	mock.IsClean = false
+	mock.StashPopErr = errors.New("conflict")
+	mock.StashedUntracked = []string{"notes.txt", "scratch/todo.md"}
+
+	cfg := &config.Config{
+		Mode:      config.ModeUpsert,
+		RepoPath:  "test/file.txt",
+		NewFile:   "/path/to/new.txt",
+		Repo:      t.TempDir(),
+		Remote:    "origin",
+		AutoStash: true,
+	}
+
+	result, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).Run()
+	if err == nil {
+		t.Fatal("Run() expected error when the stash pop conflicts, got nil")
+	}
+	if !strings.Contains(err.Error(), "remain in the stash") {
+		t.Errorf("error = %v, want it to explain the changes remain in the stash", err)
+	}
+	if result == nil || result.PRURL == "" {
+		t.Errorf("result = %+v, want the created PR to still be reported", result)
+	}
+	if len(mock.Resets) != 1 || mock.Resets[0] != "HEAD" {
+		t.Errorf("Resets = %v, want [HEAD]", mock.Resets)
+	}
+	if len(mock.Stashes) != 1 {
+		t.Errorf("Stashes = %v, want the stash kept", mock.Stashes)
+	}
+	if !reflect.DeepEqual(mock.CleanedFiles, mock.StashedUntracked) {
+		t.Errorf("CleanedFiles = %v, want the stashed untracked files removed", mock.CleanedFiles)
+	}
+}
+
+func TestApplierDefaultBranchOverride(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	mock.DefaultBranchErr = errors.New("no remote HEAD")
+	forgeMock.DefaultBranchErr = errors.New("gh not available")
+	mock.CurrentBranch = "trunk"
+
+	cfg := &config.Config{
+		Mode:          config.ModeUpsert,
+		RepoPath:      "test/file.txt",
+		NewFile:       "/path/to/new.txt",
+		Repo:          t.TempDir(),
+		Remote:        "origin",
+		DryRun:        true,
+		DefaultBranch: "trunk",
+	}
+
+	result, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.DefaultBranch != "trunk" {
+		t.Errorf("DefaultBranch = %q, want %q", result.DefaultBranch, "trunk")
+	}
+	if result.DefaultBranchSource != string(git.SourceOverride) {
+		t.Errorf("DefaultBranchSource = %q, want %q", result.DefaultBranchSource, git.SourceOverride)
+	}
+}
+
+func TestApplierDefaultBranchFromForge(t *testing.T) {
+	mock := git.NewMockOperations()
+	mock.DefaultBranchErr = errors.New("no remote HEAD")
+	forgeMock := forge.NewMockForge()
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     t.TempDir(),
+		Remote:   "origin",
+		DryRun:   true,
+	}
+
+	result, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.DefaultBranchSource != forgeMock.Name() {
+		t.Errorf("DefaultBranchSource = %q, want %q", result.DefaultBranchSource, forgeMock.Name())
+	}
+}
+
+func TestApplierDefaultBranchFallsBackToGit(t *testing.T) {
+	mock := git.NewMockOperations()
+	mock.DefaultBranch = "trunk"
+	mock.CurrentBranch = "trunk"
+	forgeMock := forge.NewMockForge()
+	forgeMock.DefaultBranchErr = errors.New("gh not available")
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     t.TempDir(),
+		Remote:   "origin",
+		DryRun:   true,
+	}
+
+	result, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.DefaultBranch != "trunk" {
+		t.Errorf("DefaultBranch = %q, want %q", result.DefaultBranch, "trunk")
+	}
+	if result.DefaultBranchSource != string(git.SourceSymbolicRef) {
+		t.Errorf("DefaultBranchSource = %q, want %q", result.DefaultBranchSource, git.SourceSymbolicRef)
+	}
+
+	// When neither the forge nor git can tell, both failures are reported
+	mock.DefaultBranchErr = errors.New("no remote HEAD")
+	_, err = NewApplier(cfg, mock, forgeMock, []byte("new content\n")).Run()
+	if err == nil {
+		t.Fatal("Run() expected error when default branch cannot be detected, got nil")
+	}
+	if !strings.Contains(err.Error(), "gh not available") || !strings.Contains(err.Error(), "no remote HEAD") {
+		t.Errorf("error = %v, want both detection failures", err)
+	}
+}
+
+func TestApplierGerritUpload(t *testing.T) {
+	mock := git.NewMockOperations()
+	mock.PushOutput = "remote:   https://review.example.com/c/repo/+/77 chore: update test/file.txt [NEW]"
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     t.TempDir(),
+		Remote:   "origin",
+		Topic:    "file-rollout",
+	}
+
+	result, err := NewApplier(cfg, mock, forge.NewGerrit(), []byte("new content\n")).Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.PRURL != "https://review.example.com/c/repo/+/77" {
+		t.Errorf("PRURL = %q, want the change URL", result.PRURL)
+	}
+	if len(mock.Pushes) != 0 {
+		t.Errorf("Pushes = %v, want none", mock.Pushes)
+	}
+	want := "refs/heads/" + result.BranchName + ":refs/for/main%topic=file-rollout"
+	if len(mock.RefspecPushes) != 1 || mock.RefspecPushes[0].Refspec != want {
+		t.Errorf("RefspecPushes = %v, want [{origin %s}]", mock.RefspecPushes, want)
+	}
+	if len(mock.Commits) != 1 || !strings.Contains(mock.Commits[0], "\n\nChange-Id: I") {
+		t.Errorf("Commits = %q, want a Change-Id trailer", mock.Commits)
+	}
+}
+
+func TestApplierFork(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     t.TempDir(),
+		Remote:   "origin",
+		Fork:     true,
+	}
+
+	result, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(mock.AddedRemotes) != 1 || mock.AddedRemotes[0].Name != "fork" || mock.AddedRemotes[0].URL != "git@github.com:me/repo.git" {
+		t.Errorf("AddedRemotes = %v, want the fork remote", mock.AddedRemotes)
+	}
+	if len(mock.Pushes) != 1 || mock.Pushes[0].Remote != "fork" || mock.Pushes[0].Branch != result.BranchName {
+		t.Errorf("Pushes = %v, want [{fork %s}]", mock.Pushes, result.BranchName)
+	}
+	if len(forgeMock.CreatedPRs) != 1 || forgeMock.CreatedPRs[0].Head != "me:"+result.BranchName {
+		t.Errorf("CreatedPRs = %+v, want head me:%s", forgeMock.CreatedPRs, result.BranchName)
+	}
+}
+
+func TestApplierForkNotReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int
+		wantErr    bool
+		wantSleeps []time.Duration
+	}{
+		{"ready after retries", 2, false, []time.Duration{2 * time.Second, 4 * time.Second}},
+		{"never ready", forkReadyAttempts, true, []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 32 * time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := git.NewMockOperations()
+			mock.RemoteHeadErr = errors.New("repository not found")
+			mock.RemoteHeadFailures = tt.failures
+			forgeMock := forge.NewMockForge()
+
+			cfg := &config.Config{
+				Mode:     config.ModeUpsert,
+				RepoPath: "test/file.txt",
+				NewFile:  "/path/to/new.txt",
+				Repo:     t.TempDir(),
+				Remote:   "origin",
+				Fork:     true,
+			}
+
+			applier := NewApplier(cfg, mock, forgeMock, []byte("new content\n"))
+			var sleeps []time.Duration
+			applier.forks.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+			_, err := applier.Run()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(sleeps, tt.wantSleeps) {
+				t.Errorf("sleeps = %v, want %v", sleeps, tt.wantSleeps)
+			}
+			if wantPushes := map[bool]int{false: 1, true: 0}[tt.wantErr]; len(mock.Pushes) != wantPushes {
+				t.Errorf("Pushes = %v, want %d", mock.Pushes, wantPushes)
+			}
+		})
+	}
+}
+
+func TestApplierForkBranchExists(t *testing.T) {
+	tests := []struct {
+		name       string
+		existing   string
+		wantAction string
+	}{
+		{"on the fork", "fork/bulkfilepr/license", "branch already exists"},
+		{"only upstream", "origin/bulkfilepr/license", "updated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := git.NewMockOperations()
+			mock.BranchExistsMap[tt.existing] = true
+
+			cfg := &config.Config{
+				Mode:     config.ModeUpsert,
+				RepoPath: "test/file.txt",
+				NewFile:  "/path/to/new.txt",
+				Repo:     t.TempDir(),
+				Remote:   "origin",
+				Branch:   "bulkfilepr/license",
+				Fork:     true,
+			}
+
+			result, err := NewApplier(cfg, mock, forge.NewMockForge(), []byte("new content\n")).Run()
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if result.Action != tt.wantAction {
+				t.Errorf("Action = %q, want %q", result.Action, tt.wantAction)
+			}
+		})
+	}
+}
+
+func TestApplierForkExistingRemote(t *testing.T) {
+	mock := git.NewMockOperations()
+	mock.RemoteURLs = map[string]string{"mine": "https://github.com/me/repo.git"}
+	forgeMock := forge.NewMockForge()
+	tmpDir := t.TempDir()
+	mock.OnSwitchBranch = func(string) { _ = os.RemoveAll(filepath.Join(tmpDir, "test")) }
+
+	cfg := &config.Config{
+		Mode:       config.ModeUpsert,
+		RepoPath:   "test/file.txt",
+		NewFile:    "/path/to/new.txt",
+		Repo:       tmpDir,
+		Remote:     "origin",
+		Bases:      []string{"main", "release"},
+		Fork:       true,
+		ForkRemote: "mine",
+	}
+
+	results, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).RunAll()
+	if err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results length = %d, want 2", len(results))
+	}
+	if forgeMock.ForkCalls != 1 {
+		t.Errorf("ForkCalls = %d, want 1", forgeMock.ForkCalls)
+	}
+	if len(mock.AddedRemotes) != 0 {
+		t.Errorf("AddedRemotes = %v, want none", mock.AddedRemotes)
+	}
+	if len(mock.Pushes) != 2 {
+		t.Errorf("Pushes = %v, want one per base", mock.Pushes)
+	}
+	for _, push := range mock.Pushes {
+		if push.Remote != "mine" {
+			t.Errorf("push remote = %q, want %q", push.Remote, "mine")
+		}
+	}
+}
+
+func TestApplierForkUnsupported(t *testing.T) {
+	mock := git.NewMockOperations()
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     t.TempDir(),
+		Remote:   "origin",
+		Fork:     true,
+	}
+
+	_, err := NewApplier(cfg, mock, forge.NewGerrit(), []byte("new content\n")).Run()
+	if !errors.Is(err, forge.ErrNotSupported) {
+		t.Errorf("Run() error = %v, want ErrNotSupported", err)
+	}
+	if len(mock.Pushes)+len(mock.RefspecPushes) != 0 {
+		t.Error("expected nothing to be pushed")
+	}
+}
+
+func TestApplierMetadataWarnings(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	forgeMock.MetadataErrs = []error{errors.New("failed to add labels to PR #1: label not found")}
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     t.TempDir(),
+		Remote:   "origin",
+		Labels:   []string{"standards"},
+	}
+
+	result, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Action != "updated" || result.PRURL != forgeMock.PRURLToReturn {
+		t.Errorf("result = %+v, want updated with the PR URL", result)
+	}
+	want := []string{"failed to add labels to PR #1: label not found"}
+	if !reflect.DeepEqual(result.Warnings, want) {
+		t.Errorf("Warnings = %v, want %v", result.Warnings, want)
+	}
+}
+
+func TestApplierMetadataNotSupported(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	forgeMock.Unsupported = []forge.Metadata{forge.MetadataProjects}
+
+	cfg := &config.Config{
+		Mode:     config.ModeUpsert,
+		RepoPath: "test/file.txt",
+		NewFile:  "/path/to/new.txt",
+		Repo:     t.TempDir(),
+		Remote:   "origin",
+		Projects: []string{"7"},
+	}
+
+	_, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).Run()
+	if !errors.Is(err, forge.ErrNotSupported) {
+		t.Errorf("Run() error = %v, want ErrNotSupported", err)
+	}
+	if len(mock.CreatedBranches) != 0 || len(mock.Pushes) != 0 || len(forgeMock.CreatedPRs) != 0 {
+		t.Error("expected no branch, push or PR")
+	}
+}
+
+func TestApplierCodeOwnersReviewers(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".github"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	codeOwners := "* @default-owner\n/test/ @acme/platform ops@example.com @alice\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, ".github", "CODEOWNERS"), []byte(codeOwners), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		noCodeOwners bool
+		noTeams      bool
+		want         []string
+		wantWarnings []string
+	}{
+		{"owners added to reviewers", false, false, []string{"alice", "bob", "acme/platform"}, nil},
+		{"owners skipped", true, false, []string{"alice", "bob"}, nil},
+		{
+			"teams dropped without team support", false, true, []string{"alice", "bob"},
+			[]string{"CODEOWNERS team @acme/platform is not requested as a reviewer: forge mock does not support team reviewers"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := git.NewMockOperations()
+			forgeMock := forge.NewMockForge()
+			if tt.noTeams {
+				forgeMock.Unsupported = []forge.Metadata{forge.MetadataTeamReviewers}
+			}
+			cfg := &config.Config{
+				Mode:         config.ModeUpsert,
+				RepoPath:     "test/file.txt",
+				NewFile:      "/path/to/new.txt",
+				Repo:         tmpDir,
+				Remote:       "origin",
+				Reviewers:    []string{"alice", "bob"},
+				NoCodeOwners: tt.noCodeOwners,
+			}
+			t.Cleanup(func() { _ = os.RemoveAll(filepath.Join(tmpDir, "test")) })
+
+			result, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).Run()
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if len(forgeMock.CreatedPRs) != 1 {
+				t.Fatalf("CreatedPRs length = %d, want 1", len(forgeMock.CreatedPRs))
+			}
+			if got := forgeMock.CreatedPRs[0].Reviewers; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reviewers = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(result.Warnings, tt.wantWarnings) {
+				t.Errorf("Warnings = %v, want %v", result.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestApplierAutoMerge(t *testing.T) {
+	tests := []struct {
+		name       string
+		forgeErr   error
+		wantMerge  bool
+		wantReason string
+	}{
+		{"enabled", nil, true, ""},
+		{"not allowed", errors.New("auto-merge is not allowed for this repository"), false, "auto-merge is not allowed for this repository"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := git.NewMockOperations()
+			forgeMock := forge.NewMockForge()
+			forgeMock.EnableAutoMergeErr = tt.forgeErr
+
+			cfg := &config.Config{
+				Mode:        config.ModeUpsert,
+				RepoPath:    "test/file.txt",
+				NewFile:     "/path/to/new.txt",
+				Repo:        t.TempDir(),
+				Remote:      "origin",
+				AutoMerge:   true,
+				MergeMethod: "squash",
+			}
+
+			result, err := NewApplier(cfg, mock, forgeMock, []byte("new content\n")).Run()
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if result.Action != "updated" {
+				t.Errorf("Action = %q, want %q", result.Action, "updated")
+			}
+			if result.AutoMerge != tt.wantMerge || result.AutoMergeReason != tt.wantReason {
+				t.Errorf("AutoMerge = %v (%q), want %v (%q)", result.AutoMerge, result.AutoMergeReason, tt.wantMerge, tt.wantReason)
+			}
+			if tt.wantMerge && forgeMock.AutoMerged[1] != forge.MergeMethodSquash {
+				t.Errorf("AutoMerged = %v, want squash for PR 1", forgeMock.AutoMerged)
+			}
+		})
+	}
+}
+
+func TestApplierAutoMergeUnsupported(t *testing.T) {
+	mock := git.NewMockOperations()
+	mock.PushOutput = "remote:   https://review.example.com/c/repo/+/77 chore: update [NEW]"
+
+	cfg := &config.Config{
+		Mode:      config.ModeUpsert,
+		RepoPath:  "test/file.txt",
+		NewFile:   "/path/to/new.txt",
+		Repo:      t.TempDir(),
+		Remote:    "origin",
+		AutoMerge: true,
+	}
+
+	result, err := NewApplier(cfg, mock, forge.NewGerrit(), []byte("new content\n")).Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.AutoMerge || !strings.Contains(result.AutoMergeReason, "does not support auto-merge") {
+		t.Errorf("AutoMerge = %v (%q), want unsupported", result.AutoMerge, result.AutoMergeReason)
+	}
+}
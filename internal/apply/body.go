@@ -0,0 +1,230 @@
+package apply
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/config"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/diff"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/git"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/hash"
+)
+
+// changeFacts are the facts about a change that PR bodies report. They are
+// also the data the commit message, PR title and PR body templates are
+// rendered with, so the field names are part of the CLI.
+type changeFacts struct {
+	// Repo is the repository's path on the forge, or its directory name.
+	Repo string
+	// Mode is the update mode.
+	Mode string
+	// Path is the destination file path inside the repo.
+	Path string
+	// Base is the branch the change is based on.
+	Base string
+	// DefaultBranch is the repository's default branch.
+	DefaultBranch string
+	// Branch is the branch the change is committed to.
+	Branch string
+	// OldSHA256 is the hash of the file on the base (empty if it did not exist).
+	OldSHA256 string
+	// NewSHA256 is the hash of the new content.
+	NewSHA256 string
+	// MatchedSHA256 is the expected hash the file matched in match mode.
+	MatchedSHA256 string
+	// Version is the bulkfilepr version that made the change.
+	Version string
+	// Diff counts the lines the change adds and removes.
+	Diff diff.Stats
+	// PRTemplate is the repository's own PR template on the base (empty if
+	// it has none).
+	PRTemplate string
+}
+
+// prTemplatePaths are the places a repository's PR template is looked up, in
+// order.
+var prTemplatePaths = []string{
+	".github/pull_request_template.md",
+	".github/PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	".gitea/pull_request_template.md",
+	".gitlab/merge_request_templates/Default.md",
+}
+
+// prMarkerRe matches the hidden marker bulkfilepr adds to the PRs it opens.
+var prMarkerRe = regexp.MustCompile(`<!-- bulkfilepr path="[^"]*" sha256="[0-9a-f]*" -->`)
+
+// IsBulkfileprPR reports whether a PR body carries the marker bulkfilepr adds
+// to the PRs it opens.
+func IsBulkfileprPR(body string) bool {
+	return prMarkerRe.MatchString(body)
+}
+
+// loadChangeFacts reads the file and the PR template on the current base to
+// describe the change. It must run before the new content is written.
+func (a *Applier) loadChangeFacts(defaultBranch, base string) error {
+	facts := changeFacts{
+		Repo:          RepoName(a.gitOps, a.cfg.Remote, a.repoDir),
+		Mode:          string(a.cfg.Mode),
+		Path:          a.cfg.RepoPath,
+		Base:          base,
+		DefaultBranch: defaultBranch,
+		NewSHA256:     hash.SHA256Bytes(a.newContent),
+		Version:       a.cfg.Version,
+	}
+	var oldContent []byte
+	if git.FileExists(a.repoDir, a.cfg.RepoPath) {
+		content, err := git.ReadFile(a.repoDir, a.cfg.RepoPath)
+		if err != nil {
+			return fmt.Errorf("failed to read existing file: %w", err)
+		}
+		oldContent = content
+		facts.OldSHA256 = hash.SHA256Bytes(content)
+	}
+	// The mode check only lets match mode through when a hash matched
+	if a.cfg.Mode == config.ModeMatch {
+		facts.MatchedSHA256 = facts.OldSHA256
+	}
+	facts.Diff = diff.Lines(oldContent, a.newContent)
+	for _, path := range prTemplatePaths {
+		if !git.FileExists(a.repoDir, path) {
+			continue
+		}
+		content, err := git.ReadFile(a.repoDir, path)
+		if err != nil {
+			return fmt.Errorf("failed to read PR template: %w", err)
+		}
+		facts.PRTemplate = string(content)
+		break
+	}
+	a.facts = facts
+	return nil
+}
+
+// renderMessages renders the commit message, PR title and PR body for a
+// change committed to head, so template errors surface before anything is
+// changed.
+func (a *Applier) renderMessages(head string) error {
+	a.facts.Branch = head
+
+	message := a.cfg.GetCommitMessage()
+	if text := firstSet(a.cfg.CommitMessageTemplate, a.cfg.CommitMessage); text != "" {
+		rendered, err := a.facts.render("commit-message", text)
+		if err != nil {
+			return fmt.Errorf("failed to render commit message: %w", err)
+		}
+		message = strings.TrimSpace(rendered)
+	}
+
+	title := a.cfg.GetPRTitle()
+	if a.cfg.PRTitle != "" {
+		rendered, err := a.facts.render("pr-title", a.cfg.PRTitle)
+		if err != nil {
+			return fmt.Errorf("failed to render PR title: %w", err)
+		}
+		title = strings.TrimSpace(rendered)
+	}
+
+	body, err := a.prBody()
+	if err != nil {
+		return fmt.Errorf("failed to render PR body: %w", err)
+	}
+
+	a.message, a.title, a.body = message, title, body
+	return nil
+}
+
+// prBody returns the PR body: the rendered --pr-body or --pr-body-file
+// template, or a summary of the change. The repository's PR template is
+// appended if requested, then the hidden marker.
+func (a *Applier) prBody() (string, error) {
+	facts := a.facts
+	body := defaultPRBody(a.cfg.GetPRBody(), facts)
+	if text := firstSet(a.cfg.PRBodyTemplate, a.cfg.PRBody); text != "" {
+		rendered, err := facts.render("pr-body", text)
+		if err != nil {
+			return "", err
+		}
+		body = rendered
+	}
+	body = strings.TrimRight(body, "\n")
+	if a.cfg.AppendPRTemplate && strings.TrimSpace(facts.PRTemplate) != "" {
+		body += "\n\n" + strings.TrimRight(facts.PRTemplate, "\n")
+	}
+	return body + "\n\n" + facts.marker(), nil
+}
+
+// defaultPRBody follows the intro with a summary of the change and where it
+// came from.
+func defaultPRBody(intro string, facts changeFacts) string {
+	var b strings.Builder
+	b.WriteString(intro + "\n\n")
+	fmt.Fprintf(&b, "- **Mode:** `%s`\n", facts.Mode)
+	fmt.Fprintf(&b, "- **Path:** `%s`\n", facts.Path)
+	if facts.OldSHA256 != "" {
+		fmt.Fprintf(&b, "- **Previous SHA-256:** `%s`\n", facts.OldSHA256)
+	} else {
+		b.WriteString("- **Previous SHA-256:** none (new file)\n")
+	}
+	fmt.Fprintf(&b, "- **New SHA-256:** `%s`\n", facts.NewSHA256)
+	if facts.MatchedSHA256 != "" {
+		fmt.Fprintf(&b, "- **Matched expected SHA-256:** `%s`\n", facts.MatchedSHA256)
+	}
+	fmt.Fprintf(&b, "- **Diff:** %s\n", facts.Diff)
+	if facts.Version != "" {
+		fmt.Fprintf(&b, "\n_Generated by %s._\n", facts.Version)
+	}
+	return b.String()
+}
+
+// render executes a Go template with the facts as its data.
+func (f changeFacts) render(name, text string) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, f); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// firstSet returns the first non-empty text. The file and inline forms of a
+// message cannot both be set, so this picks whichever was given.
+func firstSet(texts ...string) string {
+	for _, text := range texts {
+		if text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// marker returns the hidden marker recognized by IsBulkfileprPR.
+func (f changeFacts) marker() string {
+	// Escape what would end the attribute or the comment early
+	path := strings.NewReplacer(`"`, "%22", ">", "%3E").Replace(f.Path)
+	return fmt.Sprintf(`<!-- bulkfilepr path="%s" sha256="%s" -->`, path, f.NewSHA256)
+}
+
+// RepoName returns the name a repository is known by: its path on the forge,
+// or the directory name if the remote URL cannot be parsed.
+func RepoName(gitOps git.Operations, remote, dir string) string {
+	if remoteURL, err := gitOps.RemoteURL(remote); err == nil {
+		if info, err := forge.ParseRemoteURL(remoteURL); err == nil {
+			return info.Path
+		}
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return filepath.Base(dir)
+}
@@ -0,0 +1,227 @@
+package apply
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/config"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/git"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/hash"
+)
+
+// runWithExistingFile runs the applier against a repo containing test/file.txt
+// and returns the body of the created PR.
+func runWithExistingFile(t *testing.T, cfg *config.Config, existingContent, newContent []byte) string {
+	t.Helper()
+	cfg.Repo = t.TempDir()
+	cfg.RepoPath = "test/file.txt"
+	cfg.Remote = "origin"
+	if err := os.MkdirAll(filepath.Join(cfg.Repo, "test"), 0755); err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.Repo, cfg.RepoPath), existingContent, 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	forgeMock := forge.NewMockForge()
+	if _, err := NewApplier(cfg, git.NewMockOperations(), forgeMock, newContent).Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(forgeMock.CreatedPRs) != 1 {
+		t.Fatalf("CreatedPRs length = %d, want 1", len(forgeMock.CreatedPRs))
+	}
+	return forgeMock.CreatedPRs[0].Body
+}
+
+func TestApplierDefaultPRBody(t *testing.T) {
+	existingContent := []byte("a\nb\nc\n")
+	newContent := []byte("a\nB\nc\nd\n")
+	oldHash, newHash := hash.SHA256Bytes(existingContent), hash.SHA256Bytes(newContent)
+
+	cfg := &config.Config{Mode: config.ModeMatch, ExpectSHA256: oldHash, Version: "bulkfilepr version v1.2.3 (go1.22.0, linux/amd64)"}
+	body := runWithExistingFile(t, cfg, existingContent, newContent)
+
+	want := "This PR updates the standardized file at `test/file.txt`.\n\n" +
+		"- **Mode:** `match`\n" +
+		"- **Path:** `test/file.txt`\n" +
+		"- **Previous SHA-256:** `" + oldHash + "`\n" +
+		"- **New SHA-256:** `" + newHash + "`\n" +
+		"- **Matched expected SHA-256:** `" + oldHash + "`\n" +
+		"- **Diff:** 2 lines added, 1 line removed\n\n" +
+		"_Generated by bulkfilepr version v1.2.3 (go1.22.0, linux/amd64)._\n\n" +
+		`<!-- bulkfilepr path="test/file.txt" sha256="` + newHash + `" -->`
+	if body != want {
+		t.Errorf("body =\n%s\nwant\n%s", body, want)
+	}
+	if !IsBulkfileprPR(body) {
+		t.Error("IsBulkfileprPR() = false, want true")
+	}
+}
+
+func TestApplierInlineTemplates(t *testing.T) {
+	existingContent := []byte("old\n")
+	newContent := []byte("new\n")
+
+	cfg := &config.Config{
+		Mode:          config.ModeUpsert,
+		CommitMessage: "chore: update {{.Path}} ({{.Mode}})",
+		PRTitle:       "Update {{.Path}} on {{.Base}}",
+		PRBody:        "Updating {{.Path}} ({{.Mode}}) on {{.Base}} from {{.OldSHA256}} to {{.NewSHA256}}: {{.Diff}}. Keep {braces}.",
+		Version:       "bulkfilepr version dev",
+	}
+	cfg.Repo = t.TempDir()
+	cfg.RepoPath = "test/file.txt"
+	cfg.Remote = "origin"
+	if err := os.MkdirAll(filepath.Join(cfg.Repo, "test"), 0755); err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.Repo, cfg.RepoPath), existingContent, 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	if _, err := NewApplier(cfg, mock, forgeMock, newContent).Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(mock.Commits) != 1 || mock.Commits[0] != "chore: update test/file.txt (upsert)" {
+		t.Errorf("Commits = %q, want the rendered message", mock.Commits)
+	}
+	if title := forgeMock.CreatedPRs[0].Title; title != "Update test/file.txt on main" {
+		t.Errorf("title = %q, want the rendered title", title)
+	}
+	body := forgeMock.CreatedPRs[0].Body
+	want := "Updating test/file.txt (upsert) on main from " + hash.SHA256Bytes(existingContent) + " to " + hash.SHA256Bytes(newContent) +
+		": 1 line added, 1 line removed. Keep {braces}.\n\n"
+	if !strings.HasPrefix(body, want) {
+		t.Errorf("body = %q, want prefix %q", body, want)
+	}
+	if !IsBulkfileprPR(body) {
+		t.Error("IsBulkfileprPR() = false, want the marker on custom bodies")
+	}
+}
+
+func TestApplierTemplates(t *testing.T) {
+	existingContent := []byte("old\n")
+	newContent := []byte("new\n")
+	newHash := hash.SHA256Bytes(newContent)
+
+	cfg := &config.Config{
+		Mode:                  config.ModeUpsert,
+		CommitMessageTemplate: "chore: update {{.Path}}\n\nSHA-256: {{.NewSHA256}}\n",
+		PRBodyTemplate:        "Updates `{{.Path}}` in {{.Repo}} ({{.Base}} of {{.DefaultBranch}}, branch {{.Branch}}).\n{{.Diff.Added}}+ {{.Diff.Removed}}-: {{.Diff}}\n",
+	}
+	cfg.Repo = t.TempDir()
+	cfg.RepoPath = "test/file.txt"
+	cfg.Remote = "origin"
+	if err := os.MkdirAll(filepath.Join(cfg.Repo, "test"), 0755); err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.Repo, cfg.RepoPath), existingContent, 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	gitMock := git.NewMockOperations()
+	gitMock.RemoteURLs = map[string]string{"origin": "git@github.com:acme/api.git"}
+	forgeMock := forge.NewMockForge()
+	result, err := NewApplier(cfg, gitMock, forgeMock, newContent).Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	wantMessage := "chore: update test/file.txt\n\nSHA-256: " + newHash
+	if len(gitMock.Commits) != 1 || gitMock.Commits[0] != wantMessage {
+		t.Errorf("Commits = %q, want [%q]", gitMock.Commits, wantMessage)
+	}
+	wantBody := "Updates `test/file.txt` in acme/api (main of main, branch " + result.BranchName + ").\n" +
+		"1+ 1-: 1 line added, 1 line removed\n\n" +
+		`<!-- bulkfilepr path="test/file.txt" sha256="` + newHash + `" -->`
+	if body := forgeMock.CreatedPRs[0].Body; body != wantBody {
+		t.Errorf("body =\n%s\nwant\n%s", body, wantBody)
+	}
+}
+
+func TestApplierPRTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want string
+	}{
+		{
+			name: "appended",
+			cfg:  config.Config{PRBody: "Update {{.Path}}.", AppendPRTemplate: true},
+			want: "Update test/file.txt.\n\n## Checklist\n- [ ] Tested\n\n",
+		},
+		{
+			name: "embedded",
+			cfg:  config.Config{PRBodyTemplate: "{{.PRTemplate}}\nUpdate {{.Path}}."},
+			want: "## Checklist\n- [ ] Tested\n\nUpdate test/file.txt.\n\n",
+		},
+		{
+			name: "ignored by default",
+			cfg:  config.Config{PRBody: "Update {{.Path}}."},
+			want: "Update test/file.txt.\n\n<!--",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Mode = config.ModeUpsert
+			cfg.Repo = t.TempDir()
+			cfg.RepoPath = "test/file.txt"
+			cfg.Remote = "origin"
+			if err := os.MkdirAll(filepath.Join(cfg.Repo, ".github"), 0755); err != nil {
+				t.Fatalf("failed to create .github directory: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(cfg.Repo, ".github", "pull_request_template.md"), []byte("## Checklist\n- [ ] Tested\n"), 0644); err != nil {
+				t.Fatalf("failed to create PR template: %v", err)
+			}
+
+			forgeMock := forge.NewMockForge()
+			if _, err := NewApplier(&cfg, git.NewMockOperations(), forgeMock, []byte("new\n")).Run(); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if body := forgeMock.CreatedPRs[0].Body; !strings.HasPrefix(body, tt.want) {
+				t.Errorf("body = %q, want prefix %q", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplierTemplateError(t *testing.T) {
+	cfg := &config.Config{Mode: config.ModeUpsert, RepoPath: "file.txt", Repo: t.TempDir(), Remote: "origin", PRBodyTemplate: "{{.Unknown}}"}
+	gitMock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	_, err := NewApplier(cfg, gitMock, forgeMock, []byte("new\n")).Run()
+	if err == nil || !strings.Contains(err.Error(), "failed to render PR body") {
+		t.Fatalf("Run() error = %v, want a render error", err)
+	}
+	if len(gitMock.CreatedBranches) != 0 || len(forgeMock.CreatedPRs) != 0 {
+		t.Error("Run() changed the repository despite the template error")
+	}
+}
+
+func TestRepoName(t *testing.T) {
+	mock := git.NewMockOperations()
+	mock.RemoteURLs = map[string]string{"origin": "git@github.com:acme/api.git"}
+	if got := RepoName(mock, "origin", "/src/api"); got != "acme/api" {
+		t.Errorf("RepoName() = %q, want %q", got, "acme/api")
+	}
+	if got := RepoName(mock, "upstream", "/src/api"); got != "api" {
+		t.Errorf("RepoName() without remote = %q, want %q", got, "api")
+	}
+}
+
+func TestIsBulkfileprPR(t *testing.T) {
+	if IsBulkfileprPR("Update the CI workflow") {
+		t.Error("IsBulkfileprPR() = true for a body without the marker")
+	}
+	facts := changeFacts{Path: `odd"path-->`, NewSHA256: "abc123"}
+	if !IsBulkfileprPR("text\n\n" + facts.marker()) {
+		t.Errorf("IsBulkfileprPR(%q) = false, want true", facts.marker())
+	}
+}
@@ -0,0 +1,86 @@
+package apply
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/git"
+)
+
+// forkReadyAttempts is how many times a fork is checked before giving up, and
+// forkReadyDelay the wait after the first failed check, doubling after each
+// further one.
+const (
+	forkReadyAttempts = 6
+	forkReadyDelay    = 2 * time.Second
+)
+
+// forkTarget resolves the fork branches are pushed to, creating the fork and
+// its git remote on first use and reusing them afterwards.
+type forkTarget struct {
+	// remote is the name of the git remote for the fork.
+	remote string
+	fork   *forge.Fork
+	// sleep waits between checks of a fork that is not ready yet.
+	sleep func(time.Duration)
+}
+
+// newForkTarget returns the fork target for the named git remote.
+func newForkTarget(remote string) forkTarget {
+	return forkTarget{remote: remote, sleep: time.Sleep}
+}
+
+// ensure returns the user's fork, making sure the fork remote exists and
+// answers. An existing remote is used as-is.
+func (f *forkTarget) ensure(gitOps git.Operations, forgeOps forge.Forge) (*forge.Fork, error) {
+	if f.fork != nil {
+		return f.fork, nil
+	}
+
+	forker, ok := forgeOps.(forge.Forker)
+	if !ok {
+		return nil, fmt.Errorf("forge %s does not support forks: %w", forgeOps.Name(), forge.ErrNotSupported)
+	}
+	fork, err := forker.EnsureFork()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := gitOps.RemoteURL(f.remote); err != nil {
+		if err := gitOps.AddRemote(f.remote, fork.CloneURL); err != nil {
+			return nil, fmt.Errorf("failed to add fork remote: %w", err)
+		}
+	}
+
+	if err := f.waitReady(gitOps); err != nil {
+		return nil, err
+	}
+
+	f.fork = fork
+	return fork, nil
+}
+
+// waitReady waits until the fork remote answers. Forges such as GitHub
+// create forks in the background, so a new fork can refuse pushes for a
+// while after EnsureFork returns.
+func (f *forkTarget) waitReady(gitOps git.Operations) error {
+	delay := forkReadyDelay
+	for attempt := 1; ; attempt++ {
+		// Any ref query fails until the repository exists
+		_, err := gitOps.RemoteBranchHead(f.remote, "HEAD")
+		if err == nil {
+			return nil
+		}
+		if attempt == forkReadyAttempts {
+			return fmt.Errorf("fork is not ready: %w", err)
+		}
+		f.sleep(delay)
+		delay *= 2
+	}
+}
+
+// head returns the PR head for a branch pushed to the fork.
+func (f *forkTarget) head(branch string) string {
+	return f.fork.Owner + ":" + branch
+}
@@ -0,0 +1,169 @@
+package apply
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/config"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
+)
+
+// MergeResult represents the outcome of merging a campaign PR in one repository.
+type MergeResult struct {
+	// Repo is the repository directory.
+	Repo string
+	// Branch is the head branch of the campaign PR.
+	Branch string
+	// PRURL is the URL of the PR (empty if no open PR was found).
+	PRURL string
+	// Action describes what was done: merged, would merge, blocked, no open
+	// PR or failed.
+	Action string
+	// Reason explains why the PR was blocked or the merge failed.
+	Reason string
+}
+
+// Merger merges the PRs of a campaign, identified by their head branch,
+// across repositories once their checks pass.
+type Merger struct {
+	cfg      *config.Config
+	repos    []string
+	newForge func(repo string) (forge.Forge, error)
+	now      func() time.Time
+	sleep    func(time.Duration)
+
+	// mu serializes merges so the pause between them is kept
+	mu        sync.Mutex
+	lastMerge time.Time
+}
+
+// NewMerger creates a new Merger for the repositories. newForge returns the
+// forge of a repository.
+func NewMerger(cfg *config.Config, repos []string, newForge func(repo string) (forge.Forge, error)) *Merger {
+	return &Merger{
+		cfg:      cfg,
+		repos:    repos,
+		newForge: newForge,
+		now:      time.Now,
+		sleep:    time.Sleep,
+	}
+}
+
+// Run works on up to the configured number of repositories at a time and
+// returns one result per repository, in the order they were given.
+func (m *Merger) Run() []*MergeResult {
+	results := make([]*MergeResult, len(m.repos))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < m.cfg.GetMaxConcurrency(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i] = m.mergeRepo(m.repos[i])
+			}
+		}()
+	}
+	for i := range m.repos {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	return results
+}
+
+// mergeRepo waits for the campaign PR in a repository to be ready and merges it.
+func (m *Merger) mergeRepo(repo string) *MergeResult {
+	result := &MergeResult{Repo: repo, Branch: m.cfg.Branch}
+	failed := func(err error) *MergeResult {
+		result.Action = "failed"
+		result.Reason = err.Error()
+		return result
+	}
+
+	forgeOps, err := m.newForge(repo)
+	if err != nil {
+		return failed(err)
+	}
+	merger, ok := forgeOps.(forge.Merger)
+	if !ok {
+		return failed(fmt.Errorf("forge %s does not support merging: %w", forgeOps.Name(), forge.ErrNotSupported))
+	}
+
+	pr, err := forgeOps.FindPR(m.cfg.Branch)
+	if err != nil {
+		return failed(err)
+	}
+	if pr == nil {
+		result.Action = "no open PR"
+		return result
+	}
+	result.PRURL = pr.URL
+	// A branch name can be reused by hand, so only merge PRs bulkfilepr opened
+	if !IsBulkfileprPR(pr.Body) {
+		return m.blocked(result, "PR was not opened by bulkfilepr")
+	}
+	if pr.Draft {
+		return m.blocked(result, "PR is a draft")
+	}
+
+	// Poll until the PR is ready, blocked, or its checks time out
+	deadline := m.now().Add(m.cfg.GetCheckTimeout())
+	for {
+		status, err := merger.PRStatus(pr)
+		if err != nil {
+			return failed(err)
+		}
+		switch {
+		case status.Conflicts:
+			return m.blocked(result, "conflicts with the base branch")
+		case status.Checks == forge.ChecksFailing:
+			return m.blocked(result, "checks failing")
+		case status.ReviewRequired:
+			return m.blocked(result, "review required")
+		}
+		if status.Checks != forge.ChecksPending {
+			break
+		}
+		if m.cfg.DryRun {
+			return m.blocked(result, "checks pending")
+		}
+		if !m.now().Before(deadline) {
+			return m.blocked(result, fmt.Sprintf("checks still pending after %s", m.cfg.GetCheckTimeout()))
+		}
+		m.sleep(m.cfg.GetPollInterval())
+	}
+
+	if m.cfg.DryRun {
+		result.Action = "would merge"
+		return result
+	}
+	if err := m.merge(merger, pr); err != nil {
+		return failed(err)
+	}
+	result.Action = "merged"
+	return result
+}
+
+// blocked marks the result as blocked for the reason.
+func (m *Merger) blocked(result *MergeResult, reason string) *MergeResult {
+	result.Action = "blocked"
+	result.Reason = reason
+	return result
+}
+
+// merge merges the PR, first waiting out the pause since the previous merge.
+func (m *Merger) merge(merger forge.Merger, pr *forge.PullRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.lastMerge.IsZero() {
+		if wait := m.lastMerge.Add(m.cfg.MergePause).Sub(m.now()); wait > 0 {
+			m.sleep(wait)
+		}
+	}
+	err := merger.MergePR(pr, forge.MergeMethod(m.cfg.GetMergeMethod()))
+	m.lastMerge = m.now()
+	return err
+}
@@ -0,0 +1,219 @@
+package apply
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/config"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
+)
+
+// fakeClock is a clock that only advances when slept on.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.sleeps = append(c.sleeps, d)
+}
+
+// newTestMerger creates a Merger over mock forges keyed by repository, with a
+// fake clock.
+func newTestMerger(cfg *config.Config, forges map[string]forge.Forge, repos ...string) (*Merger, *fakeClock) {
+	m := NewMerger(cfg, repos, func(repo string) (forge.Forge, error) {
+		forgeOps, ok := forges[repo]
+		if !ok {
+			return nil, fmt.Errorf("no forge for %s", repo)
+		}
+		return forgeOps, nil
+	})
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m.now, m.sleep = clock.Now, clock.Sleep
+	return m, clock
+}
+
+// mockWithPR returns a mock forge with an open PR for the branch.
+func mockWithPR(branch string, statuses ...*forge.PRStatus) *forge.MockForge {
+	forgeMock := forge.NewMockForge()
+	forgeMock.OpenPRs[branch] = &forge.PullRequest{
+		Number: 1,
+		URL:    "https://github.com/owner/repo/pull/1",
+		Head:   branch,
+		Body:   "Update\n\n" + `<!-- bulkfilepr path="a.txt" sha256="abc123" -->`,
+	}
+	forgeMock.Statuses = statuses
+	return forgeMock
+}
+
+func TestMergerRun(t *testing.T) {
+	ready := mockWithPR("bulkfilepr/a")
+	conflicting := mockWithPR("bulkfilepr/a", &forge.PRStatus{Checks: forge.ChecksPassing, Conflicts: true})
+	failing := mockWithPR("bulkfilepr/a", &forge.PRStatus{Checks: forge.ChecksFailing})
+	review := mockWithPR("bulkfilepr/a", &forge.PRStatus{Checks: forge.ChecksPassing, ReviewRequired: true})
+	draft := mockWithPR("bulkfilepr/a")
+	draft.OpenPRs["bulkfilepr/a"].Draft = true
+	foreign := mockWithPR("bulkfilepr/a")
+	foreign.OpenPRs["bulkfilepr/a"].Body = "Opened by hand"
+	broken := mockWithPR("bulkfilepr/a")
+	broken.MergePRErr = errors.New("405 method not allowed")
+
+	cfg := &config.Config{Branch: "bulkfilepr/a", MergeMethod: "squash"}
+	forges := map[string]forge.Forge{
+		"ready": ready, "conflicting": conflicting, "failing": failing, "review": review,
+		"draft": draft, "foreign": foreign, "none": forge.NewMockForge(), "broken": broken,
+	}
+	m, _ := newTestMerger(cfg, forges, "ready", "conflicting", "failing", "review", "draft", "foreign", "none", "broken", "missing")
+	results := m.Run()
+
+	want := []struct{ action, reason string }{
+		{"merged", ""},
+		{"blocked", "conflicts with the base branch"},
+		{"blocked", "checks failing"},
+		{"blocked", "review required"},
+		{"blocked", "PR is a draft"},
+		{"blocked", "PR was not opened by bulkfilepr"},
+		{"no open PR", ""},
+		{"failed", "405 method not allowed"},
+		{"failed", "no forge for missing"},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("results length = %d, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		if results[i].Action != w.action || results[i].Reason != w.reason {
+			t.Errorf("results[%d] (%s) = %s/%q, want %s/%q", i, results[i].Repo, results[i].Action, results[i].Reason, w.action, w.reason)
+		}
+	}
+	if ready.MergedPRs[1] != forge.MergeMethodSquash {
+		t.Errorf("MergedPRs = %v, want PR 1 squashed", ready.MergedPRs)
+	}
+	if results[0].PRURL != "https://github.com/owner/repo/pull/1" {
+		t.Errorf("PRURL = %q, want the PR URL", results[0].PRURL)
+	}
+	for _, blocked := range []*forge.MockForge{conflicting, failing, review, draft, foreign} {
+		if len(blocked.MergedPRs) != 0 {
+			t.Errorf("MergedPRs = %v, want none for a blocked PR", blocked.MergedPRs)
+		}
+	}
+}
+
+func TestMergerRunWaitsForPendingChecks(t *testing.T) {
+	pending := &forge.PRStatus{Checks: forge.ChecksPending}
+	forgeMock := mockWithPR("bulkfilepr/a", pending, pending, &forge.PRStatus{Checks: forge.ChecksPassing})
+
+	cfg := &config.Config{Branch: "bulkfilepr/a", PollInterval: 10 * time.Second}
+	m, clock := newTestMerger(cfg, map[string]forge.Forge{"repo": forgeMock}, "repo")
+	results := m.Run()
+
+	if results[0].Action != "merged" {
+		t.Errorf("Action = %q, want merged", results[0].Action)
+	}
+	if forgeMock.StatusCalls != 3 {
+		t.Errorf("StatusCalls = %d, want 3", forgeMock.StatusCalls)
+	}
+	if len(clock.sleeps) != 2 || clock.sleeps[0] != 10*time.Second {
+		t.Errorf("sleeps = %v, want two 10s polls", clock.sleeps)
+	}
+}
+
+func TestMergerRunPendingTimeout(t *testing.T) {
+	forgeMock := mockWithPR("bulkfilepr/a", &forge.PRStatus{Checks: forge.ChecksPending})
+
+	cfg := &config.Config{Branch: "bulkfilepr/a", PollInterval: time.Minute, CheckTimeout: 5 * time.Minute}
+	m, _ := newTestMerger(cfg, map[string]forge.Forge{"repo": forgeMock}, "repo")
+	results := m.Run()
+
+	if results[0].Action != "blocked" || results[0].Reason != "checks still pending after 5m0s" {
+		t.Errorf("result = %s/%q, want blocked on pending checks", results[0].Action, results[0].Reason)
+	}
+	if forgeMock.StatusCalls != 6 {
+		t.Errorf("StatusCalls = %d, want 6", forgeMock.StatusCalls)
+	}
+	if len(forgeMock.MergedPRs) != 0 {
+		t.Errorf("MergedPRs = %v, want none", forgeMock.MergedPRs)
+	}
+}
+
+func TestMergerRunDryRun(t *testing.T) {
+	ready := mockWithPR("bulkfilepr/a")
+	pending := mockWithPR("bulkfilepr/a", &forge.PRStatus{Checks: forge.ChecksPending})
+
+	cfg := &config.Config{Branch: "bulkfilepr/a", DryRun: true}
+	m, clock := newTestMerger(cfg, map[string]forge.Forge{"ready": ready, "pending": pending}, "ready", "pending")
+	results := m.Run()
+
+	if results[0].Action != "would merge" {
+		t.Errorf("results[0].Action = %q, want would merge", results[0].Action)
+	}
+	if results[1].Action != "blocked" || results[1].Reason != "checks pending" {
+		t.Errorf("results[1] = %s/%q, want blocked on pending checks", results[1].Action, results[1].Reason)
+	}
+	if len(ready.MergedPRs) != 0 || len(clock.sleeps) != 0 {
+		t.Errorf("MergedPRs = %v, sleeps = %v, want no merges or waiting", ready.MergedPRs, clock.sleeps)
+	}
+}
+
+func TestMergerRunPause(t *testing.T) {
+	a, b, c := mockWithPR("bulkfilepr/a"), mockWithPR("bulkfilepr/a"), mockWithPR("bulkfilepr/a")
+
+	cfg := &config.Config{Branch: "bulkfilepr/a", MergePause: time.Minute}
+	m, clock := newTestMerger(cfg, map[string]forge.Forge{"a": a, "b": b, "c": c}, "a", "b", "c")
+	results := m.Run()
+
+	for _, result := range results {
+		if result.Action != "merged" {
+			t.Errorf("%s Action = %q, want merged", result.Repo, result.Action)
+		}
+	}
+	// The first merge does not wait
+	if len(clock.sleeps) != 2 || clock.sleeps[0] != time.Minute || clock.sleeps[1] != time.Minute {
+		t.Errorf("sleeps = %v, want two 1m pauses", clock.sleeps)
+	}
+}
+
+func TestMergerRunConcurrency(t *testing.T) {
+	forges := map[string]forge.Forge{}
+	repos := []string{}
+	for i := 0; i < 5; i++ {
+		repo := fmt.Sprintf("repo-%d", i)
+		forges[repo] = mockWithPR("bulkfilepr/a")
+		repos = append(repos, repo)
+	}
+
+	cfg := &config.Config{Branch: "bulkfilepr/a", MaxConcurrency: 3}
+	m, _ := newTestMerger(cfg, forges, repos...)
+	results := m.Run()
+
+	// Results keep the order the repositories were given in
+	for i, result := range results {
+		if result.Repo != repos[i] || result.Action != "merged" {
+			t.Errorf("results[%d] = %s/%s, want %s/merged", i, result.Repo, result.Action, repos[i])
+		}
+	}
+}
+
+func TestMergerRunUnsupportedForge(t *testing.T) {
+	gerrit := forge.NewGerrit()
+
+	cfg := &config.Config{Branch: "bulkfilepr/a"}
+	m, _ := newTestMerger(cfg, map[string]forge.Forge{"repo": gerrit}, "repo")
+	results := m.Run()
+
+	if results[0].Action != "failed" || results[0].Reason != "forge gerrit does not support merging: not supported by this forge" {
+		t.Errorf("result = %s/%q, want failed as unsupported", results[0].Action, results[0].Reason)
+	}
+}
@@ -0,0 +1,229 @@
+package apply
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/config"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/git"
+)
+
+// Branch config keys used to record branches committed in local-only mode.
+const (
+	pendingBaseKey         = "bulkfilepr-base"
+	pendingTitleKey        = "bulkfilepr-title"
+	pendingBodyKey         = "bulkfilepr-body"
+	pendingDraftKey        = "bulkfilepr-draft"
+	pendingLabelsKey       = "bulkfilepr-labels"
+	pendingAssigneesKey    = "bulkfilepr-assignees"
+	pendingReviewersKey    = "bulkfilepr-reviewers"
+	pendingMilestoneKey    = "bulkfilepr-milestone"
+	pendingProjectsKey     = "bulkfilepr-projects"
+	pendingWorkItemsKey    = "bulkfilepr-work-items"
+	pendingAutoCompleteKey = "bulkfilepr-auto-complete"
+	pendingTopicKey        = "bulkfilepr-topic"
+	pendingHashtagsKey     = "bulkfilepr-hashtags"
+	pendingRemoveSourceKey = "bulkfilepr-remove-source-branch"
+	pendingForkKey         = "bulkfilepr-fork"
+	pendingAutoMergeKey    = "bulkfilepr-auto-merge"
+)
+
+// pendingBranch is what publish needs to open the PR for a branch committed
+// in local-only mode.
+type pendingBranch struct {
+	// PR is the request the PR is created from.
+	PR forge.PRRequest
+	// Fork indicates whether the branch is pushed to the user's fork. The fork
+	// is only set up when the branch is published.
+	Fork bool
+	// AutoMerge is the merge method to enable auto-merge with, or empty to
+	// leave it off.
+	AutoMerge string
+}
+
+// recordPending stores the pending PR for a branch in the branch's git config
+// section, so publish can open it later.
+func recordPending(gitOps git.Operations, branch string, pending pendingBranch) error {
+	req := pending.PR
+	values := []struct{ key, value string }{
+		{pendingTitleKey, req.Title},
+		{pendingBodyKey, req.Body},
+		{pendingDraftKey, strconv.FormatBool(req.Draft)},
+		{pendingLabelsKey, strings.Join(req.Labels, ",")},
+		{pendingAssigneesKey, strings.Join(req.Assignees, ",")},
+		{pendingReviewersKey, strings.Join(req.Reviewers, ",")},
+		{pendingMilestoneKey, req.Milestone},
+		{pendingProjectsKey, strings.Join(req.Projects, ",")},
+		{pendingWorkItemsKey, strings.Join(req.WorkItems, ",")},
+		{pendingAutoCompleteKey, strconv.FormatBool(req.AutoComplete)},
+		{pendingTopicKey, req.Topic},
+		{pendingHashtagsKey, strings.Join(req.Hashtags, ",")},
+		{pendingRemoveSourceKey, strconv.FormatBool(req.RemoveSourceBranch)},
+		{pendingForkKey, strconv.FormatBool(pending.Fork)},
+		{pendingAutoMergeKey, pending.AutoMerge},
+		// The base is written last since its presence marks the branch as pending
+		{pendingBaseKey, req.Base},
+	}
+	for _, v := range values {
+		if err := gitOps.SetBranchConfig(branch, v.key, v.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadPending reads the pending PR recorded for a branch.
+func loadPending(gitOps git.Operations, branch string) (pendingBranch, error) {
+	pending := pendingBranch{PR: forge.PRRequest{Head: branch}}
+	req := &pending.PR
+	var draft, labels, assignees, reviewers, projects, workItems, autoComplete, hashtags, removeSource, fork string
+	values := []struct {
+		key    string
+		target *string
+	}{
+		{pendingBaseKey, &req.Base},
+		{pendingTitleKey, &req.Title},
+		{pendingBodyKey, &req.Body},
+		{pendingDraftKey, &draft},
+		{pendingLabelsKey, &labels},
+		{pendingAssigneesKey, &assignees},
+		{pendingReviewersKey, &reviewers},
+		{pendingMilestoneKey, &req.Milestone},
+		{pendingProjectsKey, &projects},
+		{pendingWorkItemsKey, &workItems},
+		{pendingAutoCompleteKey, &autoComplete},
+		{pendingTopicKey, &req.Topic},
+		{pendingHashtagsKey, &hashtags},
+		{pendingRemoveSourceKey, &removeSource},
+		{pendingForkKey, &fork},
+		{pendingAutoMergeKey, &pending.AutoMerge},
+	}
+	for _, v := range values {
+		value, err := gitOps.GetBranchConfig(branch, v.key)
+		if err != nil {
+			return pendingBranch{}, err
+		}
+		*v.target = value
+	}
+
+	req.Draft = draft == "true"
+	req.Labels = splitList(labels)
+	req.Assignees = splitList(assignees)
+	req.Reviewers = splitList(reviewers)
+	req.Projects = splitList(projects)
+	req.WorkItems = splitList(workItems)
+	req.AutoComplete = autoComplete == "true"
+	req.Hashtags = splitList(hashtags)
+	req.RemoveSourceBranch = removeSource == "true"
+	pending.Fork = fork == "true"
+	return pending, nil
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(s string) []string {
+	var values []string
+	for _, value := range strings.Split(s, ",") {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// clearPending removes the recorded PR request for a branch.
+func clearPending(gitOps git.Operations, branch string) error {
+	// The base is removed first so a partial failure never leaves a branch that
+	// looks pending without its details
+	keys := []string{
+		pendingBaseKey, pendingTitleKey, pendingBodyKey, pendingDraftKey,
+		pendingLabelsKey, pendingAssigneesKey, pendingReviewersKey,
+		pendingMilestoneKey, pendingProjectsKey,
+		pendingWorkItemsKey, pendingAutoCompleteKey, pendingTopicKey, pendingHashtagsKey,
+		pendingRemoveSourceKey, pendingForkKey, pendingAutoMergeKey,
+	}
+	for _, key := range keys {
+		if err := gitOps.UnsetBranchConfig(branch, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Publisher pushes branches previously committed in local-only mode and opens
+// their PRs.
+type Publisher struct {
+	cfg      *config.Config
+	gitOps   git.Operations
+	forgeOps forge.Forge
+	forks    forkTarget
+}
+
+// NewPublisher creates a new Publisher instance.
+func NewPublisher(cfg *config.Config, gitOps git.Operations, forgeOps forge.Forge) *Publisher {
+	return &Publisher{
+		cfg:      cfg,
+		gitOps:   gitOps,
+		forgeOps: forgeOps,
+		forks:    newForkTarget(cfg.GetForkRemote()),
+	}
+}
+
+// Run publishes every pending branch, or only the configured branch if one is
+// set, and returns one result per branch. Results for branches published
+// before a failure are returned alongside the error.
+func (p *Publisher) Run() ([]*Result, error) {
+	branches, err := p.gitOps.ListBranchesWithConfig(pendingBaseKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending branches: %w", err)
+	}
+
+	results := []*Result{}
+	for _, branch := range branches {
+		if p.cfg.Branch != "" && branch != p.cfg.Branch {
+			continue
+		}
+
+		pending, err := loadPending(p.gitOps, branch)
+		if err != nil {
+			return results, fmt.Errorf("failed to read pending branch %s: %w", branch, err)
+		}
+		pr := pending.PR
+		result := &Result{BaseBranch: pr.Base, BranchName: branch}
+
+		if p.cfg.DryRun {
+			result.Action = "would publish"
+			results = append(results, result)
+			continue
+		}
+
+		remote := p.cfg.Remote
+		if pending.Fork {
+			if _, err := p.forks.ensure(p.gitOps, p.forgeOps); err != nil {
+				return results, fmt.Errorf("failed to set up fork for %s: %w", branch, err)
+			}
+			remote, pr.Head = p.forks.remote, p.forks.head(branch)
+		}
+		created, warnings, err := submit(p.gitOps, p.forgeOps, remote, branch, pr)
+		if err != nil {
+			return results, fmt.Errorf("failed to publish %s: %w", branch, err)
+		}
+		if err := clearPending(p.gitOps, branch); err != nil {
+			return results, fmt.Errorf("failed to clear pending state for %s: %w", branch, err)
+		}
+
+		result.PRURL = created.URL
+		result.Warnings = warnings
+		result.Action = "updated"
+		if pending.AutoMerge != "" {
+			enableAutoMerge(p.forgeOps, created, pending.AutoMerge, result)
+		}
+		results = append(results, result)
+	}
+
+	if p.cfg.Branch != "" && len(results) == 0 {
+		return nil, fmt.Errorf("branch %q is not pending publication", p.cfg.Branch)
+	}
+	return results, nil
+}
@@ -0,0 +1,175 @@
+package apply
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/config"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/git"
+)
+
+func TestApplierNoPush(t *testing.T) {
+	tmpDir := t.TempDir()
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	newContent := []byte("new content\n")
+
+	cfg := &config.Config{
+		Mode:        config.ModeUpsert,
+		RepoPath:    "test/file.txt",
+		NewFile:     "/path/to/new.txt",
+		Repo:        tmpDir,
+		Remote:      "origin",
+		PRTitle:     "Standardize file",
+		Draft:       true,
+		NoPush:      true,
+		Labels:      []string{"chore", "standards"},
+		Milestone:   "Q4",
+		Projects:    []string{"acme/7"},
+		AutoMerge:   true,
+		MergeMethod: "squash",
+	}
+
+	applier := NewApplier(cfg, mock, forgeMock, newContent)
+	result, err := applier.Run()
+
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Action != "committed" {
+		t.Errorf("Action = %q, want %q", result.Action, "committed")
+	}
+	if result.CommitSHA != mock.HeadSHA {
+		t.Errorf("CommitSHA = %q, want %q", result.CommitSHA, mock.HeadSHA)
+	}
+	if len(mock.Commits) != 1 {
+		t.Errorf("Commits length = %d, want 1", len(mock.Commits))
+	}
+	if len(mock.Pushes) != 0 {
+		t.Errorf("Pushes length = %d, want 0", len(mock.Pushes))
+	}
+	if len(forgeMock.CreatedPRs) != 0 {
+		t.Errorf("CreatedPRs length = %d, want 0", len(forgeMock.CreatedPRs))
+	}
+	if mock.CurrentBranch != "main" {
+		t.Errorf("CurrentBranch = %q, want %q", mock.CurrentBranch, "main")
+	}
+
+	pending, err := loadPending(mock, result.BranchName)
+	if err != nil {
+		t.Fatalf("loadPending() error = %v", err)
+	}
+	if !strings.HasPrefix(pending.PR.Body, cfg.GetPRBody()) || !IsBulkfileprPR(pending.PR.Body) {
+		t.Errorf("pending body = %q, want the generated body", pending.PR.Body)
+	}
+	want := pendingBranch{
+		PR: forge.PRRequest{
+			Base:      "main",
+			Head:      result.BranchName,
+			Title:     "Standardize file",
+			Body:      pending.PR.Body,
+			Draft:     true,
+			Labels:    []string{"chore", "standards"},
+			Milestone: "Q4",
+			Projects:  []string{"acme/7"},
+		},
+		AutoMerge: "squash",
+	}
+	if !reflect.DeepEqual(pending, want) {
+		t.Errorf("pending = %+v, want %+v", pending, want)
+	}
+}
+
+func TestPublisherRun(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	pending := forge.PRRequest{Base: "release/1.x", Title: "Update file", Body: "Body", Draft: true, Assignees: []string{"alice"}}
+	if err := recordPending(mock, "bulkfilepr/file-txt-abc", pendingBranch{PR: pending}); err != nil {
+		t.Fatalf("recordPending() error = %v", err)
+	}
+
+	cfg := &config.Config{Repo: t.TempDir(), Remote: "origin"}
+	results, err := NewPublisher(cfg, mock, forgeMock).Run()
+
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("results length = %d, want 1", len(results))
+	}
+	if results[0].Action != "updated" || results[0].PRURL != forgeMock.PRURLToReturn {
+		t.Errorf("results[0] = %+v, want updated with PR URL", results[0])
+	}
+	if len(mock.Pushes) != 1 || mock.Pushes[0].Branch != "bulkfilepr/file-txt-abc" {
+		t.Errorf("Pushes = %v, want [{origin bulkfilepr/file-txt-abc}]", mock.Pushes)
+	}
+	if len(forgeMock.CreatedPRs) != 1 {
+		t.Fatalf("CreatedPRs length = %d, want 1", len(forgeMock.CreatedPRs))
+	}
+	pr := forgeMock.CreatedPRs[0]
+	if pr.Base != "release/1.x" || pr.Head != "bulkfilepr/file-txt-abc" || pr.Title != "Update file" || pr.Body != "Body" || !pr.Draft || !reflect.DeepEqual(pr.Assignees, []string{"alice"}) {
+		t.Errorf("CreatedPRs[0] = %+v, want recorded details", pr)
+	}
+
+	// Published branches are no longer pending
+	branches, _ := mock.ListBranchesWithConfig(pendingBaseKey)
+	if len(branches) != 0 {
+		t.Errorf("pending branches = %v, want none", branches)
+	}
+}
+
+func TestPublisherRunDryRun(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	if err := recordPending(mock, "bulkfilepr/a", pendingBranch{PR: forge.PRRequest{Base: "main"}}); err != nil {
+		t.Fatalf("recordPending() error = %v", err)
+	}
+
+	cfg := &config.Config{Repo: t.TempDir(), Remote: "origin", DryRun: true}
+	results, err := NewPublisher(cfg, mock, forgeMock).Run()
+
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Action != "would publish" {
+		t.Errorf("results = %v, want one would publish", results)
+	}
+	if len(mock.Pushes) != 0 {
+		t.Errorf("Pushes length = %d, want 0", len(mock.Pushes))
+	}
+}
+
+func TestPublisherRunUnknownBranch(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	if err := recordPending(mock, "bulkfilepr/a", pendingBranch{PR: forge.PRRequest{Base: "main"}}); err != nil {
+		t.Fatalf("recordPending() error = %v", err)
+	}
+
+	cfg := &config.Config{Repo: t.TempDir(), Remote: "origin", Branch: "bulkfilepr/b"}
+	_, err := NewPublisher(cfg, mock, forgeMock).Run()
+	if err == nil {
+		t.Error("Run() expected error for branch that is not pending, got nil")
+	}
+}
+
+func TestPublisherRunFork(t *testing.T) {
+	mock := git.NewMockOperations()
+	forgeMock := forge.NewMockForge()
+	if err := recordPending(mock, "bulkfilepr/a", pendingBranch{PR: forge.PRRequest{Base: "main"}, Fork: true}); err != nil {
+		t.Fatalf("recordPending() error = %v", err)
+	}
+
+	cfg := &config.Config{Repo: t.TempDir(), Remote: "origin"}
+	if _, err := NewPublisher(cfg, mock, forgeMock).Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(mock.Pushes) != 1 || mock.Pushes[0].Remote != "fork" || mock.Pushes[0].Branch != "bulkfilepr/a" {
+		t.Errorf("Pushes = %v, want [{fork bulkfilepr/a}]", mock.Pushes)
+	}
+	if len(forgeMock.CreatedPRs) != 1 || forgeMock.CreatedPRs[0].Head != "me:bulkfilepr/a" {
+		t.Errorf("CreatedPRs = %+v, want head me:bulkfilepr/a", forgeMock.CreatedPRs)
+	}
+}
@@ -0,0 +1,90 @@
+package apply
+
+import (
+	"fmt"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/config"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/git"
+)
+
+// recorder returns the forge as a Recorder, or an error naming the forge if
+// it does not keep records.
+func recorder(forgeOps forge.Forge) (forge.Recorder, error) {
+	r, ok := forgeOps.(forge.Recorder)
+	if !ok {
+		return nil, fmt.Errorf("forge %s does not keep PR records: %w", forgeOps.Name(), forge.ErrNotSupported)
+	}
+	return r, nil
+}
+
+// Status returns the PRs recorded by the forge. Forges that do not keep
+// records are asked for the open PR of the branch instead, so a branch is
+// required for them.
+func Status(forgeOps forge.Forge, branch string) ([]*forge.PullRequest, error) {
+	if r, ok := forgeOps.(forge.Recorder); ok {
+		return r.ListPRs()
+	}
+	if branch == "" {
+		return nil, fmt.Errorf("forge %s does not keep PR records, pass a branch to look up its PR: %w", forgeOps.Name(), forge.ErrNotSupported)
+	}
+	pr, err := forgeOps.FindPR(branch)
+	if err != nil {
+		return nil, err
+	}
+	if pr == nil {
+		return nil, nil
+	}
+	return []*forge.PullRequest{pr}, nil
+}
+
+// Pruner removes PR records that are closed or merged, or whose head branch
+// no longer exists locally or on the remote.
+type Pruner struct {
+	cfg      *config.Config
+	gitOps   git.Operations
+	forgeOps forge.Forge
+}
+
+// NewPruner creates a new Pruner instance.
+func NewPruner(cfg *config.Config, gitOps git.Operations, forgeOps forge.Forge) *Pruner {
+	return &Pruner{
+		cfg:      cfg,
+		gitOps:   gitOps,
+		forgeOps: forgeOps,
+	}
+}
+
+// Run removes the stale records, or only lists them in dry-run mode, and
+// returns them. Records removed before a failure are returned alongside the error.
+func (p *Pruner) Run() ([]*forge.PullRequest, error) {
+	r, err := recorder(p.forgeOps)
+	if err != nil {
+		return nil, err
+	}
+	prs, err := r.ListPRs()
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := []*forge.PullRequest{}
+	for _, pr := range prs {
+		if pr.State == "open" {
+			exists, err := p.gitOps.BranchExists(pr.Head, p.cfg.Remote)
+			if err != nil {
+				return pruned, fmt.Errorf("failed to check if branch exists: %w", err)
+			}
+			if exists {
+				continue
+			}
+		}
+
+		if !p.cfg.DryRun {
+			if err := r.DeletePR(pr.Number); err != nil {
+				return pruned, err
+			}
+		}
+		pruned = append(pruned, pr)
+	}
+	return pruned, nil
+}
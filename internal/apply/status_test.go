@@ -0,0 +1,99 @@
+package apply
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/config"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/git"
+)
+
+// writeRecords writes local forge records into a new directory.
+func writeRecords(t *testing.T, records ...string) *forge.Local {
+	t.Helper()
+	dir := t.TempDir()
+	for i, record := range records {
+		path := filepath.Join(dir, fmt.Sprintf("%d.json", i+1))
+		if err := os.WriteFile(path, []byte(record), 0644); err != nil {
+			t.Fatalf("failed to write record: %v", err)
+		}
+	}
+	local, err := forge.NewLocal(forge.Options{BaseURL: dir})
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	return local
+}
+
+func TestStatus(t *testing.T) {
+	local := writeRecords(t,
+		`{"number":1,"state":"open","base":"main","head":"bulkfilepr/a"}`,
+		`{"number":2,"state":"closed","base":"main","head":"bulkfilepr/b"}`,
+	)
+
+	prs, err := Status(local, "")
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(prs) != 2 || prs[0].Head != "bulkfilepr/a" || prs[1].State != "closed" {
+		t.Errorf("Status() = %+v, want both records", prs)
+	}
+
+}
+
+func TestStatusWithoutRecords(t *testing.T) {
+	mock := forge.NewMockForge()
+	mock.OpenPRs["bulkfilepr/a"] = &forge.PullRequest{Number: 7, URL: "https://github.com/owner/repo/pull/7", State: "open", Head: "bulkfilepr/a"}
+
+	prs, err := Status(mock, "bulkfilepr/a")
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(prs) != 1 || prs[0].Number != 7 {
+		t.Errorf("Status() = %+v, want the open PR of the branch", prs)
+	}
+
+	prs, err = Status(mock, "bulkfilepr/missing")
+	if err != nil || len(prs) != 0 {
+		t.Errorf("Status() for a branch without a PR = %+v, %v, want none", prs, err)
+	}
+
+	if _, err := Status(mock, ""); !errors.Is(err, forge.ErrNotSupported) {
+		t.Errorf("Status() without a branch error = %v, want ErrNotSupported", err)
+	}
+}
+
+func TestPrunerRun(t *testing.T) {
+	local := writeRecords(t,
+		`{"number":1,"state":"open","base":"main","head":"bulkfilepr/kept"}`,
+		`{"number":2,"state":"closed","base":"main","head":"bulkfilepr/kept"}`,
+		`{"number":3,"state":"open","base":"main","head":"bulkfilepr/deleted"}`,
+	)
+	mock := git.NewMockOperations()
+	mock.BranchExistsMap["bulkfilepr/kept"] = true
+
+	cfg := &config.Config{Remote: "origin", DryRun: true}
+	pruned, err := NewPruner(cfg, mock, local).Run()
+	if err != nil {
+		t.Fatalf("Run() dry-run error = %v", err)
+	}
+	if len(pruned) != 2 || pruned[0].Number != 2 || pruned[1].Number != 3 {
+		t.Errorf("Run() dry-run = %+v, want records 2 and 3", pruned)
+	}
+	if records, _ := local.Records(); len(records) != 3 {
+		t.Errorf("records after dry-run = %d, want 3", len(records))
+	}
+
+	cfg.DryRun = false
+	if _, err := NewPruner(cfg, mock, local).Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	records, _ := local.Records()
+	if len(records) != 1 || records[0].Number != 1 {
+		t.Errorf("records after prune = %+v, want only record 1", records)
+	}
+}
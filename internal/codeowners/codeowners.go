@@ -0,0 +1,157 @@
+package codeowners
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Locations lists where a CODEOWNERS file is looked up, relative to the
+// repository root, in order of precedence.
+var Locations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// Rule is a single CODEOWNERS line: a path pattern and the owners of the
+// paths it matches. A rule without owners leaves its paths unowned.
+type Rule struct {
+	// Pattern is the path pattern as written in the file.
+	Pattern string
+	// Owners are the users (@user), teams (@org/team) and email addresses
+	// that own the matching paths.
+	Owners []string
+
+	re *regexp.Regexp
+}
+
+// File is a parsed CODEOWNERS file.
+type File struct {
+	// Path is the location the file was read from, relative to the
+	// repository root (empty if it was not loaded from a repository).
+	Path string
+	// Rules are the rules in file order.
+	Rules []Rule
+}
+
+// Load reads the first CODEOWNERS file found in the repository. It returns
+// nil without an error if the repository has none.
+func Load(repoDir string) (*File, error) {
+	for _, location := range Locations {
+		f, err := os.Open(filepath.Join(repoDir, filepath.FromSlash(location)))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", location, err)
+		}
+		file, err := Parse(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", location, err)
+		}
+		file.Path = location
+		return file, nil
+	}
+	return nil, nil
+}
+
+// Parse reads CODEOWNERS rules. Blank lines and comments are skipped, as are
+// lines with patterns that cannot be used, matching how GitHub ignores them.
+func Parse(r io.Reader) (*File, error) {
+	file := &File{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		pattern := strings.ReplaceAll(fields[0], `\#`, "#")
+		re, err := compile(pattern)
+		if err != nil {
+			continue
+		}
+		rule := Rule{Pattern: pattern, re: re}
+		for _, owner := range fields[1:] {
+			// The rest of the line is an inline comment
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			rule.Owners = append(rule.Owners, owner)
+		}
+		file.Rules = append(file.Rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Owners returns the owners of a slash-separated path relative to the
+// repository root. The last matching rule wins, so nil is returned when no
+// rule matches or the matching rule has no owners.
+func (f *File) Owners(path string) []string {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		if f.Rules[i].re.MatchString(path) {
+			return f.Rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// compile converts a CODEOWNERS pattern into a regular expression. Patterns
+// follow gitignore rules: a leading or inner slash anchors the pattern to the
+// repository root, otherwise it matches at any depth; * and ? do not match
+// slashes while ** does; and a pattern matching a directory matches
+// everything below it, except that a trailing /* only matches the
+// directory's direct children.
+func compile(pattern string) (*regexp.Regexp, error) {
+	if strings.Contains(pattern, "!") || strings.Contains(pattern, "[") {
+		return nil, fmt.Errorf("unsupported pattern %q", pattern)
+	}
+
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			b.WriteString("[^/]*")
+		case pattern[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	switch {
+	case dirOnly:
+		b.WriteString("/.*")
+	case pattern == "*" || strings.HasSuffix(pattern, "/*"):
+		// Only the direct children of the directory
+	default:
+		b.WriteString("(?:/.*)?")
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,103 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const example = `# Default owners
+*       @global-owner1 @global-owner2
+
+*.js    @js-owner # JavaScript
+*.go    docs@example.com
+/build/logs/ @doctocat
+docs/*  @docs-team
+apps/   @octocat
+/scripts/ @doctocat @octocat
+**/logs @logs-owner
+/apps/github
+\#notes @notes-owner
+`
+
+func TestOwners(t *testing.T) {
+	file, err := Parse(strings.NewReader(example))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"README.md", []string{"@global-owner1", "@global-owner2"}},
+		{"src/app.js", []string{"@js-owner"}},
+		{"main.go", []string{"docs@example.com"}},
+		{"build/logs/today.log", []string{"@logs-owner"}},
+		{"build/logs/2024/today.log", []string{"@logs-owner"}},
+		{"docs/getting-started.md", []string{"@docs-team"}},
+		{"docs/build-app/troubleshooting.md", []string{"@global-owner1", "@global-owner2"}},
+		{"apps/web/index.html", []string{"@octocat"}},
+		{"services/apps/index.html", []string{"@octocat"}},
+		{"scripts/release.sh", []string{"@doctocat", "@octocat"}},
+		{"deeply/nested/logs", []string{"@logs-owner"}},
+		{"apps/github/main.rb", nil},
+		{"#notes", []string{"@notes-owner"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := file.Owners(tt.path); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Owners(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOwnersAnchoring(t *testing.T) {
+	file, err := Parse(strings.NewReader("/ci.yml @root-owner\nconfig/ci.yml @config-owner\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := file.Owners("ci.yml"); !reflect.DeepEqual(got, []string{"@root-owner"}) {
+		t.Errorf("Owners(ci.yml) = %v", got)
+	}
+	if got := file.Owners("sub/ci.yml"); got != nil {
+		t.Errorf("Owners(sub/ci.yml) = %v, want nil for an anchored pattern", got)
+	}
+	if got := file.Owners("sub/config/ci.yml"); got != nil {
+		t.Errorf("Owners(sub/config/ci.yml) = %v, want nil for a pattern with an inner slash", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	file, err := Load(dir)
+	if err != nil || file != nil {
+		t.Fatalf("Load() = %v, %v, want nil without CODEOWNERS", file, err)
+	}
+
+	// .github/CODEOWNERS takes precedence over the root file
+	if err := os.WriteFile(filepath.Join(dir, "CODEOWNERS"), []byte("* @root\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".github"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".github", "CODEOWNERS"), []byte("* @github\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err = Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if file.Path != ".github/CODEOWNERS" {
+		t.Errorf("Path = %q, want %q", file.Path, ".github/CODEOWNERS")
+	}
+	if got := file.Owners("LICENSE"); !reflect.DeepEqual(got, []string{"@github"}) {
+		t.Errorf("Owners(LICENSE) = %v, want [@github]", got)
+	}
+}
@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"text/template"
+	"time"
 )
 
 // Mode represents the file update mode for the apply command.
@@ -43,22 +46,162 @@ type Config struct {
 	Repo string
 	// Branch is the name of the branch to create (optional, auto-generated if empty).
 	Branch string
-	// CommitMessage is the commit message (default: "chore: update {repo-path}").
+	// CommitMessage is a Go template rendered into the commit message
+	// (default: "chore: update <repo-path>").
 	CommitMessage string
-	// PRTitle is the PR title (default: "Update {repo-path}").
+	// PRTitle is a Go template rendered into the PR title (default:
+	// "Update <repo-path>").
 	PRTitle string
-	// PRBody is the PR body content.
+	// PRBody is a Go template rendered into the PR body (default: a summary
+	// of the change).
 	PRBody string
+	// CommitMessageTemplate is a Go template rendered into the commit message
+	// (optional, read from --commit-message-file).
+	CommitMessageTemplate string
+	// PRBodyTemplate is a Go template rendered into the PR body (optional,
+	// read from --pr-body-file).
+	PRBodyTemplate string
+	// AppendPRTemplate appends the repository's own PR template to the PR body.
+	AppendPRTemplate bool
+	// Signoff adds a Signed-off-by trailer to the commit (DCO).
+	Signoff bool
+	// Trailers are extra commit trailers, each as key=value.
+	Trailers []string
+	// CoAuthors are added as Co-authored-by trailers, each as "Name <email>".
+	CoAuthors []string
+	// NoContentTrailer omits the trailer recording the new content's SHA-256.
+	NoContentTrailer bool
+	// AuthorName and AuthorEmail override git's author identity (optional).
+	AuthorName  string
+	AuthorEmail string
+	// CommitterName and CommitterEmail override git's committer identity
+	// (default: the author given with AuthorName and AuthorEmail).
+	CommitterName  string
+	CommitterEmail string
+	// Sign signs the commit with git's configured signing key.
+	Sign bool
+	// SigningKey is the key to sign the commit with, which implies Sign.
+	SigningKey string
+	// SigningFormat is the signature format: openpgp, ssh or x509 (default:
+	// git's gpg.format).
+	SigningFormat string
 	// Draft indicates whether to create the PR as a draft.
 	Draft bool
 	// DryRun indicates whether to run in dry-run mode (no changes made).
 	DryRun bool
 	// Remote is the git remote name (default: origin).
 	Remote string
+	// DefaultBranch overrides default branch detection (optional).
+	DefaultBranch string
 	// ExpectSHA256 is the expected SHA-256 hash for match mode.
 	ExpectSHA256 string
+	// BranchTemplate is the template used to generate branch names when Branch is empty.
+	BranchTemplate string
+	// BranchPrefix is the value substituted for {prefix} in the branch template.
+	BranchPrefix string
+	// Direct indicates whether to commit onto the base branch and push it
+	// directly instead of creating a branch and PR.
+	Direct bool
+	// NoPush indicates whether to stop after committing on the new branch,
+	// leaving the push and PR to a later publish.
+	NoPush bool
+	// ExportPatch is the directory to write an mbox of the change to instead
+	// of pushing it (optional).
+	ExportPatch string
+	// AutoStash indicates whether to stash local changes (including untracked
+	// files) before applying and restore them afterwards.
+	AutoStash bool
+	// Bases are the branches to base changes on; entries may be glob patterns
+	// matched against remote branches (default: the repository's default branch).
+	Bases []string
+	// Labels are added to the PR when it is created (optional).
+	Labels []string
+	// Assignees are user names assigned to the PR when it is created (optional).
+	Assignees []string
+	// Reviewers are user names asked to review the PR when it is created (optional).
+	Reviewers []string
+	// NoCodeOwners disables requesting reviews from the owners of RepoPath in
+	// the repository's CODEOWNERS file.
+	NoCodeOwners bool
+	// Milestone is the title or number of the milestone to add the PR to (optional).
+	Milestone string
+	// Projects are project boards to add the PR to when it is created (optional).
+	Projects []string
+	// WorkItems are IDs of work items to link to the PR, where supported (optional).
+	WorkItems []string
+	// AutoComplete asks the forge to complete the PR once its policies pass,
+	// where supported.
+	AutoComplete bool
+	// Topic groups the changes of a campaign on forges that support it (optional).
+	Topic string
+	// Hashtags are attached to the change on forges that support them (optional).
+	Hashtags []string
+	// RemoveSourceBranch asks the forge to delete the PR branch once it is
+	// merged, where supported.
+	RemoveSourceBranch bool
+	// AutoMerge indicates whether to enable auto-merge on the created PR.
+	AutoMerge bool
+	// MergeMethod is how auto-merged PRs are merged: merge, squash or rebase
+	// (default: merge).
+	MergeMethod string
+	// MaxConcurrency is the number of repositories the merge command works
+	// on at the same time (default: 1).
+	MaxConcurrency int
+	// MergePause is the minimum time between two merges (optional).
+	MergePause time.Duration
+	// PollInterval is how often the merge command checks pending PRs again
+	// (default: 30s).
+	PollInterval time.Duration
+	// CheckTimeout is how long the merge command waits for pending checks
+	// before giving up on a PR (default: 30m).
+	CheckTimeout time.Duration
+	// Fork indicates whether to push the branch to the user's fork and open
+	// the PR from there.
+	Fork bool
+	// ForkRemote is the name of the git remote for the fork (default: fork).
+	ForkRemote string
+	// Version is the bulkfilepr version reported in generated PR bodies (optional).
+	Version string
+	// TrackingRepo is the URL of the repository holding the campaign's
+	// tracking issue (optional).
+	TrackingRepo string
+	// TrackingTitle is the title of the tracking issue (default: derived
+	// from the branch).
+	TrackingTitle string
+	// TrackingForge is the forge of the tracking repository (default: auto).
+	TrackingForge string
 }
 
+const (
+	// DefaultBranchTemplate is the branch template used when none is configured.
+	DefaultBranchTemplate = "{prefix}/{path-slug}-{hash}"
+	// DefaultBranchPrefix is the branch prefix used when none is configured.
+	DefaultBranchPrefix = "bulkfilepr"
+	// DefaultMergeMethod is the merge method used when none is configured.
+	DefaultMergeMethod = "merge"
+	// DefaultMaxConcurrency is the merge concurrency used when none is configured.
+	DefaultMaxConcurrency = 1
+	// DefaultPollInterval is the check poll interval used when none is configured.
+	DefaultPollInterval = 30 * time.Second
+	// DefaultCheckTimeout is the check timeout used when none is configured.
+	DefaultCheckTimeout = 30 * time.Minute
+	// DefaultTrackingForge is the tracking repository forge used when none is configured.
+	DefaultTrackingForge = "auto"
+	// DefaultForkRemote is the fork remote name used when none is configured.
+	DefaultForkRemote = "fork"
+)
+
+// BranchPlaceholders lists the placeholders supported in branch templates.
+var BranchPlaceholders = []string{"{prefix}", "{path-slug}", "{hash}", "{mode}", "{date}", "{base}"}
+
+var placeholderRe = regexp.MustCompile(`\{[^{}]*\}`)
+
+// trailerKeyRe matches a valid git trailer key.
+var trailerKeyRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// coAuthorRe matches a "Name <email>" identity.
+var coAuthorRe = regexp.MustCompile(`^[^<>\n]+ <[^<>\s]+@[^<>\s]+>$`)
+
 // DefaultConfig returns a Config with default values.
 func DefaultConfig() *Config {
 	return &Config{
@@ -81,6 +224,185 @@ func (c *Config) Validate() error {
 	if c.Mode == ModeMatch && c.ExpectSHA256 == "" {
 		return fmt.Errorf("expect-sha256 is required when mode is 'match'")
 	}
+	if c.Direct && c.Draft {
+		return fmt.Errorf("draft cannot be used with direct push")
+	}
+	if c.Direct && c.Branch != "" {
+		return fmt.Errorf("branch cannot be used with direct push")
+	}
+	if c.Direct && c.NoPush {
+		return fmt.Errorf("no-push cannot be used with direct push")
+	}
+	if c.ExportPatch != "" && (c.Direct || c.NoPush) {
+		return fmt.Errorf("export-patch cannot be used with direct push or no-push")
+	}
+	if c.AutoMerge && (c.Direct || c.ExportPatch != "") {
+		return fmt.Errorf("auto-merge cannot be used with direct push or export-patch")
+	}
+	if err := c.validateMergeMethod(); err != nil {
+		return err
+	}
+	if c.Fork && (c.Direct || c.ExportPatch != "") {
+		return fmt.Errorf("fork cannot be used with direct push or export-patch")
+	}
+	for _, base := range c.Bases {
+		if strings.TrimSpace(base) == "" {
+			return fmt.Errorf("base must not be empty")
+		}
+	}
+	if err := validatePlaceholders(c.GetBranchTemplate()); err != nil {
+		return fmt.Errorf("invalid branch-template: %w", err)
+	}
+	if c.CommitMessage != "" && c.CommitMessageTemplate != "" {
+		return fmt.Errorf("commit-message and commit-message-file cannot be used together")
+	}
+	if c.PRBody != "" && c.PRBodyTemplate != "" {
+		return fmt.Errorf("pr-body and pr-body-file cannot be used together")
+	}
+	templates := []struct{ flag, text string }{
+		{"commit-message", c.CommitMessage},
+		{"commit-message-file", c.CommitMessageTemplate},
+		{"pr-title", c.PRTitle},
+		{"pr-body", c.PRBody},
+		{"pr-body-file", c.PRBodyTemplate},
+	}
+	for _, t := range templates {
+		if _, err := template.New(t.flag).Parse(t.text); err != nil {
+			return fmt.Errorf("invalid %s: %w", t.flag, err)
+		}
+	}
+	for _, trailer := range c.Trailers {
+		if _, _, err := ParseTrailer(trailer); err != nil {
+			return err
+		}
+	}
+	for _, coAuthor := range c.CoAuthors {
+		if !coAuthorRe.MatchString(coAuthor) {
+			return fmt.Errorf("invalid co-author %q: expected \"Name <email>\"", coAuthor)
+		}
+	}
+	if err := c.validateIdentity(); err != nil {
+		return err
+	}
+	// The default tracking title names the campaign branch, which must be the
+	// same in every repository
+	if c.TrackingRepo != "" && c.TrackingTitle == "" {
+		if c.Direct {
+			return fmt.Errorf("tracking-repo with direct requires tracking-title")
+		}
+		if c.Branch == "" && strings.Contains(c.GetBranchTemplate(), "{base}") {
+			return fmt.Errorf("tracking-repo requires tracking-title when branch-template contains {base}")
+		}
+	}
+	return c.validateTracking()
+}
+
+// ValidateMerge checks that the configuration is valid for the merge command.
+func (c *Config) ValidateMerge() error {
+	if c.Branch == "" {
+		return fmt.Errorf("branch is required")
+	}
+	if err := c.validateMergeMethod(); err != nil {
+		return err
+	}
+	if c.MaxConcurrency < 0 {
+		return fmt.Errorf("max-concurrency must not be negative")
+	}
+	if c.MergePause < 0 || c.PollInterval < 0 || c.CheckTimeout < 0 {
+		return fmt.Errorf("pause, poll-interval and timeout must not be negative")
+	}
+	return c.validateTracking()
+}
+
+// ValidateStatus checks that the configuration is valid for the status command.
+func (c *Config) ValidateStatus() error {
+	if c.TrackingRepo != "" && c.TrackingTitle == "" && c.Branch == "" {
+		return fmt.Errorf("tracking-repo requires tracking-title or branch")
+	}
+	return c.validateTracking()
+}
+
+// validateTracking checks that tracking issue options are only set together
+// with the tracking repository.
+func (c *Config) validateTracking() error {
+	if c.TrackingRepo == "" && (c.TrackingTitle != "" || c.TrackingForge != "") {
+		return fmt.Errorf("tracking-title and tracking-forge require tracking-repo")
+	}
+	return nil
+}
+
+// GetTrackingForge returns the tracking repository's forge, substituting defaults if necessary.
+func (c *Config) GetTrackingForge() string {
+	if c.TrackingForge != "" {
+		return c.TrackingForge
+	}
+	return DefaultTrackingForge
+}
+
+// validateMergeMethod checks that the merge method is known.
+func (c *Config) validateMergeMethod() error {
+	switch c.GetMergeMethod() {
+	case "merge", "squash", "rebase":
+		return nil
+	default:
+		return fmt.Errorf("invalid merge-method %q, must be one of: merge, squash, rebase", c.MergeMethod)
+	}
+}
+
+// validateIdentity checks the commit identity and signing settings.
+func (c *Config) validateIdentity() error {
+	if (c.AuthorName == "") != (c.AuthorEmail == "") {
+		return fmt.Errorf("author-name and author-email must be used together")
+	}
+	if (c.CommitterName == "") != (c.CommitterEmail == "") {
+		return fmt.Errorf("committer-name and committer-email must be used together")
+	}
+	for _, value := range []string{c.AuthorName, c.AuthorEmail, c.CommitterName, c.CommitterEmail} {
+		if strings.ContainsAny(value, "<>\r\n") {
+			return fmt.Errorf("invalid identity %q: must not contain angle brackets or newlines", value)
+		}
+	}
+	switch c.SigningFormat {
+	case "", "openpgp", "ssh", "x509":
+	default:
+		return fmt.Errorf("invalid signing-format %q: must be openpgp, ssh or x509", c.SigningFormat)
+	}
+	if c.SigningFormat != "" && !c.SignsCommits() {
+		return fmt.Errorf("signing-format requires sign or signing-key")
+	}
+	return nil
+}
+
+// ParseTrailer splits a key=value trailer into its key and value.
+func ParseTrailer(trailer string) (string, string, error) {
+	key, value, ok := strings.Cut(trailer, "=")
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if !ok || !trailerKeyRe.MatchString(key) {
+		return "", "", fmt.Errorf("invalid trailer %q: expected key=value with a key of letters, digits and dashes", trailer)
+	}
+	if value == "" || strings.ContainsAny(value, "\r\n") {
+		return "", "", fmt.Errorf("invalid trailer %q: value must be a single non-empty line", trailer)
+	}
+	return key, value, nil
+}
+
+// validatePlaceholders checks that a branch template only uses known placeholders.
+func validatePlaceholders(template string) error {
+	if strings.TrimSpace(template) == "" {
+		return fmt.Errorf("template is empty")
+	}
+	for _, placeholder := range placeholderRe.FindAllString(template, -1) {
+		known := false
+		for _, p := range BranchPlaceholders {
+			if placeholder == p {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown placeholder %s, must be one of: %s", placeholder, strings.Join(BranchPlaceholders, ", "))
+		}
+	}
 	return nil
 }
 
@@ -90,7 +412,7 @@ func (c *Config) GetExpectedHashes() []string {
 	if c.ExpectSHA256 == "" {
 		return []string{}
 	}
-	
+
 	// Split by comma and trim whitespace
 	hashes := strings.Split(c.ExpectSHA256, ",")
 	result := make([]string, 0, len(hashes))
@@ -103,6 +425,29 @@ func (c *Config) GetExpectedHashes() []string {
 	return result
 }
 
+// GetCommitterName returns the committer name, defaulting to the author name
+// so a bot identity covers both.
+func (c *Config) GetCommitterName() string {
+	if c.CommitterName != "" {
+		return c.CommitterName
+	}
+	return c.AuthorName
+}
+
+// GetCommitterEmail returns the committer email, defaulting to the author email.
+func (c *Config) GetCommitterEmail() string {
+	if c.CommitterEmail != "" {
+		return c.CommitterEmail
+	}
+	return c.AuthorEmail
+}
+
+// SignsCommits reports whether commits are signed, with the configured key
+// or git's own.
+func (c *Config) SignsCommits() bool {
+	return c.Sign || c.SigningKey != ""
+}
+
 // GetCommitMessage returns the commit message, substituting defaults if necessary.
 func (c *Config) GetCommitMessage() string {
 	if c.CommitMessage != "" {
@@ -126,3 +471,59 @@ func (c *Config) GetPRBody() string {
 	}
 	return fmt.Sprintf("This PR updates the standardized file at `%s`.", c.RepoPath)
 }
+
+// GetBranchTemplate returns the branch template, substituting defaults if necessary.
+func (c *Config) GetBranchTemplate() string {
+	if c.BranchTemplate != "" {
+		return c.BranchTemplate
+	}
+	return DefaultBranchTemplate
+}
+
+// GetBranchPrefix returns the branch prefix, substituting defaults if necessary.
+func (c *Config) GetBranchPrefix() string {
+	if c.BranchPrefix != "" {
+		return c.BranchPrefix
+	}
+	return DefaultBranchPrefix
+}
+
+// GetMergeMethod returns the merge method, substituting defaults if necessary.
+func (c *Config) GetMergeMethod() string {
+	if c.MergeMethod != "" {
+		return c.MergeMethod
+	}
+	return DefaultMergeMethod
+}
+
+// GetMaxConcurrency returns the merge concurrency, substituting defaults if necessary.
+func (c *Config) GetMaxConcurrency() int {
+	if c.MaxConcurrency > 0 {
+		return c.MaxConcurrency
+	}
+	return DefaultMaxConcurrency
+}
+
+// GetPollInterval returns the check poll interval, substituting defaults if necessary.
+func (c *Config) GetPollInterval() time.Duration {
+	if c.PollInterval > 0 {
+		return c.PollInterval
+	}
+	return DefaultPollInterval
+}
+
+// GetCheckTimeout returns the check timeout, substituting defaults if necessary.
+func (c *Config) GetCheckTimeout() time.Duration {
+	if c.CheckTimeout > 0 {
+		return c.CheckTimeout
+	}
+	return DefaultCheckTimeout
+}
+
+// GetForkRemote returns the fork remote name, substituting defaults if necessary.
+func (c *Config) GetForkRemote() string {
+	if c.ForkRemote != "" {
+		return c.ForkRemote
+	}
+	return DefaultForkRemote
+}
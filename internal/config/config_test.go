@@ -1,6 +1,9 @@
 package config
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestParseMode(t *testing.T) {
 	tests := []struct {
@@ -94,6 +97,70 @@ func TestConfigValidate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "valid branch template",
+			config: &Config{
+				Mode:           ModeUpsert,
+				RepoPath:       "LICENSE",
+				NewFile:        "/path/to/LICENSE",
+				BranchTemplate: "{prefix}/{mode}/{path-slug}-{hash}-{date}",
+			},
+			expectError: false,
+		},
+		{
+			name: "branch template with unknown placeholder",
+			config: &Config{
+				Mode:           ModeUpsert,
+				RepoPath:       "LICENSE",
+				NewFile:        "/path/to/LICENSE",
+				BranchTemplate: "{prefix}/{repo}-{hash}",
+			},
+			expectError: true,
+		},
+		{
+			name: "direct push with draft",
+			config: &Config{
+				Mode:     ModeUpsert,
+				RepoPath: "LICENSE",
+				NewFile:  "/path/to/LICENSE",
+				Direct:   true,
+				Draft:    true,
+			},
+			expectError: true,
+		},
+		{
+			name: "auto-merge with squash",
+			config: &Config{
+				Mode:        ModeUpsert,
+				RepoPath:    "LICENSE",
+				NewFile:     "/path/to/LICENSE",
+				AutoMerge:   true,
+				MergeMethod: "squash",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid merge method",
+			config: &Config{
+				Mode:        ModeUpsert,
+				RepoPath:    "LICENSE",
+				NewFile:     "/path/to/LICENSE",
+				AutoMerge:   true,
+				MergeMethod: "fast-forward",
+			},
+			expectError: true,
+		},
+		{
+			name: "fork with direct push",
+			config: &Config{
+				Mode:     ModeUpsert,
+				RepoPath: "LICENSE",
+				NewFile:  "/path/to/LICENSE",
+				Direct:   true,
+				Fork:     true,
+			},
+			expectError: true,
+		},
 		{
 			name: "match mode without expect-sha256",
 			config: &Config{
@@ -103,6 +170,188 @@ func TestConfigValidate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "valid message templates",
+			config: &Config{
+				Mode:                  ModeUpsert,
+				RepoPath:              "LICENSE",
+				NewFile:               "/path/to/LICENSE",
+				CommitMessageTemplate: "chore: update {{.Path}}",
+				PRBodyTemplate:        "Updates `{{.Path}}` in {{.Repo}}.\n\n{{.PRTemplate}}",
+			},
+			expectError: false,
+		},
+		{
+			name: "pr-body with pr-body-file",
+			config: &Config{
+				Mode:           ModeUpsert,
+				RepoPath:       "LICENSE",
+				NewFile:        "/path/to/LICENSE",
+				PRBody:         "Update",
+				PRBodyTemplate: "Update {{.Path}}",
+			},
+			expectError: true,
+		},
+		{
+			name: "commit-message with commit-message-file",
+			config: &Config{
+				Mode:                  ModeUpsert,
+				RepoPath:              "LICENSE",
+				NewFile:               "/path/to/LICENSE",
+				CommitMessage:         "chore: update",
+				CommitMessageTemplate: "chore: update {{.Path}}",
+			},
+			expectError: true,
+		},
+		{
+			name: "valid trailers and co-authors",
+			config: &Config{
+				Mode:      ModeUpsert,
+				RepoPath:  "LICENSE",
+				NewFile:   "/path/to/LICENSE",
+				Trailers:  []string{"Change-Type=automated"},
+				CoAuthors: []string{"Jane Doe <jane@example.com>"},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid trailer",
+			config: &Config{
+				Mode:     ModeUpsert,
+				RepoPath: "LICENSE",
+				NewFile:  "/path/to/LICENSE",
+				Trailers: []string{"Change Type=automated"},
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid co-author",
+			config: &Config{
+				Mode:      ModeUpsert,
+				RepoPath:  "LICENSE",
+				NewFile:   "/path/to/LICENSE",
+				CoAuthors: []string{"jane@example.com"},
+			},
+			expectError: true,
+		},
+		{
+			name: "valid identity and signing",
+			config: &Config{
+				Mode:          ModeUpsert,
+				RepoPath:      "LICENSE",
+				NewFile:       "/path/to/LICENSE",
+				AuthorName:    "Standards Bot",
+				AuthorEmail:   "bot@example.com",
+				SigningKey:    "~/.ssh/bot.pub",
+				SigningFormat: "ssh",
+			},
+			expectError: false,
+		},
+		{
+			name: "author name without email",
+			config: &Config{
+				Mode:       ModeUpsert,
+				RepoPath:   "LICENSE",
+				NewFile:    "/path/to/LICENSE",
+				AuthorName: "Standards Bot",
+			},
+			expectError: true,
+		},
+		{
+			name: "committer email without name",
+			config: &Config{
+				Mode:           ModeUpsert,
+				RepoPath:       "LICENSE",
+				NewFile:        "/path/to/LICENSE",
+				CommitterEmail: "ci@example.com",
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid signing format",
+			config: &Config{
+				Mode:          ModeUpsert,
+				RepoPath:      "LICENSE",
+				NewFile:       "/path/to/LICENSE",
+				Sign:          true,
+				SigningFormat: "pgp",
+			},
+			expectError: true,
+		},
+		{
+			name: "signing format without signing",
+			config: &Config{
+				Mode:          ModeUpsert,
+				RepoPath:      "LICENSE",
+				NewFile:       "/path/to/LICENSE",
+				SigningFormat: "ssh",
+			},
+			expectError: true,
+		},
+		{
+			name: "tracking with the default title",
+			config: &Config{
+				Mode:         ModeUpsert,
+				RepoPath:     "LICENSE",
+				NewFile:      "/path/to/LICENSE",
+				TrackingRepo: "https://github.com/acme/rollouts",
+			},
+			expectError: false,
+		},
+		{
+			name: "tracking with direct and no title",
+			config: &Config{
+				Mode:         ModeUpsert,
+				RepoPath:     "LICENSE",
+				NewFile:      "/path/to/LICENSE",
+				Direct:       true,
+				TrackingRepo: "https://github.com/acme/rollouts",
+			},
+			expectError: true,
+		},
+		{
+			name: "tracking with base in branch template and no title",
+			config: &Config{
+				Mode:           ModeUpsert,
+				RepoPath:       "LICENSE",
+				NewFile:        "/path/to/LICENSE",
+				BranchTemplate: "{prefix}/{base}/{path-slug}",
+				TrackingRepo:   "https://github.com/acme/rollouts",
+			},
+			expectError: true,
+		},
+		{
+			name: "tracking with direct and a title",
+			config: &Config{
+				Mode:          ModeUpsert,
+				RepoPath:      "LICENSE",
+				NewFile:       "/path/to/LICENSE",
+				Direct:        true,
+				TrackingRepo:  "https://github.com/acme/rollouts",
+				TrackingTitle: "Rollout",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid pr-title template",
+			config: &Config{
+				Mode:     ModeUpsert,
+				RepoPath: "LICENSE",
+				NewFile:  "/path/to/LICENSE",
+				PRTitle:  "Update {{.Path",
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid pr-body-file template",
+			config: &Config{
+				Mode:           ModeUpsert,
+				RepoPath:       "LICENSE",
+				NewFile:        "/path/to/LICENSE",
+				PRBodyTemplate: "Update {{.Path",
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -270,3 +519,119 @@ func TestGetExpectedHashes(t *testing.T) {
 	}
 }
 
+func TestParseTrailer(t *testing.T) {
+	tests := []struct {
+		trailer   string
+		wantKey   string
+		wantValue string
+		wantErr   bool
+	}{
+		{trailer: "Refs=ENG-42", wantKey: "Refs", wantValue: "ENG-42"},
+		{trailer: " Change-Type = automated update ", wantKey: "Change-Type", wantValue: "automated update"},
+		{trailer: "Link=https://example.com/?a=b", wantKey: "Link", wantValue: "https://example.com/?a=b"},
+		{trailer: "Refs", wantErr: true},
+		{trailer: "=ENG-42", wantErr: true},
+		{trailer: "Refs:=ENG-42", wantErr: true},
+		{trailer: "Refs=", wantErr: true},
+		{trailer: "Refs=a\nb", wantErr: true},
+	}
+	for _, tt := range tests {
+		key, value, err := ParseTrailer(tt.trailer)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseTrailer(%q) expected error, got nil", tt.trailer)
+			}
+			continue
+		}
+		if err != nil || key != tt.wantKey || value != tt.wantValue {
+			t.Errorf("ParseTrailer(%q) = %q, %q, %v, want %q, %q", tt.trailer, key, value, err, tt.wantKey, tt.wantValue)
+		}
+	}
+}
+
+func TestGetBranchTemplate(t *testing.T) {
+	cfg := &Config{}
+	if got := cfg.GetBranchTemplate(); got != DefaultBranchTemplate {
+		t.Errorf("GetBranchTemplate() = %q, want %q", got, DefaultBranchTemplate)
+	}
+	cfg.BranchTemplate = "{prefix}/{hash}"
+	if got := cfg.GetBranchTemplate(); got != "{prefix}/{hash}" {
+		t.Errorf("GetBranchTemplate() = %q, want %q", got, "{prefix}/{hash}")
+	}
+}
+
+func TestGetBranchPrefix(t *testing.T) {
+	cfg := &Config{}
+	if got := cfg.GetBranchPrefix(); got != DefaultBranchPrefix {
+		t.Errorf("GetBranchPrefix() = %q, want %q", got, DefaultBranchPrefix)
+	}
+	cfg.BranchPrefix = "standards"
+	if got := cfg.GetBranchPrefix(); got != "standards" {
+		t.Errorf("GetBranchPrefix() = %q, want %q", got, "standards")
+	}
+}
+
+func TestConfigValidateMerge(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         Config
+		expectError bool
+	}{
+		{name: "valid", cfg: Config{Branch: "bulkfilepr/a"}, expectError: false},
+		{name: "missing branch", cfg: Config{}, expectError: true},
+		{name: "invalid merge method", cfg: Config{Branch: "bulkfilepr/a", MergeMethod: "fast-forward"}, expectError: true},
+		{name: "negative concurrency", cfg: Config{Branch: "bulkfilepr/a", MaxConcurrency: -1}, expectError: true},
+		{name: "negative pause", cfg: Config{Branch: "bulkfilepr/a", MergePause: -time.Second}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.ValidateMerge()
+			if tt.expectError && err == nil {
+				t.Error("ValidateMerge() expected error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("ValidateMerge() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetMergeDefaults(t *testing.T) {
+	cfg := &Config{}
+	if got := cfg.GetMaxConcurrency(); got != DefaultMaxConcurrency {
+		t.Errorf("GetMaxConcurrency() = %d, want %d", got, DefaultMaxConcurrency)
+	}
+	if got := cfg.GetPollInterval(); got != DefaultPollInterval {
+		t.Errorf("GetPollInterval() = %s, want %s", got, DefaultPollInterval)
+	}
+	if got := cfg.GetCheckTimeout(); got != DefaultCheckTimeout {
+		t.Errorf("GetCheckTimeout() = %s, want %s", got, DefaultCheckTimeout)
+	}
+}
+
+func TestConfigValidateStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         Config
+		expectError bool
+	}{
+		{name: "no tracking", cfg: Config{}, expectError: false},
+		{name: "tracking with branch", cfg: Config{TrackingRepo: "https://github.com/acme/rollouts", Branch: "bulkfilepr/a"}, expectError: false},
+		{name: "tracking with title", cfg: Config{TrackingRepo: "https://github.com/acme/rollouts", TrackingTitle: "Rollout"}, expectError: false},
+		{name: "tracking without title or branch", cfg: Config{TrackingRepo: "https://github.com/acme/rollouts"}, expectError: true},
+		{name: "title without tracking repo", cfg: Config{TrackingTitle: "Rollout"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.ValidateStatus()
+			if tt.expectError && err == nil {
+				t.Error("ValidateStatus() expected error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("ValidateStatus() unexpected error: %v", err)
+			}
+		})
+	}
+}
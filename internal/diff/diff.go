@@ -0,0 +1,80 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stats counts the lines a change adds and removes.
+type Stats struct {
+	// Added is the number of lines only in the new text.
+	Added int
+	// Removed is the number of lines only in the old text.
+	Removed int
+}
+
+// String summarizes the stats, e.g. "3 lines added, 1 line removed".
+func (s Stats) String() string {
+	return fmt.Sprintf("%s added, %s removed", lines(s.Added), lines(s.Removed))
+}
+
+// lines formats a line count.
+func lines(n int) string {
+	if n == 1 {
+		return "1 line"
+	}
+	return fmt.Sprintf("%d lines", n)
+}
+
+// Lines returns the stats of a line diff between two texts, as git would
+// count them. A missing final newline makes the last line differ.
+func Lines(oldText, newText []byte) Stats {
+	a, b := splitLines(string(oldText)), splitLines(string(newText))
+	d := editDistance(a, b)
+	// Every line not in the longest common subsequence is added or removed,
+	// so d = (len(a) - lcs) + (len(b) - lcs)
+	lcs := (len(a) + len(b) - d) / 2
+	return Stats{Added: len(b) - lcs, Removed: len(a) - lcs}
+}
+
+// splitLines splits text into lines, keeping their newlines.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// editDistance returns the number of insertions and deletions needed to turn a
+// into b, using Myers' O(ND) algorithm.
+func editDistance(a, b []string) int {
+	n, m := len(a), len(b)
+	max := n + m
+	// v[offset+k] is the furthest x reached on diagonal k
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return d
+			}
+		}
+	}
+	return max
+}
@@ -0,0 +1,36 @@
+package diff
+
+import "testing"
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     Stats
+	}{
+		{name: "identical", old: "a\nb\n", new: "a\nb\n", want: Stats{}},
+		{name: "new file", old: "", new: "a\nb\n", want: Stats{Added: 2}},
+		{name: "deleted content", old: "a\nb\n", new: "", want: Stats{Removed: 2}},
+		{name: "changed line", old: "a\nb\nc\n", new: "a\nB\nc\n", want: Stats{Added: 1, Removed: 1}},
+		{name: "insert and delete", old: "a\nb\nc\nd\n", new: "a\nc\nd\ne\nf\n", want: Stats{Added: 2, Removed: 1}},
+		{name: "missing final newline", old: "a\nb", new: "a\nb\n", want: Stats{Added: 1, Removed: 1}},
+		{name: "reordered", old: "a\nb\nc\n", new: "c\nb\na\n", want: Stats{Added: 2, Removed: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines([]byte(tt.old), []byte(tt.new)); got != tt.want {
+				t.Errorf("Lines() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsString(t *testing.T) {
+	if got := (Stats{Added: 1, Removed: 0}).String(); got != "1 line added, 0 lines removed" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (Stats{Added: 12, Removed: 1}).String(); got != "12 lines added, 1 line removed" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -0,0 +1,289 @@
+package forge
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// AzureDevOpsName is the name of the Azure DevOps Repos forge.
+const AzureDevOpsName = "azure-devops"
+
+// azureAPIVersion is the REST API version requested from Azure DevOps.
+const azureAPIVersion = "7.1"
+
+// AzureDevOps implements Forge for Azure DevOps Repos using the REST API.
+// The personal access token is read from AZURE_DEVOPS_TOKEN or
+// AZURE_DEVOPS_EXT_PAT unless set in the options.
+type AzureDevOps struct {
+	api     *apiClient
+	project string
+	repo    string
+}
+
+// NewAzureDevOps creates an Azure DevOps forge for the repository the remote
+// URL points at. Remotes of the form https://dev.azure.com/<org>/<project>/_git/<repo>,
+// git@ssh.dev.azure.com:v3/<org>/<project>/<repo> and on-premises
+// https://<host>/<collection>/<project>/_git/<repo> are supported. The API
+// defaults to the organization or collection URL; BaseURL overrides it.
+func NewAzureDevOps(opts Options) (*AzureDevOps, error) {
+	orgURL, project, repo, err := parseAzureRemote(opts.RemoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate Azure DevOps repository: %w", err)
+	}
+	if opts.BaseURL != "" {
+		orgURL = opts.BaseURL
+	}
+
+	token := opts.Token
+	if token == "" {
+		token = os.Getenv("AZURE_DEVOPS_TOKEN")
+	}
+	if token == "" {
+		token = os.Getenv("AZURE_DEVOPS_EXT_PAT")
+	}
+
+	headers := map[string]string{}
+	if token != "" {
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(":"+token))
+	}
+	return &AzureDevOps{
+		api:     newAPIClient(orgURL, headers),
+		project: project,
+		repo:    repo,
+	}, nil
+}
+
+// parseAzureRemote returns the organization (or collection) URL, project and
+// repository of an Azure DevOps remote URL.
+func parseAzureRemote(remoteURL string) (string, string, string, error) {
+	info, err := ParseRemoteURL(remoteURL)
+	if err != nil {
+		return "", "", "", err
+	}
+	segments := strings.Split(info.Path, "/")
+
+	// SSH remotes: v3/<org>/<project>/<repo>
+	if info.Host == "ssh.dev.azure.com" || info.Host == "vs-ssh.visualstudio.com" {
+		if len(segments) != 4 || segments[0] != "v3" {
+			return "", "", "", fmt.Errorf("unsupported Azure DevOps remote %q", remoteURL)
+		}
+		return "https://dev.azure.com/" + segments[1], segments[2], segments[3], nil
+	}
+
+	// HTTPS remotes: [<org or collection>/]<project>/_git/<repo>
+	for i, segment := range segments {
+		if segment != "_git" || i == 0 || i != len(segments)-2 {
+			continue
+		}
+		orgURL := "https://" + info.Host
+		if prefix := strings.Join(segments[:i-1], "/"); prefix != "" {
+			orgURL += "/" + prefix
+		}
+		return orgURL, segments[i-1], segments[i+1], nil
+	}
+	return "", "", "", fmt.Errorf("unsupported Azure DevOps remote %q", remoteURL)
+}
+
+// isAzureHost reports whether the host serves Azure DevOps Repos.
+func isAzureHost(host string) bool {
+	return host == "dev.azure.com" || host == "ssh.dev.azure.com" || strings.HasSuffix(host, ".visualstudio.com")
+}
+
+// Name returns the name used to select the forge.
+func (a *AzureDevOps) Name() string {
+	return AzureDevOpsName
+}
+
+// azurePullRequest is the subset of Azure DevOps' pull request fields that is used.
+type azurePullRequest struct {
+	PullRequestID int    `json:"pullRequestId"`
+	Status        string `json:"status"`
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	IsDraft       bool   `json:"isDraft"`
+	SourceRefName string `json:"sourceRefName"`
+	TargetRefName string `json:"targetRefName"`
+	CreatedBy     struct {
+		ID string `json:"id"`
+	} `json:"createdBy"`
+	Repository struct {
+		WebURL string `json:"webUrl"`
+	} `json:"repository"`
+}
+
+// pullRequest converts the Azure DevOps pull request into a PullRequest.
+func (pr *azurePullRequest) pullRequest() *PullRequest {
+	state := pr.Status
+	switch pr.Status {
+	case "active":
+		state = "open"
+	case "completed":
+		state = "merged"
+	case "abandoned":
+		state = "closed"
+	}
+	result := &PullRequest{
+		Number: pr.PullRequestID,
+		ID:     fmt.Sprint(pr.PullRequestID),
+		State:  state,
+		Base:   strings.TrimPrefix(pr.TargetRefName, "refs/heads/"),
+		Head:   strings.TrimPrefix(pr.SourceRefName, "refs/heads/"),
+		Title:  pr.Title,
+		Body:   pr.Description,
+		Draft:  pr.IsDraft,
+	}
+	if pr.Repository.WebURL != "" {
+		result.URL = fmt.Sprintf("%s/pullrequest/%d", pr.Repository.WebURL, pr.PullRequestID)
+	}
+	return result
+}
+
+// DefaultBranch returns the default branch from the repository resource.
+func (a *AzureDevOps) DefaultBranch() (string, error) {
+	var repo struct {
+		DefaultBranch string `json:"defaultBranch"`
+	}
+	if err := a.api.do(http.MethodGet, a.repoPath(""), nil, &repo); err != nil {
+		return "", fmt.Errorf("failed to get default branch: %w", err)
+	}
+	if repo.DefaultBranch == "" {
+		return "", fmt.Errorf("failed to get default branch: empty response")
+	}
+	return strings.TrimPrefix(repo.DefaultBranch, "refs/heads/"), nil
+}
+
+// SupportsMetadata reports whether the kind of metadata can be applied.
+// Reviewers are identity IDs rather than org/team names, and Azure DevOps has
+// no assignees, milestones or project boards.
+func (a *AzureDevOps) SupportsMetadata(kind Metadata) bool {
+	return kind == MetadataLabels || kind == MetadataReviewers || kind == MetadataWorkItems
+}
+
+// CreatePR creates a pull request with its labels and linked work items, then
+// adds the reviewers (given as identity IDs) and enables auto-complete if
+// asked. An identity Azure DevOps does not know is returned as a
+// *MetadataError without losing the PR.
+func (a *AzureDevOps) CreatePR(req PRRequest) (*PullRequest, error) {
+	create := map[string]any{
+		"sourceRefName": "refs/heads/" + req.Head,
+		"targetRefName": "refs/heads/" + req.Base,
+		"title":         req.Title,
+		"description":   req.Body,
+		"isDraft":       req.Draft,
+	}
+	if len(req.Labels) > 0 {
+		labels := make([]map[string]string, 0, len(req.Labels))
+		for _, label := range req.Labels {
+			labels = append(labels, map[string]string{"name": label})
+		}
+		create["labels"] = labels
+	}
+	if len(req.WorkItems) > 0 {
+		workItems := make([]map[string]string, 0, len(req.WorkItems))
+		for _, workItem := range req.WorkItems {
+			workItems = append(workItems, map[string]string{"id": workItem})
+		}
+		create["workItemRefs"] = workItems
+	}
+
+	var created azurePullRequest
+	if err := a.api.do(http.MethodPost, a.repoPath("/pullrequests"), create, &created); err != nil {
+		return nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+	pr := created.pullRequest()
+
+	var errs []error
+	if len(req.Reviewers) > 0 {
+		if err := a.RequestReviewers(pr.Number, req.Reviewers); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if req.AutoComplete {
+		// Auto-complete is set on behalf of a user, so it uses the PR's creator
+		update := map[string]any{
+			"autoCompleteSetBy": map[string]string{"id": created.CreatedBy.ID},
+			"completionOptions": map[string]any{"deleteSourceBranch": req.RemoveSourceBranch},
+		}
+		if err := a.api.do(http.MethodPatch, a.pullPath(pr.Number), update, nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to enable auto-complete: %w", err))
+		}
+	}
+	return pr, metadataError(pr, errs)
+}
+
+// FindPR returns the active pull request for the source branch, or nil if
+// there is none. Lists truncate descriptions, so the pull request found is
+// read again in full.
+func (a *AzureDevOps) FindPR(head string) (*PullRequest, error) {
+	var page struct {
+		Value []azurePullRequest `json:"value"`
+	}
+	query := "searchCriteria.status=active&searchCriteria.sourceRefName=" + url.QueryEscape("refs/heads/"+head)
+	if err := a.api.do(http.MethodGet, a.repoPath("/pullrequests")+"&"+query, nil, &page); err != nil {
+		return nil, fmt.Errorf("failed to find PR for %s: %w", head, err)
+	}
+	if len(page.Value) == 0 {
+		return nil, nil
+	}
+	var found azurePullRequest
+	if err := a.api.do(http.MethodGet, a.pullPath(page.Value[0].PullRequestID), nil, &found); err != nil {
+		return nil, fmt.Errorf("failed to find PR for %s: %w", head, err)
+	}
+	return found.pullRequest(), nil
+}
+
+// UpdatePR updates the title and description of a pull request.
+func (a *AzureDevOps) UpdatePR(number int, title, body string) error {
+	update := map[string]any{"title": title, "description": body}
+	if err := a.api.do(http.MethodPatch, a.pullPath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to update PR %d: %w", number, err)
+	}
+	return nil
+}
+
+// ClosePR abandons a pull request.
+func (a *AzureDevOps) ClosePR(number int) error {
+	update := map[string]any{"status": "abandoned"}
+	if err := a.api.do(http.MethodPatch, a.pullPath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to abandon PR %d: %w", number, err)
+	}
+	return nil
+}
+
+// AddLabels adds labels (tags) to a pull request.
+func (a *AzureDevOps) AddLabels(number int, labels []string) error {
+	for _, label := range labels {
+		path := a.repoPath(fmt.Sprintf("/pullRequests/%d/labels", number))
+		if err := a.api.do(http.MethodPost, path, map[string]string{"name": label}, nil); err != nil {
+			return fmt.Errorf("failed to add labels to PR %d: %w", number, err)
+		}
+	}
+	return nil
+}
+
+// RequestReviewers adds reviewers, given as identity IDs, to a pull request.
+func (a *AzureDevOps) RequestReviewers(number int, reviewers []string) error {
+	for _, reviewer := range reviewers {
+		path := a.repoPath(fmt.Sprintf("/pullRequests/%d/reviewers/%s", number, url.PathEscape(reviewer)))
+		if err := a.api.do(http.MethodPut, path, map[string]any{"vote": 0}, nil); err != nil {
+			return fmt.Errorf("failed to request reviewers on PR %d: %w", number, err)
+		}
+	}
+	return nil
+}
+
+// repoPath returns the API path of a resource under the repository, with the
+// API version query parameter.
+func (a *AzureDevOps) repoPath(resource string) string {
+	return fmt.Sprintf("/%s/_apis/git/repositories/%s%s?api-version=%s",
+		url.PathEscape(a.project), url.PathEscape(a.repo), resource, azureAPIVersion)
+}
+
+// pullPath returns the API path of a pull request.
+func (a *AzureDevOps) pullPath(number int) string {
+	return a.repoPath(fmt.Sprintf("/pullrequests/%d", number))
+}
@@ -0,0 +1,160 @@
+package forge
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fakeAzureDevOps starts a fake API server and an Azure DevOps forge pointed at it.
+func fakeAzureDevOps(t *testing.T, responses map[string]string) (*AzureDevOps, *[]apiRequest) {
+	t.Helper()
+	server, requests := fakeAPI(t, responses)
+	a, err := NewAzureDevOps(Options{
+		RemoteURL: "https://contoso@dev.azure.com/contoso/Platform/_git/service",
+		BaseURL:   server.URL + "/contoso",
+		Token:     "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewAzureDevOps() error = %v", err)
+	}
+	return a, requests
+}
+
+const azureRepoPath = "/contoso/Platform/_apis/git/repositories/service"
+
+func TestParseAzureRemote(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		orgURL  string
+		project string
+		repo    string
+	}{
+		{name: "https", input: "https://contoso@dev.azure.com/contoso/Platform/_git/service", orgURL: "https://dev.azure.com/contoso", project: "Platform", repo: "service"},
+		{name: "ssh", input: "git@ssh.dev.azure.com:v3/contoso/Platform/service", orgURL: "https://dev.azure.com/contoso", project: "Platform", repo: "service"},
+		{name: "visualstudio.com", input: "https://contoso.visualstudio.com/Platform/_git/service", orgURL: "https://contoso.visualstudio.com", project: "Platform", repo: "service"},
+		{name: "server collection", input: "https://tfs.example.com/tfs/DefaultCollection/Platform/_git/service", orgURL: "https://tfs.example.com/tfs/DefaultCollection", project: "Platform", repo: "service"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgURL, project, repo, err := parseAzureRemote(tt.input)
+			if err != nil {
+				t.Fatalf("parseAzureRemote(%q) error = %v", tt.input, err)
+			}
+			if orgURL != tt.orgURL || project != tt.project || repo != tt.repo {
+				t.Errorf("parseAzureRemote(%q) = %q, %q, %q, want %q, %q, %q", tt.input, orgURL, project, repo, tt.orgURL, tt.project, tt.repo)
+			}
+		})
+	}
+
+	if _, _, _, err := parseAzureRemote("https://dev.azure.com/contoso/Platform"); err == nil {
+		t.Error("parseAzureRemote() expected error for URL without _git, got nil")
+	}
+}
+
+func TestAzureDevOpsDefaultBranch(t *testing.T) {
+	a, requests := fakeAzureDevOps(t, map[string]string{
+		"GET " + azureRepoPath: `{"id":"abc","defaultBranch":"refs/heads/develop"}`,
+	})
+
+	branch, err := a.DefaultBranch()
+	if err != nil {
+		t.Fatalf("DefaultBranch() error = %v", err)
+	}
+	if branch != "develop" {
+		t.Errorf("DefaultBranch() = %q, want %q", branch, "develop")
+	}
+	req := (*requests)[0]
+	if req.Query != "api-version=7.1" {
+		t.Errorf("query = %q, want %q", req.Query, "api-version=7.1")
+	}
+	// Basic auth with an empty user name and the PAT as password
+	if got := req.Header.Get("Authorization"); got != "Basic OnNlY3JldA==" {
+		t.Errorf("Authorization = %q, want %q", got, "Basic OnNlY3JldA==")
+	}
+}
+
+func TestAzureDevOpsCreatePR(t *testing.T) {
+	a, requests := fakeAzureDevOps(t, map[string]string{
+		"POST " + azureRepoPath + "/pullrequests":                  `{"pullRequestId":55,"status":"active","title":"Title","isDraft":true,"sourceRefName":"refs/heads/feature","targetRefName":"refs/heads/main","createdBy":{"id":"user-1"},"repository":{"webUrl":"https://dev.azure.com/contoso/Platform/_git/service"}}`,
+		"PATCH " + azureRepoPath + "/pullrequests/55":              `{}`,
+		"PUT " + azureRepoPath + "/pullRequests/55/reviewers/0a1b": `{}`,
+	})
+
+	pr, err := a.CreatePR(PRRequest{
+		Base:               "main",
+		Head:               "feature",
+		Title:              "Title",
+		Body:               "Body",
+		Draft:              true,
+		Labels:             []string{"standards"},
+		Reviewers:          []string{"0a1b"},
+		WorkItems:          []string{"1234"},
+		AutoComplete:       true,
+		RemoveSourceBranch: true,
+	})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	want := &PullRequest{
+		Number: 55,
+		ID:     "55",
+		URL:    "https://dev.azure.com/contoso/Platform/_git/service/pullrequest/55",
+		State:  "open",
+		Base:   "main",
+		Head:   "feature",
+		Title:  "Title",
+		Draft:  true,
+	}
+	if !reflect.DeepEqual(pr, want) {
+		t.Errorf("CreatePR() = %+v, want %+v", pr, want)
+	}
+
+	if len(*requests) != 3 {
+		t.Fatalf("requests = %d, want 3", len(*requests))
+	}
+	expected := map[string]any{
+		"sourceRefName": "refs/heads/feature",
+		"targetRefName": "refs/heads/main",
+		"title":         "Title",
+		"description":   "Body",
+		"isDraft":       true,
+		"labels":        []any{map[string]any{"name": "standards"}},
+		"workItemRefs":  []any{map[string]any{"id": "1234"}},
+	}
+	if !reflect.DeepEqual((*requests)[0].Body, expected) {
+		t.Errorf("create body = %v, want %v", (*requests)[0].Body, expected)
+	}
+	autoComplete := map[string]any{
+		"autoCompleteSetBy": map[string]any{"id": "user-1"},
+		"completionOptions": map[string]any{"deleteSourceBranch": true},
+	}
+	if got := (*requests)[1].Path; got != azureRepoPath+"/pullRequests/55/reviewers/0a1b" {
+		t.Errorf("reviewer path = %q", got)
+	}
+	if !reflect.DeepEqual((*requests)[2].Body, autoComplete) {
+		t.Errorf("auto-complete body = %v, want %v", (*requests)[2].Body, autoComplete)
+	}
+}
+
+func TestAzureDevOpsFindPR(t *testing.T) {
+	a, requests := fakeAzureDevOps(t, map[string]string{
+		"GET " + azureRepoPath + "/pullrequests":    `{"value":[{"pullRequestId":55,"status":"active","description":"Truncated","sourceRefName":"refs/heads/feature","targetRefName":"refs/heads/main"}],"count":1}`,
+		"GET " + azureRepoPath + "/pullrequests/55": `{"pullRequestId":55,"status":"active","description":"Full description","sourceRefName":"refs/heads/feature","targetRefName":"refs/heads/main"}`,
+	})
+
+	pr, err := a.FindPR("feature")
+	if err != nil {
+		t.Fatalf("FindPR() error = %v", err)
+	}
+	if pr == nil || pr.Number != 55 || pr.Head != "feature" || pr.State != "open" {
+		t.Errorf("FindPR() = %+v, want open PR 55", pr)
+	}
+	if pr.Body != "Full description" {
+		t.Errorf("Body = %q, want the full description", pr.Body)
+	}
+	if (*requests)[0].Query != "api-version=7.1&searchCriteria.status=active&searchCriteria.sourceRefName=refs%2Fheads%2Ffeature" {
+		t.Errorf("query = %q", (*requests)[0].Query)
+	}
+}
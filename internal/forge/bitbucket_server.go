@@ -0,0 +1,244 @@
+package forge
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// BitbucketServerName is the name of the Bitbucket Server and Data Center forge.
+const BitbucketServerName = "bitbucket-server"
+
+// BitbucketServer implements Forge for Bitbucket Server and Data Center using
+// the REST API. The token is an HTTP access token read from BITBUCKET_TOKEN
+// unless set in the options.
+type BitbucketServer struct {
+	api     *apiClient
+	project string
+	repo    string
+}
+
+// NewBitbucketServer creates a Bitbucket Server forge for the repository the
+// remote URL points at. Both https://<host>/scm/<project>/<repo>.git and
+// ssh://git@<host>:7999/<project>/<repo>.git remotes are supported, and the
+// API defaults to https://<remote host>/rest/api/1.0.
+func NewBitbucketServer(opts Options) (*BitbucketServer, error) {
+	info, err := ParseRemoteURL(opts.RemoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate Bitbucket Server repository: %w", err)
+	}
+	info.Path = strings.TrimPrefix(info.Path, "scm/")
+	project, repo, err := info.OwnerRepo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate Bitbucket Server repository: %w", err)
+	}
+
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://%s/rest/api/1.0", info.Host)
+	}
+	token := opts.Token
+	if token == "" {
+		token = os.Getenv("BITBUCKET_TOKEN")
+	}
+
+	headers := map[string]string{}
+	if token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	return &BitbucketServer{
+		api:     newAPIClient(baseURL, headers),
+		project: strings.ToUpper(project),
+		repo:    repo,
+	}, nil
+}
+
+// Name returns the name used to select the forge.
+func (b *BitbucketServer) Name() string {
+	return BitbucketServerName
+}
+
+// bitbucketRepository identifies a repository in a Bitbucket Server branch reference.
+type bitbucketRepository struct {
+	Slug    string `json:"slug"`
+	Project struct {
+		Key string `json:"key"`
+	} `json:"project"`
+}
+
+// bitbucketRef is a branch reference in a Bitbucket Server pull request.
+type bitbucketRef struct {
+	ID         string               `json:"id"`
+	DisplayID  string               `json:"displayId,omitempty"`
+	Repository *bitbucketRepository `json:"repository,omitempty"`
+}
+
+// bitbucketPullRequest is the subset of Bitbucket Server's pull request fields that is used.
+type bitbucketPullRequest struct {
+	ID          int          `json:"id"`
+	Version     int          `json:"version"`
+	State       string       `json:"state"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Draft       bool         `json:"draft"`
+	FromRef     bitbucketRef `json:"fromRef"`
+	ToRef       bitbucketRef `json:"toRef"`
+	Links       struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+// pullRequest converts the Bitbucket Server pull request into a PullRequest.
+func (pr *bitbucketPullRequest) pullRequest() *PullRequest {
+	result := &PullRequest{
+		Number: pr.ID,
+		ID:     fmt.Sprint(pr.ID),
+		State:  strings.ToLower(pr.State),
+		Base:   pr.ToRef.DisplayID,
+		Head:   pr.FromRef.DisplayID,
+		Title:  pr.Title,
+		Body:   pr.Description,
+		Draft:  pr.Draft,
+	}
+	if len(pr.Links.Self) > 0 {
+		result.URL = pr.Links.Self[0].Href
+	}
+	return result
+}
+
+// DefaultBranch returns the repository's default branch.
+func (b *BitbucketServer) DefaultBranch() (string, error) {
+	var branch bitbucketRef
+	if err := b.api.do(http.MethodGet, b.repoPath()+"/branches/default", nil, &branch); err != nil {
+		return "", fmt.Errorf("failed to get default branch: %w", err)
+	}
+	if branch.DisplayID == "" {
+		return "", fmt.Errorf("failed to get default branch: empty response")
+	}
+	return branch.DisplayID, nil
+}
+
+// SupportsMetadata reports whether the kind of metadata can be applied.
+// Bitbucket Server has user reviewers but no team reviewers, labels,
+// assignees, milestones, project boards or work items.
+func (b *BitbucketServer) SupportsMetadata(kind Metadata) bool {
+	return kind == MetadataReviewers
+}
+
+// CreatePR creates a pull request, then adds its reviewers so a user name
+// Bitbucket Server does not know is returned as a *MetadataError without
+// losing the PR.
+func (b *BitbucketServer) CreatePR(req PRRequest) (*PullRequest, error) {
+	create := map[string]any{
+		"title":       req.Title,
+		"description": req.Body,
+		"fromRef":     b.ref(req.Head),
+		"toRef":       b.ref(req.Base),
+	}
+	if req.Draft {
+		create["draft"] = true
+	}
+
+	var created bitbucketPullRequest
+	if err := b.api.do(http.MethodPost, b.repoPath()+"/pull-requests", create, &created); err != nil {
+		return nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+	pr := created.pullRequest()
+
+	if len(req.Reviewers) > 0 {
+		if err := b.RequestReviewers(pr.Number, req.Reviewers); err != nil {
+			return pr, metadataError(pr, []error{err})
+		}
+	}
+	return pr, nil
+}
+
+// FindPR returns the open pull request from the source branch, or nil if there is none.
+func (b *BitbucketServer) FindPR(head string) (*PullRequest, error) {
+	var page struct {
+		Values []bitbucketPullRequest `json:"values"`
+	}
+	path := b.repoPath() + "/pull-requests?state=OPEN&direction=OUTGOING&at=" + url.QueryEscape("refs/heads/"+head)
+	if err := b.api.do(http.MethodGet, path, nil, &page); err != nil {
+		return nil, fmt.Errorf("failed to find PR for %s: %w", head, err)
+	}
+	if len(page.Values) == 0 {
+		return nil, nil
+	}
+	return page.Values[0].pullRequest(), nil
+}
+
+// UpdatePR updates the title and description of a pull request.
+func (b *BitbucketServer) UpdatePR(number int, title, body string) error {
+	pr, err := b.get(number)
+	if err != nil {
+		return fmt.Errorf("failed to update PR #%d: %w", number, err)
+	}
+	update := map[string]any{"version": pr.Version, "title": title, "description": body}
+	if err := b.api.do(http.MethodPut, b.pullPath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to update PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// ClosePR declines a pull request.
+func (b *BitbucketServer) ClosePR(number int) error {
+	pr, err := b.get(number)
+	if err != nil {
+		return fmt.Errorf("failed to decline PR #%d: %w", number, err)
+	}
+	path := fmt.Sprintf("%s/decline?version=%d", b.pullPath(number), pr.Version)
+	if err := b.api.do(http.MethodPost, path, map[string]any{}, nil); err != nil {
+		return fmt.Errorf("failed to decline PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// AddLabels is not supported since Bitbucket Server has no pull request labels.
+func (b *BitbucketServer) AddLabels(number int, labels []string) error {
+	return fmt.Errorf("failed to add labels to PR #%d: %w", number, ErrNotSupported)
+}
+
+// RequestReviewers adds users as reviewers of a pull request.
+func (b *BitbucketServer) RequestReviewers(number int, reviewers []string) error {
+	for _, reviewer := range reviewers {
+		participant := map[string]any{
+			"user": map[string]any{"name": strings.TrimPrefix(reviewer, "@")},
+			"role": "REVIEWER",
+		}
+		if err := b.api.do(http.MethodPost, b.pullPath(number)+"/participants", participant, nil); err != nil {
+			return fmt.Errorf("failed to request reviewers on PR #%d: %w", number, err)
+		}
+	}
+	return nil
+}
+
+// get fetches a pull request, which is needed for its current version.
+func (b *BitbucketServer) get(number int) (*bitbucketPullRequest, error) {
+	var pr bitbucketPullRequest
+	if err := b.api.do(http.MethodGet, b.pullPath(number), nil, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// ref returns a branch reference in this repository.
+func (b *BitbucketServer) ref(branch string) bitbucketRef {
+	repo := &bitbucketRepository{Slug: b.repo}
+	repo.Project.Key = b.project
+	return bitbucketRef{ID: "refs/heads/" + branch, Repository: repo}
+}
+
+// repoPath returns the API path of the repository.
+func (b *BitbucketServer) repoPath() string {
+	return fmt.Sprintf("/projects/%s/repos/%s", url.PathEscape(b.project), url.PathEscape(b.repo))
+}
+
+// pullPath returns the API path of a pull request.
+func (b *BitbucketServer) pullPath(number int) string {
+	return fmt.Sprintf("%s/pull-requests/%d", b.repoPath(), number)
+}
@@ -0,0 +1,162 @@
+package forge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeBitbucketServer starts a fake API server and a Bitbucket Server forge pointed at it.
+func fakeBitbucketServer(t *testing.T, responses map[string]string) (*BitbucketServer, *[]apiRequest) {
+	t.Helper()
+	server, requests := fakeAPI(t, responses)
+	b, err := NewBitbucketServer(Options{
+		RemoteURL: "https://bitbucket.example.com/scm/plat/service.git",
+		BaseURL:   server.URL + "/rest/api/1.0",
+		Token:     "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewBitbucketServer() error = %v", err)
+	}
+	return b, requests
+}
+
+const bitbucketRepoPath = "/rest/api/1.0/projects/PLAT/repos/service"
+
+func TestNewBitbucketServerSSHRemote(t *testing.T) {
+	b, err := NewBitbucketServer(Options{RemoteURL: "ssh://git@bitbucket.example.com:7999/plat/service.git"})
+	if err != nil {
+		t.Fatalf("NewBitbucketServer() error = %v", err)
+	}
+	if b.project != "PLAT" || b.repo != "service" {
+		t.Errorf("project, repo = %q, %q, want %q, %q", b.project, b.repo, "PLAT", "service")
+	}
+	if b.api.baseURL != "https://bitbucket.example.com/rest/api/1.0" {
+		t.Errorf("baseURL = %q", b.api.baseURL)
+	}
+}
+
+func TestBitbucketServerDefaultBranch(t *testing.T) {
+	b, requests := fakeBitbucketServer(t, map[string]string{
+		"GET " + bitbucketRepoPath + "/branches/default": `{"id":"refs/heads/master","displayId":"master"}`,
+	})
+
+	branch, err := b.DefaultBranch()
+	if err != nil {
+		t.Fatalf("DefaultBranch() error = %v", err)
+	}
+	if branch != "master" {
+		t.Errorf("DefaultBranch() = %q, want %q", branch, "master")
+	}
+	if got := (*requests)[0].Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+	}
+}
+
+func TestBitbucketServerCreatePR(t *testing.T) {
+	b, requests := fakeBitbucketServer(t, map[string]string{
+		"POST " + bitbucketRepoPath + "/pull-requests":                 `{"id":31,"version":0,"state":"OPEN","title":"Title","fromRef":{"id":"refs/heads/feature","displayId":"feature"},"toRef":{"id":"refs/heads/master","displayId":"master"},"links":{"self":[{"href":"https://bitbucket.example.com/projects/PLAT/repos/service/pull-requests/31"}]}}`,
+		"POST " + bitbucketRepoPath + "/pull-requests/31/participants": `{}`,
+	})
+
+	pr, err := b.CreatePR(PRRequest{Base: "master", Head: "feature", Title: "Title", Body: "Body", Reviewers: []string{"@alice"}})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	want := &PullRequest{
+		Number: 31,
+		ID:     "31",
+		URL:    "https://bitbucket.example.com/projects/PLAT/repos/service/pull-requests/31",
+		State:  "open",
+		Base:   "master",
+		Head:   "feature",
+		Title:  "Title",
+	}
+	if !reflect.DeepEqual(pr, want) {
+		t.Errorf("CreatePR() = %+v, want %+v", pr, want)
+	}
+
+	repo := map[string]any{"slug": "service", "project": map[string]any{"key": "PLAT"}}
+	expected := map[string]any{
+		"title":       "Title",
+		"description": "Body",
+		"fromRef":     map[string]any{"id": "refs/heads/feature", "repository": repo},
+		"toRef":       map[string]any{"id": "refs/heads/master", "repository": repo},
+	}
+	if !reflect.DeepEqual((*requests)[0].Body, expected) {
+		t.Errorf("create body = %v, want %v", (*requests)[0].Body, expected)
+	}
+	reviewer := map[string]any{"user": map[string]any{"name": "alice"}, "role": "REVIEWER"}
+	if !reflect.DeepEqual((*requests)[1].Body, reviewer) {
+		t.Errorf("participant body = %v, want %v", (*requests)[1].Body, reviewer)
+	}
+}
+
+func TestBitbucketServerCreatePRUnknownReviewer(t *testing.T) {
+	b, _ := fakeBitbucketServer(t, map[string]string{
+		"POST " + bitbucketRepoPath + "/pull-requests": `{"id":31,"state":"OPEN","links":{"self":[{"href":"https://bitbucket.example.com/projects/PLAT/repos/service/pull-requests/31"}]}}`,
+	})
+
+	pr, err := b.CreatePR(PRRequest{Base: "master", Head: "feature", Title: "Title", Reviewers: []string{"nobody"}})
+	var metaErr *MetadataError
+	if pr == nil || !errors.As(err, &metaErr) {
+		t.Errorf("CreatePR() = %v, %v, want the PR with a *MetadataError", pr, err)
+	}
+}
+
+func TestBitbucketServerMetadataNotSupported(t *testing.T) {
+	b, _ := fakeBitbucketServer(t, map[string]string{})
+
+	err := CheckMetadata(b, PRRequest{Base: "master", Head: "feature", Title: "Title", Labels: []string{"standards"}, Milestone: "Q4", Reviewers: []string{"alice"}})
+	if !errors.Is(err, ErrNotSupported) || err.Error() != "labels and milestones are not supported by this forge" {
+		t.Errorf("CheckMetadata() error = %v, want labels and milestones not supported", err)
+	}
+}
+
+func TestBitbucketServerFindPR(t *testing.T) {
+	b, requests := fakeBitbucketServer(t, map[string]string{
+		"GET " + bitbucketRepoPath + "/pull-requests": `{"values":[{"id":31,"state":"OPEN","fromRef":{"displayId":"feature"},"toRef":{"displayId":"master"}}],"isLastPage":true}`,
+	})
+
+	pr, err := b.FindPR("feature")
+	if err != nil {
+		t.Fatalf("FindPR() error = %v", err)
+	}
+	if pr == nil || pr.Number != 31 || pr.Head != "feature" {
+		t.Errorf("FindPR() = %+v, want PR #31", pr)
+	}
+	if (*requests)[0].Query != "state=OPEN&direction=OUTGOING&at=refs%2Fheads%2Ffeature" {
+		t.Errorf("query = %q", (*requests)[0].Query)
+	}
+}
+
+func TestBitbucketServerRequestReviewers(t *testing.T) {
+	b, requests := fakeBitbucketServer(t, map[string]string{
+		"POST " + bitbucketRepoPath + "/pull-requests/31/participants": `{}`,
+	})
+
+	if err := b.RequestReviewers(31, []string{"alice", "bob"}); err != nil {
+		t.Fatalf("RequestReviewers() error = %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(*requests))
+	}
+	expected := map[string]any{"user": map[string]any{"name": "bob"}, "role": "REVIEWER"}
+	if !reflect.DeepEqual((*requests)[1].Body, expected) {
+		t.Errorf("participant body = %v, want %v", (*requests)[1].Body, expected)
+	}
+}
+
+func TestBitbucketServerClosePR(t *testing.T) {
+	b, requests := fakeBitbucketServer(t, map[string]string{
+		"GET " + bitbucketRepoPath + "/pull-requests/31":          `{"id":31,"version":4}`,
+		"POST " + bitbucketRepoPath + "/pull-requests/31/decline": `{}`,
+	})
+
+	if err := b.ClosePR(31); err != nil {
+		t.Fatalf("ClosePR() error = %v", err)
+	}
+	if (*requests)[1].Query != "version=4" {
+		t.Errorf("decline query = %q, want %q", (*requests)[1].Query, "version=4")
+	}
+}
@@ -0,0 +1,461 @@
+package forge
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/git"
+)
+
+// ErrNotSupported is returned by forge operations the hosting provider does not support.
+var ErrNotSupported = errors.New("not supported by this forge")
+
+// PRRequest describes a pull request to create.
+type PRRequest struct {
+	// Base is the branch the pull request merges into.
+	Base string
+	// Head is the branch containing the changes.
+	Head string
+	// Title is the pull request title.
+	Title string
+	// Body is the pull request description.
+	Body string
+	// Draft indicates whether to create the pull request as a draft.
+	Draft bool
+	// Labels are added to the pull request when it is created.
+	Labels []string
+	// Assignees are user names assigned to the pull request when it is created.
+	Assignees []string
+	// Reviewers are user names asked to review the pull request when it is created.
+	Reviewers []string
+	// Milestone is the title or number of the milestone to add the pull
+	// request to, where supported (optional).
+	Milestone string
+	// Projects are project boards to add the pull request to, where supported.
+	Projects []string
+	// WorkItems are IDs of work items to link to the pull request, where supported.
+	WorkItems []string
+	// AutoComplete asks the forge to complete the pull request once its
+	// policies pass, where supported.
+	AutoComplete bool
+	// Topic groups related changes, where supported (optional).
+	Topic string
+	// Hashtags are attached to the change, where supported (optional).
+	Hashtags []string
+	// RemoveSourceBranch asks the forge to delete the head branch once merged,
+	// where supported.
+	RemoveSourceBranch bool
+}
+
+// PullRequest describes a pull request on a forge.
+type PullRequest struct {
+	// Number is the pull request number shown to users.
+	Number int
+	// ID is the provider's internal identifier, if it differs from Number.
+	ID string
+	// URL is the web URL of the pull request.
+	URL string
+	// State is the provider's state for the pull request (e.g. open, closed, merged).
+	State string
+	// Base is the branch the pull request merges into.
+	Base string
+	// Head is the branch containing the changes.
+	Head string
+	// Title is the pull request title.
+	Title string
+	// Body is the pull request description.
+	Body string
+	// Draft indicates whether the pull request is a draft.
+	Draft bool
+}
+
+// MetadataError reports metadata, such as labels or reviewers, that could not
+// be applied to a pull request that was created. CreatePR returns it alongside
+// the created pull request so callers can report it without losing the PR.
+type MetadataError struct {
+	// URL is the web URL of the created pull request.
+	URL string
+	// Errs are the individual failures.
+	Errs []error
+}
+
+// Error returns the failures prefixed with the pull request URL.
+func (e *MetadataError) Error() string {
+	messages := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("created PR %s: %s", e.URL, strings.Join(messages, "; "))
+}
+
+// Unwrap returns the individual failures.
+func (e *MetadataError) Unwrap() []error {
+	return e.Errs
+}
+
+// metadataError returns a *MetadataError for the failures, or nil if there
+// are none.
+func metadataError(pr *PullRequest, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &MetadataError{URL: pr.URL, Errs: errs}
+}
+
+// Metadata is a kind of pull request metadata that not every forge supports.
+type Metadata string
+
+const (
+	// MetadataLabels are the request's labels.
+	MetadataLabels Metadata = "labels"
+	// MetadataAssignees are the request's assignees.
+	MetadataAssignees Metadata = "assignees"
+	// MetadataReviewers are the request's reviewers.
+	MetadataReviewers Metadata = "reviewers"
+	// MetadataTeamReviewers are the request's reviewers in org/team form.
+	MetadataTeamReviewers Metadata = "team reviewers"
+	// MetadataMilestone is the request's milestone.
+	MetadataMilestone Metadata = "milestones"
+	// MetadataProjects are the request's project boards.
+	MetadataProjects Metadata = "projects"
+	// MetadataWorkItems are the request's linked work items.
+	MetadataWorkItems Metadata = "work items"
+)
+
+// requested returns the kinds of metadata the request carries.
+func (req PRRequest) requested() []Metadata {
+	kinds := []Metadata{}
+	if len(req.Labels) > 0 {
+		kinds = append(kinds, MetadataLabels)
+	}
+	if len(req.Assignees) > 0 {
+		kinds = append(kinds, MetadataAssignees)
+	}
+	if len(req.Reviewers) > 0 {
+		kinds = append(kinds, MetadataReviewers)
+	}
+	for _, reviewer := range req.Reviewers {
+		if IsTeam(reviewer) {
+			kinds = append(kinds, MetadataTeamReviewers)
+			break
+		}
+	}
+	if req.Milestone != "" {
+		kinds = append(kinds, MetadataMilestone)
+	}
+	if len(req.Projects) > 0 {
+		kinds = append(kinds, MetadataProjects)
+	}
+	if len(req.WorkItems) > 0 {
+		kinds = append(kinds, MetadataWorkItems)
+	}
+	return kinds
+}
+
+// IsTeam reports whether a reviewer names a team, in @org/team or org/team form.
+func IsTeam(reviewer string) bool {
+	return strings.Contains(reviewer, "/")
+}
+
+// Supports reports whether the forge can apply the kind of metadata. Forges
+// that do not implement MetadataSupporter accept every kind.
+func Supports(f Forge, kind Metadata) bool {
+	supporter, ok := f.(MetadataSupporter)
+	return !ok || supporter.SupportsMetadata(kind)
+}
+
+// CheckMetadata returns an error naming the metadata in the request that the
+// forge cannot apply, so callers can refuse the request before pushing
+// anything.
+func CheckMetadata(f Forge, req PRRequest) error {
+	unsupported := []string{}
+	for _, kind := range req.requested() {
+		if !Supports(f, kind) {
+			unsupported = append(unsupported, string(kind))
+		}
+	}
+	if len(unsupported) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s are %w", strings.Join(unsupported, " and "), ErrNotSupported)
+}
+
+// Forge defines the interface for operations on the hosting provider, such as
+// pull requests. This allows additional providers to be added without
+// changing the apply logic, and allows for mocking in tests.
+type Forge interface {
+	// Name returns the name used to select the forge.
+	Name() string
+	// DefaultBranch returns the repository's default branch name.
+	DefaultBranch() (string, error)
+	// CreatePR creates a pull request. If the pull request is created but some
+	// of its metadata cannot be applied, it is returned with a *MetadataError.
+	// Kinds of metadata the forge does not support are ignored; callers check
+	// them with CheckMetadata first.
+	CreatePR(req PRRequest) (*PullRequest, error)
+	// FindPR returns the open pull request for the head branch, or nil if there is none.
+	FindPR(head string) (*PullRequest, error)
+	// UpdatePR updates the title and body of a pull request.
+	UpdatePR(number int, title, body string) error
+	// ClosePR closes a pull request without merging it.
+	ClosePR(number int) error
+	// AddLabels adds labels to a pull request.
+	AddLabels(number int, labels []string) error
+	// RequestReviewers requests reviews on a pull request.
+	RequestReviewers(number int, reviewers []string) error
+}
+
+// ChangeUploader is implemented by forges that review commits pushed to a
+// special ref instead of pull requests, such as Gerrit. Changes are uploaded
+// with a git push of UploadRefspec, replacing the branch push and CreatePR.
+type ChangeUploader interface {
+	Forge
+	// CommitMessage returns the commit message with any trailers the forge
+	// needs to track the change.
+	CommitMessage(message string, req PRRequest) string
+	// UploadRefspec returns the refspec, including push options, that uploads
+	// the head branch as a change for the request.
+	UploadRefspec(req PRRequest) string
+	// ParseUpload returns the change reported in the push output.
+	ParseUpload(output string) (*PullRequest, error)
+}
+
+// MetadataSupporter is implemented by forges that can apply only some kinds
+// of pull request metadata.
+type MetadataSupporter interface {
+	Forge
+	// SupportsMetadata reports whether CreatePR can apply the kind of metadata.
+	SupportsMetadata(kind Metadata) bool
+}
+
+// Fork describes a fork of the repository owned by the authenticated user.
+type Fork struct {
+	// Owner is the user or organization that owns the fork.
+	Owner string
+	// CloneURL is the URL to push to, using the same protocol as the upstream remote.
+	CloneURL string
+}
+
+// Forker is implemented by forges that can open pull requests from a fork.
+type Forker interface {
+	Forge
+	// EnsureFork returns the user's fork of the repository, creating it if it
+	// does not exist yet.
+	EnsureFork() (*Fork, error)
+}
+
+// MergeMethod is how a pull request's commits are merged into its base.
+type MergeMethod string
+
+const (
+	// MergeMethodMerge creates a merge commit.
+	MergeMethodMerge MergeMethod = "merge"
+	// MergeMethodSquash squashes the commits into one.
+	MergeMethodSquash MergeMethod = "squash"
+	// MergeMethodRebase rebases the commits onto the base.
+	MergeMethodRebase MergeMethod = "rebase"
+)
+
+// MergeMethods lists the supported merge methods.
+var MergeMethods = []MergeMethod{MergeMethodMerge, MergeMethodSquash, MergeMethodRebase}
+
+// ParseMergeMethod converts a string to a MergeMethod, returning an error if invalid.
+func ParseMergeMethod(s string) (MergeMethod, error) {
+	for _, method := range MergeMethods {
+		if string(method) == s {
+			return method, nil
+		}
+	}
+	return "", fmt.Errorf("invalid merge method %q, must be one of: merge, squash, rebase", s)
+}
+
+// AutoMerger is implemented by forges that can merge a pull request
+// automatically once its required checks pass.
+type AutoMerger interface {
+	Forge
+	// EnableAutoMerge turns on auto-merge for a pull request. It returns an
+	// error if the repository does not allow auto-merge.
+	EnableAutoMerge(pr *PullRequest, method MergeMethod) error
+}
+
+// CheckState is the combined state of a pull request's status checks.
+type CheckState string
+
+const (
+	// ChecksPending means some checks have not finished yet.
+	ChecksPending CheckState = "pending"
+	// ChecksPassing means every check passed, or there are no checks.
+	ChecksPassing CheckState = "passing"
+	// ChecksFailing means at least one check failed.
+	ChecksFailing CheckState = "failing"
+)
+
+// PRStatus describes whether a pull request is ready to merge.
+type PRStatus struct {
+	// Checks is the combined state of the pull request's status checks.
+	Checks CheckState
+	// Conflicts reports whether the pull request conflicts with its base.
+	Conflicts bool
+	// ReviewRequired reports whether the pull request still needs an
+	// approving review before it can be merged.
+	ReviewRequired bool
+}
+
+// Merger is implemented by forges that can report whether a pull request is
+// ready to merge and merge it.
+type Merger interface {
+	Forge
+	// PRStatus returns the checks, conflict and review state of a pull request.
+	PRStatus(pr *PullRequest) (*PRStatus, error)
+	// MergePR merges a pull request with the given method.
+	MergePR(pr *PullRequest, method MergeMethod) error
+}
+
+// ProtectionChecker is implemented by forges that can report a branch's
+// protection rules.
+type ProtectionChecker interface {
+	Forge
+	// RequiresSignedCommits reports whether commits on the branch must be
+	// signed. Protection the credentials cannot read counts as not requiring
+	// signatures.
+	RequiresSignedCommits(branch string) (bool, error)
+}
+
+// Issue describes an issue on a forge.
+type Issue struct {
+	// Number is the issue number shown to users.
+	Number int
+	// URL is the web URL of the issue.
+	URL string
+	// Title is the issue title.
+	Title string
+	// Body is the issue description.
+	Body string
+}
+
+// IssueTracker is implemented by forges that can manage issues, such as the
+// tracking issue of a campaign.
+type IssueTracker interface {
+	Forge
+	// FindIssue returns the open issue with exactly the title, or nil if there is none.
+	FindIssue(title string) (*Issue, error)
+	// CreateIssue creates an issue.
+	CreateIssue(title, body string) (*Issue, error)
+	// UpdateIssue replaces the body of an issue.
+	UpdateIssue(number int, body string) error
+}
+
+// Recorder is implemented by forges that keep their pull requests as records
+// the tool manages itself, such as the local forge.
+type Recorder interface {
+	Forge
+	// ListPRs returns every recorded pull request, ordered by number.
+	ListPRs() ([]*PullRequest, error)
+	// DeletePR removes the record of a pull request.
+	DeletePR(number int) error
+}
+
+// Options holds the settings used to construct a forge.
+type Options struct {
+	// RepoDir is the local repository directory.
+	RepoDir string
+	// Git runs git in the local repository, for forges that read it
+	// (optional, defaults to git in RepoDir).
+	Git git.Operations
+	// Remote is the git remote name the forge repository is reached through.
+	Remote string
+	// RemoteURL is the URL of the remote, used to locate the repository on the
+	// forge and to auto-select the forge.
+	RemoteURL string
+	// BaseURL overrides the forge's API base URL (optional).
+	BaseURL string
+	// Token overrides the API token read from the forge's environment variable (optional).
+	Token string
+	// Hosts maps remote host names to forge names for auto-selection.
+	Hosts map[string]string
+}
+
+// constructor creates a forge from options.
+type constructor func(opts Options) (Forge, error)
+
+// registry maps forge names to their constructors.
+var registry = map[string]constructor{
+	GitHubName: newGitHubForge,
+	GitLabName: func(opts Options) (Forge, error) {
+		return NewGitLab(opts)
+	},
+	GiteaName: func(opts Options) (Forge, error) {
+		return NewGitea(GiteaName, opts)
+	},
+	ForgejoName: func(opts Options) (Forge, error) {
+		return NewGitea(ForgejoName, opts)
+	},
+	BitbucketServerName: func(opts Options) (Forge, error) {
+		return NewBitbucketServer(opts)
+	},
+	AzureDevOpsName: func(opts Options) (Forge, error) {
+		return NewAzureDevOps(opts)
+	},
+	LocalName: func(opts Options) (Forge, error) {
+		return NewLocal(opts)
+	},
+	GerritName: func(opts Options) (Forge, error) {
+		return NewGerrit(), nil
+	},
+}
+
+// AutoName selects the forge from the remote URL.
+const AutoName = "auto"
+
+// DefaultName is the forge used when none is selected.
+const DefaultName = AutoName
+
+// Detect returns the name of the forge for the remote URL. An explicit host
+// mapping wins, then well-known host names, falling back to GitHub.
+func Detect(opts Options) string {
+	info, err := ParseRemoteURL(opts.RemoteURL)
+	if err != nil {
+		return GitHubName
+	}
+	if name, ok := opts.Hosts[info.Host]; ok {
+		return name
+	}
+	switch {
+	case strings.Contains(info.Host, "gitlab"):
+		return GitLabName
+	case isAzureHost(info.Host):
+		return AzureDevOpsName
+	case strings.Contains(info.Host, "gerrit"):
+		return GerritName
+	case strings.Contains(info.Host, "forgejo"), info.Host == "codeberg.org":
+		return ForgejoName
+	case strings.Contains(info.Host, "gitea"):
+		return GiteaName
+	}
+	return GitHubName
+}
+
+// Names returns the names of the available forges in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(registry)+1)
+	names = append(names, AutoName)
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the forge with the given name, or the detected forge for "auto".
+func New(name string, opts Options) (Forge, error) {
+	if name == AutoName {
+		name = Detect(opts)
+	}
+	create, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid forge: %q, must be one of: %s", name, strings.Join(Names(), ", "))
+	}
+	return create(opts)
+}
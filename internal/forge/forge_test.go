@@ -0,0 +1,145 @@
+package forge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	f, err := New(GitHubName, Options{RepoDir: "."})
+	if err != nil {
+		t.Fatalf("New(%q) error = %v", GitHubName, err)
+	}
+	if f.Name() != GitHubName {
+		t.Errorf("Name() = %q, want %q", f.Name(), GitHubName)
+	}
+
+	_, err = New("sourceforge", Options{})
+	if err == nil {
+		t.Fatal("New(sourceforge) expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), GitHubName) {
+		t.Errorf("New(sourceforge) error = %v, want it to list the available forges", err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{name: "github.com", opts: Options{RemoteURL: "git@github.com:owner/repo.git"}, expected: GitHubName},
+		{name: "gitlab.com", opts: Options{RemoteURL: "https://gitlab.com/group/repo.git"}, expected: GitLabName},
+		{name: "self-managed gitlab", opts: Options{RemoteURL: "git@gitlab.example.com:group/repo.git"}, expected: GitLabName},
+		{
+			name:     "host mapping",
+			opts:     Options{RemoteURL: "https://git.example.com/group/repo.git", Hosts: map[string]string{"git.example.com": GitLabName}},
+			expected: GitLabName,
+		},
+		{name: "codeberg", opts: Options{RemoteURL: "https://codeberg.org/owner/repo.git"}, expected: ForgejoName},
+		{name: "gitea host", opts: Options{RemoteURL: "git@gitea.example.com:owner/repo.git"}, expected: GiteaName},
+		{name: "azure devops", opts: Options{RemoteURL: "git@ssh.dev.azure.com:v3/org/project/repo"}, expected: AzureDevOpsName},
+		{name: "gerrit host", opts: Options{RemoteURL: "ssh://gerrit.example.com:29418/tools/repo"}, expected: GerritName},
+		{name: "unparseable remote", opts: Options{}, expected: GitHubName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.opts); got != tt.expected {
+				t.Errorf("Detect() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewAuto(t *testing.T) {
+	f, err := New(AutoName, Options{RemoteURL: "https://gitlab.com/group/repo.git"})
+	if err != nil {
+		t.Fatalf("New(%q) error = %v", AutoName, err)
+	}
+	if f.Name() != GitLabName {
+		t.Errorf("Name() = %q, want %q", f.Name(), GitLabName)
+	}
+}
+
+func TestTeamReviewers(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	tests := []struct {
+		name      string
+		opts      Options
+		supported bool
+	}{
+		{name: GitHubName, opts: Options{RemoteURL: "git@github.com:owner/repo.git", RepoDir: "."}, supported: true},
+		{name: GitHubName, opts: Options{RemoteURL: "git@github.com:owner/repo.git", Token: "secret"}, supported: true},
+		{name: ForgejoName, opts: Options{RemoteURL: "https://codeberg.org/owner/repo.git"}, supported: true},
+		{name: GitLabName, opts: Options{RemoteURL: "https://gitlab.com/group/repo.git"}},
+		{name: BitbucketServerName, opts: Options{RemoteURL: "https://bitbucket.example.com/scm/PLAT/service.git"}},
+		{name: AzureDevOpsName, opts: Options{RemoteURL: "git@ssh.dev.azure.com:v3/org/project/repo"}},
+		{name: GerritName, opts: Options{}},
+	}
+
+	req := PRRequest{Base: "main", Head: "feature", Title: "Title", Reviewers: []string{"alice", "@acme/platform"}}
+	for _, tt := range tests {
+		f, err := New(tt.name, tt.opts)
+		if err != nil {
+			t.Fatalf("New(%q) error = %v", tt.name, err)
+		}
+		if got := Supports(f, MetadataTeamReviewers); got != tt.supported {
+			t.Errorf("%T Supports(team reviewers) = %v, want %v", f, got, tt.supported)
+		}
+		err = CheckMetadata(f, req)
+		if tt.supported && err != nil {
+			t.Errorf("%T CheckMetadata() error = %v, want nil", f, err)
+		}
+		if !tt.supported && (!errors.Is(err, ErrNotSupported) || err.Error() != "team reviewers are not supported by this forge") {
+			t.Errorf("%T CheckMetadata() error = %v, want team reviewers not supported", f, err)
+		}
+	}
+}
+
+func TestMockForge(t *testing.T) {
+	mock := NewMockForge()
+
+	branch, err := mock.DefaultBranch()
+	if err != nil || branch != "main" {
+		t.Errorf("DefaultBranch() = %q, %v, want %q", branch, err, "main")
+	}
+
+	pr, err := mock.CreatePR(PRRequest{Base: "main", Head: "feature", Title: "Title", Body: "Body", Draft: true})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	if pr.URL != mock.PRURLToReturn || pr.Number != 1 || !pr.Draft {
+		t.Errorf("CreatePR() = %+v, want PR #1 with mock URL", pr)
+	}
+
+	found, err := mock.FindPR("feature")
+	if err != nil || found != pr {
+		t.Errorf("FindPR() = %v, %v, want created PR", found, err)
+	}
+
+	if err := mock.AddLabels(1, []string{"standards"}); err != nil {
+		t.Errorf("AddLabels() error = %v", err)
+	}
+	if err := mock.RequestReviewers(1, []string{"octocat"}); err != nil {
+		t.Errorf("RequestReviewers() error = %v", err)
+	}
+	if len(mock.Labels[1]) != 1 || len(mock.Reviewers[1]) != 1 {
+		t.Errorf("Labels = %v, Reviewers = %v, want one each", mock.Labels, mock.Reviewers)
+	}
+
+	if err := mock.ClosePR(1); err != nil {
+		t.Errorf("ClosePR() error = %v", err)
+	}
+	found, err = mock.FindPR("feature")
+	if err != nil || found != nil {
+		t.Errorf("FindPR() after close = %v, %v, want nil", found, err)
+	}
+}
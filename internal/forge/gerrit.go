@@ -0,0 +1,126 @@
+package forge
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GerritName is the name of the Gerrit forge.
+const GerritName = "gerrit"
+
+// Gerrit implements ChangeUploader for Gerrit Code Review. Changes are
+// uploaded by pushing the head branch to refs/for/<base>, so there are no pull requests to
+// create or manage through an API.
+type Gerrit struct{}
+
+// NewGerrit creates a new Gerrit forge.
+func NewGerrit() *Gerrit {
+	return &Gerrit{}
+}
+
+// Name returns the name used to select the forge.
+func (g *Gerrit) Name() string {
+	return GerritName
+}
+
+// DefaultBranch is not supported, so detection falls back to git.
+func (g *Gerrit) DefaultBranch() (string, error) {
+	return "", ErrNotSupported
+}
+
+// CreatePR is not supported; changes are uploaded with UploadRefspec.
+func (g *Gerrit) CreatePR(req PRRequest) (*PullRequest, error) {
+	return nil, fmt.Errorf("failed to create PR: %w", ErrNotSupported)
+}
+
+// FindPR is not supported.
+func (g *Gerrit) FindPR(head string) (*PullRequest, error) {
+	return nil, fmt.Errorf("failed to find change for %s: %w", head, ErrNotSupported)
+}
+
+// UpdatePR is not supported.
+func (g *Gerrit) UpdatePR(number int, title, body string) error {
+	return fmt.Errorf("failed to update change %d: %w", number, ErrNotSupported)
+}
+
+// ClosePR is not supported.
+func (g *Gerrit) ClosePR(number int) error {
+	return fmt.Errorf("failed to abandon change %d: %w", number, ErrNotSupported)
+}
+
+// AddLabels is not supported; use hashtags when uploading instead.
+func (g *Gerrit) AddLabels(number int, labels []string) error {
+	return fmt.Errorf("failed to add labels to change %d: %w", number, ErrNotSupported)
+}
+
+// RequestReviewers is not supported; reviewers are added when uploading instead.
+func (g *Gerrit) RequestReviewers(number int, reviewers []string) error {
+	return fmt.Errorf("failed to request reviewers on change %d: %w", number, ErrNotSupported)
+}
+
+// SupportsMetadata reports whether the kind of metadata can be uploaded.
+// User reviewers are the only kind; labels map to hashtags instead.
+func (g *Gerrit) SupportsMetadata(kind Metadata) bool {
+	return kind == MetadataReviewers
+}
+
+// CommitMessage appends a Change-Id trailer unless the message already has
+// one. The Change-Id is derived from the base and head branches, so uploading
+// the same branch again adds a patch set to the existing change.
+func (g *Gerrit) CommitMessage(message string, req PRRequest) string {
+	if changeIDRe.MatchString(message) {
+		return message
+	}
+	return strings.TrimRight(message, "\n") + "\n\nChange-Id: " + changeID(req)
+}
+
+// UploadRefspec returns <head>:refs/for/<base> with the topic, hashtags,
+// reviewers and work-in-progress state as push options.
+func (g *Gerrit) UploadRefspec(req PRRequest) string {
+	options := []string{}
+	if req.Topic != "" {
+		options = append(options, "topic="+req.Topic)
+	}
+	for _, hashtag := range req.Hashtags {
+		options = append(options, "t="+hashtag)
+	}
+	for _, reviewer := range req.Reviewers {
+		options = append(options, "r="+strings.TrimPrefix(reviewer, "@"))
+	}
+	if req.Draft {
+		options = append(options, "wip")
+	}
+
+	refspec := "refs/heads/" + req.Head + ":refs/for/" + req.Base
+	if len(options) > 0 {
+		refspec += "%" + strings.Join(options, ",")
+	}
+	return refspec
+}
+
+// changeURLRe matches the change URL Gerrit prints in its push output, e.g.
+// "remote:   https://review.example.com/c/project/+/1234 Update file [NEW]".
+var changeURLRe = regexp.MustCompile(`(?m)^remote:\s+(https?://\S+)`)
+
+// changeIDRe matches an existing Change-Id trailer.
+var changeIDRe = regexp.MustCompile(`(?m)^Change-Id: I[0-9a-f]{40}\s*$`)
+
+// ParseUpload returns the change whose URL Gerrit reported in the push output.
+func (g *Gerrit) ParseUpload(output string) (*PullRequest, error) {
+	match := changeURLRe.FindStringSubmatch(output)
+	if match == nil {
+		return nil, fmt.Errorf("no change URL in push output: %s", strings.TrimSpace(output))
+	}
+	return &PullRequest{
+		Number: prNumberFromURL(match[1]),
+		URL:    match[1],
+		State:  "open",
+	}, nil
+}
+
+// changeID returns a Change-Id that is stable for the base and head branches.
+func changeID(req PRRequest) string {
+	return fmt.Sprintf("I%x", sha1.Sum([]byte(req.Base+"\x00"+req.Head)))
+}
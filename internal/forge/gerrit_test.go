@@ -0,0 +1,81 @@
+package forge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGerritCommitMessage(t *testing.T) {
+	g := NewGerrit()
+	req := PRRequest{Base: "main", Head: "bulkfilepr/ci-yml-abc"}
+
+	message := g.CommitMessage("chore: update ci.yml\n", req)
+	want := "chore: update ci.yml\n\nChange-Id: " + changeID(req)
+	if message != want {
+		t.Errorf("CommitMessage() = %q, want %q", message, want)
+	}
+	if len(changeID(req)) != 41 || !strings.HasPrefix(changeID(req), "I") {
+		t.Errorf("changeID() = %q, want I followed by 40 hex digits", changeID(req))
+	}
+
+	// The Change-Id is stable so re-uploads become new patch sets
+	if again := g.CommitMessage("chore: update ci.yml", req); again != want {
+		t.Errorf("CommitMessage() second call = %q, want %q", again, want)
+	}
+	// An existing Change-Id is kept
+	if kept := g.CommitMessage(want, PRRequest{Base: "other", Head: "other"}); kept != want {
+		t.Errorf("CommitMessage() with Change-Id = %q, want unchanged", kept)
+	}
+}
+
+func TestGerritUploadRefspec(t *testing.T) {
+	g := NewGerrit()
+
+	refspec := g.UploadRefspec(PRRequest{Base: "main", Head: "feature"})
+	if refspec != "refs/heads/feature:refs/for/main" {
+		t.Errorf("UploadRefspec() = %q", refspec)
+	}
+
+	refspec = g.UploadRefspec(PRRequest{
+		Base:      "main",
+		Head:      "feature",
+		Topic:     "ci-rollout",
+		Hashtags:  []string{"standards", "ci"},
+		Reviewers: []string{"@alice"},
+		Draft:     true,
+	})
+	if refspec != "refs/heads/feature:refs/for/main%topic=ci-rollout,t=standards,t=ci,r=alice,wip" {
+		t.Errorf("UploadRefspec() = %q", refspec)
+	}
+}
+
+func TestGerritParseUpload(t *testing.T) {
+	g := NewGerrit()
+	output := `remote: Processing changes: new: 1, done
+remote:
+remote: SUCCESS
+remote:
+remote:   https://review.example.com/c/tools/repo/+/1234 chore: update ci.yml [NEW]
+remote:
+To ssh://review.example.com:29418/tools/repo
+ * [new reference]   feature -> refs/for/main`
+
+	change, err := g.ParseUpload(output)
+	if err != nil {
+		t.Fatalf("ParseUpload() error = %v", err)
+	}
+	if change.URL != "https://review.example.com/c/tools/repo/+/1234" || change.Number != 1234 {
+		t.Errorf("ParseUpload() = %+v, want change 1234", change)
+	}
+
+	if _, err := g.ParseUpload("Everything up-to-date"); err == nil {
+		t.Error("ParseUpload() expected error without a change URL, got nil")
+	}
+}
+
+func TestGerritCreatePRNotSupported(t *testing.T) {
+	if _, err := NewGerrit().CreatePR(PRRequest{}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("CreatePR() error = %v, want ErrNotSupported", err)
+	}
+}
@@ -0,0 +1,531 @@
+package forge
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// GiteaName is the name of the Gitea forge.
+	GiteaName = "gitea"
+	// ForgejoName is the name of the Forgejo forge, which uses the Gitea API.
+	ForgejoName = "forgejo"
+)
+
+// giteaDraftPrefix marks a pull request as work in progress, which Gitea and
+// Forgejo treat as a draft.
+const giteaDraftPrefix = "WIP: "
+
+// giteaPageSize is the number of items requested per page when listing.
+const giteaPageSize = 50
+
+// Gitea implements Forge for Gitea and Forgejo using the Gitea-compatible REST API.
+// The token is read from GITEA_TOKEN or FORGEJO_TOKEN unless set in the options.
+type Gitea struct {
+	name      string
+	api       *apiClient
+	owner     string
+	repo      string
+	remoteURL string
+	// unmergeableSince records when each PR was first seen not mergeable.
+	unmergeableSince map[int]time.Time
+	// now returns the current time and is replaceable in tests.
+	now func() time.Time
+}
+
+// giteaConflictGrace is how long a pull request may stay unmergeable before
+// it is taken to conflict with its base. Gitea reports mergeable=false both
+// while it checks a pull request against its base and when that check found
+// a conflict, so a pull request is only reported as conflicting once the
+// check has had time to finish.
+const giteaConflictGrace = time.Minute
+
+// NewGitea creates a Gitea-compatible forge with the given name for the
+// repository the remote URL points at. The API defaults to
+// https://<remote host>/api/v1.
+func NewGitea(name string, opts Options) (*Gitea, error) {
+	info, err := ParseRemoteURL(opts.RemoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate %s repository: %w", name, err)
+	}
+	owner, repo, err := info.OwnerRepo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate %s repository: %w", name, err)
+	}
+
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://%s/api/v1", info.Host)
+	}
+	token := opts.Token
+	if token == "" {
+		token = os.Getenv("GITEA_TOKEN")
+	}
+	if token == "" {
+		token = os.Getenv("FORGEJO_TOKEN")
+	}
+
+	headers := map[string]string{}
+	if token != "" {
+		headers["Authorization"] = "token " + token
+	}
+	return &Gitea{
+		name:      name,
+		api:       newAPIClient(baseURL, headers),
+		owner:     owner,
+		repo:      repo,
+		remoteURL: opts.RemoteURL,
+
+		unmergeableSince: map[int]time.Time{},
+		now:              time.Now,
+	}, nil
+}
+
+// Name returns the name used to select the forge.
+func (g *Gitea) Name() string {
+	return g.name
+}
+
+// giteaPullRequest is the subset of Gitea's pull request fields that is used.
+type giteaPullRequest struct {
+	ID      int    `json:"id"`
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	Head    struct {
+		Ref  string           `json:"ref"`
+		SHA  string           `json:"sha"`
+		Repo *giteaRepository `json:"repo"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Mergeable bool `json:"mergeable"`
+}
+
+// pullRequest converts the Gitea pull request into a PullRequest.
+func (pr *giteaPullRequest) pullRequest() *PullRequest {
+	return &PullRequest{
+		Number: pr.Number,
+		ID:     fmt.Sprint(pr.ID),
+		URL:    pr.HTMLURL,
+		State:  pr.State,
+		Base:   pr.Base.Ref,
+		Head:   pr.Head.Ref,
+		Title:  pr.Title,
+		Body:   pr.Body,
+		Draft:  strings.HasPrefix(pr.Title, giteaDraftPrefix),
+	}
+}
+
+// DefaultBranch returns the repository's default branch.
+func (g *Gitea) DefaultBranch() (string, error) {
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := g.api.do(http.MethodGet, g.repoPath(), nil, &repo); err != nil {
+		return "", fmt.Errorf("failed to get default branch: %w", err)
+	}
+	if repo.DefaultBranch == "" {
+		return "", fmt.Errorf("failed to get default branch: empty response")
+	}
+	return repo.DefaultBranch, nil
+}
+
+// SupportsMetadata reports whether the kind of metadata can be applied.
+// Projects cannot be set through the API, and there are no work items.
+func (g *Gitea) SupportsMetadata(kind Metadata) bool {
+	return kind != MetadataProjects && kind != MetadataWorkItems
+}
+
+// CreatePR creates a pull request. Drafts are created with the "WIP:" title
+// prefix. Labels, assignees, the milestone and reviewers are set once the PR
+// exists, so a label or milestone missing from the repository is returned as
+// a *MetadataError without losing the PR.
+func (g *Gitea) CreatePR(req PRRequest) (*PullRequest, error) {
+	title := req.Title
+	if req.Draft && !strings.HasPrefix(title, giteaDraftPrefix) {
+		title = giteaDraftPrefix + title
+	}
+
+	create := map[string]any{
+		"base":  req.Base,
+		"head":  req.Head,
+		"title": title,
+		"body":  req.Body,
+	}
+
+	var created giteaPullRequest
+	if err := g.api.do(http.MethodPost, g.repoPath()+"/pulls", create, &created); err != nil {
+		return nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+	pr := created.pullRequest()
+
+	var errs []error
+	if len(req.Labels) > 0 {
+		if err := g.AddLabels(pr.Number, req.Labels); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(req.Assignees) > 0 {
+		update := map[string]any{"assignees": req.Assignees}
+		if err := g.api.do(http.MethodPatch, g.pullPath(pr.Number), update, nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to add assignees to PR #%d: %w", pr.Number, err))
+		}
+	}
+	if req.Milestone != "" {
+		if err := g.setMilestone(pr.Number, req.Milestone); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(req.Reviewers) > 0 {
+		if err := g.RequestReviewers(pr.Number, req.Reviewers); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return pr, metadataError(pr, errs)
+}
+
+// setMilestone adds a pull request to a milestone given by ID or title.
+func (g *Gitea) setMilestone(number int, milestone string) error {
+	id, err := g.milestoneID(milestone)
+	if err != nil {
+		return fmt.Errorf("failed to set milestone on PR #%d: %w", number, err)
+	}
+	update := map[string]any{"milestone": id}
+	if err := g.api.do(http.MethodPatch, g.pullPath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to set milestone on PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// FindPR returns the open pull request for the head branch, or nil if there
+// is none. The head is a branch of this repository or <owner>:<branch> for a
+// fork's branch. The API has no head filter, so open pull requests are paged
+// through.
+func (g *Gitea) FindPR(head string) (*PullRequest, error) {
+	owner, ref := g.owner, head
+	if before, after, ok := strings.Cut(head, ":"); ok {
+		owner, ref = before, after
+	}
+	for page := 1; ; page++ {
+		var prs []giteaPullRequest
+		path := fmt.Sprintf("%s/pulls?state=open&page=%d&limit=%d", g.repoPath(), page, giteaPageSize)
+		if err := g.api.do(http.MethodGet, path, nil, &prs); err != nil {
+			return nil, fmt.Errorf("failed to find PR for %s: %w", head, err)
+		}
+		for i := range prs {
+			// The head repository is missing once a fork is deleted
+			headRepo := prs[i].Head.Repo
+			if prs[i].Head.Ref == ref && (headRepo == nil || strings.EqualFold(headRepo.Owner.Login, owner)) {
+				return prs[i].pullRequest(), nil
+			}
+		}
+		if len(prs) < giteaPageSize {
+			return nil, nil
+		}
+	}
+}
+
+// UpdatePR updates the title and body of a pull request.
+func (g *Gitea) UpdatePR(number int, title, body string) error {
+	update := map[string]any{"title": title, "body": body}
+	if err := g.api.do(http.MethodPatch, g.pullPath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to update PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// ClosePR closes a pull request without merging it.
+func (g *Gitea) ClosePR(number int) error {
+	update := map[string]any{"state": "closed"}
+	if err := g.api.do(http.MethodPatch, g.pullPath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to close PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// AddLabels adds labels to a pull request.
+func (g *Gitea) AddLabels(number int, labels []string) error {
+	ids, err := g.labelIDs(labels)
+	if err != nil {
+		return fmt.Errorf("failed to add labels to PR #%d: %w", number, err)
+	}
+	add := map[string]any{"labels": ids}
+	path := fmt.Sprintf("%s/issues/%d/labels", g.repoPath(), number)
+	if err := g.api.do(http.MethodPost, path, add, nil); err != nil {
+		return fmt.Errorf("failed to add labels to PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// RequestReviewers requests reviews on a pull request. Reviewers in org/team
+// form are requested as teams.
+func (g *Gitea) RequestReviewers(number int, reviewers []string) error {
+	users := []string{}
+	teams := []string{}
+	for _, reviewer := range reviewers {
+		reviewer = strings.TrimPrefix(reviewer, "@")
+		if _, team, ok := strings.Cut(reviewer, "/"); ok {
+			teams = append(teams, team)
+		} else {
+			users = append(users, reviewer)
+		}
+	}
+
+	request := map[string]any{"reviewers": users, "team_reviewers": teams}
+	if err := g.api.do(http.MethodPost, g.pullPath(number)+"/requested_reviewers", request, nil); err != nil {
+		return fmt.Errorf("failed to request reviewers on PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// EnableAutoMerge schedules a pull request to be merged once its checks succeed.
+func (g *Gitea) EnableAutoMerge(pr *PullRequest, method MergeMethod) error {
+	merge := map[string]any{"Do": string(method), "merge_when_checks_succeed": true}
+	if err := g.api.do(http.MethodPost, g.pullPath(pr.Number)+"/merge", merge, nil); err != nil {
+		return fmt.Errorf("failed to enable auto-merge on PR #%d: %w", pr.Number, err)
+	}
+	return nil
+}
+
+// PRStatus returns the checks and conflict state of a pull request. Gitea
+// does not report required approvals, so a missing approval only shows up as
+// a failed merge.
+func (g *Gitea) PRStatus(pr *PullRequest) (*PRStatus, error) {
+	var current giteaPullRequest
+	if err := g.api.do(http.MethodGet, g.pullPath(pr.Number), nil, &current); err != nil {
+		return nil, fmt.Errorf("failed to get status of PR #%d: %w", pr.Number, err)
+	}
+	var combined struct {
+		State string `json:"state"`
+	}
+	path := fmt.Sprintf("%s/commits/%s/status", g.repoPath(), url.PathEscape(current.Head.SHA))
+	if err := g.api.do(http.MethodGet, path, nil, &combined); err != nil {
+		return nil, fmt.Errorf("failed to get status of PR #%d: %w", pr.Number, err)
+	}
+
+	status := &PRStatus{Checks: ChecksPassing}
+	switch combined.State {
+	case "", "success", "warning":
+	case "failure", "error":
+		status.Checks = ChecksFailing
+	default:
+		status.Checks = ChecksPending
+	}
+	switch {
+	case current.Mergeable || current.pullRequest().Draft:
+		// Drafts are never mergeable and are not merged anyway
+		delete(g.unmergeableSince, pr.Number)
+	case g.stillUnmergeable(pr.Number):
+		status.Conflicts = true
+	case status.Checks == ChecksPassing:
+		status.Checks = ChecksPending
+	}
+	return status, nil
+}
+
+// stillUnmergeable records that a pull request is not mergeable and reports
+// whether it has been so for longer than Gitea's mergeability check takes.
+func (g *Gitea) stillUnmergeable(number int) bool {
+	since, ok := g.unmergeableSince[number]
+	if !ok {
+		since = g.now()
+		g.unmergeableSince[number] = since
+	}
+	return g.now().Sub(since) >= giteaConflictGrace
+}
+
+// MergePR merges a pull request with the given method.
+func (g *Gitea) MergePR(pr *PullRequest, method MergeMethod) error {
+	merge := map[string]any{"Do": string(method)}
+	if err := g.api.do(http.MethodPost, g.pullPath(pr.Number)+"/merge", merge, nil); err != nil {
+		return fmt.Errorf("failed to merge PR #%d: %w", pr.Number, err)
+	}
+	return nil
+}
+
+// RequiresSignedCommits reports whether a branch protection rule matching
+// the branch requires signed commits. Reading the rules needs admin access.
+func (g *Gitea) RequiresSignedCommits(branch string) (bool, error) {
+	var protections []struct {
+		RuleName             string `json:"rule_name"`
+		BranchName           string `json:"branch_name"`
+		RequireSignedCommits bool   `json:"require_signed_commits"`
+	}
+	err := g.api.do(http.MethodGet, g.repoPath()+"/branch_protections", nil, &protections)
+	if isStatus(err, http.StatusForbidden, http.StatusNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read branch protections: %w", err)
+	}
+	for _, protection := range protections {
+		// Rule names are branch names or, on newer versions, glob patterns
+		rule := protection.RuleName
+		if rule == "" {
+			rule = protection.BranchName
+		}
+		if matched, _ := path.Match(rule, branch); (matched || rule == branch) && protection.RequireSignedCommits {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// giteaIssue is the subset of Gitea's issue fields that is used.
+type giteaIssue struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+}
+
+// issue converts the Gitea issue into an Issue.
+func (i *giteaIssue) issue() *Issue {
+	return &Issue{Number: i.Number, URL: i.HTMLURL, Title: i.Title, Body: i.Body}
+}
+
+// FindIssue returns the open issue with exactly the title, or nil if there is none.
+func (g *Gitea) FindIssue(title string) (*Issue, error) {
+	for page := 1; ; page++ {
+		var issues []giteaIssue
+		path := fmt.Sprintf("%s/issues?state=open&type=issues&page=%d&limit=%d", g.repoPath(), page, giteaPageSize)
+		if err := g.api.do(http.MethodGet, path, nil, &issues); err != nil {
+			return nil, fmt.Errorf("failed to find issue %q: %w", title, err)
+		}
+		for i := range issues {
+			if issues[i].Title == title {
+				return issues[i].issue(), nil
+			}
+		}
+		if len(issues) < giteaPageSize {
+			return nil, nil
+		}
+	}
+}
+
+// CreateIssue creates an issue.
+func (g *Gitea) CreateIssue(title, body string) (*Issue, error) {
+	create := map[string]any{"title": title, "body": body}
+	var created giteaIssue
+	if err := g.api.do(http.MethodPost, g.repoPath()+"/issues", create, &created); err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	return created.issue(), nil
+}
+
+// UpdateIssue replaces the body of an issue.
+func (g *Gitea) UpdateIssue(number int, body string) error {
+	update := map[string]any{"body": body}
+	if err := g.api.do(http.MethodPatch, fmt.Sprintf("%s/issues/%d", g.repoPath(), number), update, nil); err != nil {
+		return fmt.Errorf("failed to update issue #%d: %w", number, err)
+	}
+	return nil
+}
+
+// giteaRepository is the subset of Gitea's repository fields used for forks.
+type giteaRepository struct {
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	CloneURL string `json:"clone_url"`
+	SSHURL   string `json:"ssh_url"`
+}
+
+// EnsureFork returns the user's fork of the repository, creating it if needed.
+func (g *Gitea) EnsureFork() (*Fork, error) {
+	var fork giteaRepository
+	err := g.api.do(http.MethodPost, g.repoPath()+"/forks", map[string]any{}, &fork)
+
+	// Gitea answers 409 Conflict when the fork already exists, so look it up
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+		var user struct {
+			Login string `json:"login"`
+		}
+		if err := g.api.do(http.MethodGet, "/user", nil, &user); err != nil {
+			return nil, fmt.Errorf("failed to find fork of %s/%s: %w", g.owner, g.repo, err)
+		}
+		path := fmt.Sprintf("/repos/%s/%s", url.PathEscape(user.Login), url.PathEscape(g.repo))
+		err = g.api.do(http.MethodGet, path, nil, &fork)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fork %s/%s: %w", g.owner, g.repo, err)
+	}
+	return &Fork{
+		Owner:    fork.Owner.Login,
+		CloneURL: cloneURLFor(g.remoteURL, fork.CloneURL, fork.SSHURL),
+	}, nil
+}
+
+// labelIDs resolves label names to the repository's label IDs.
+func (g *Gitea) labelIDs(names []string) ([]int, error) {
+	available := map[string]int{}
+	for page := 1; ; page++ {
+		var labels []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}
+		path := fmt.Sprintf("%s/labels?page=%d&limit=%d", g.repoPath(), page, giteaPageSize)
+		if err := g.api.do(http.MethodGet, path, nil, &labels); err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+		for _, label := range labels {
+			available[label.Name] = label.ID
+		}
+		if len(labels) < giteaPageSize {
+			break
+		}
+	}
+
+	ids := make([]int, 0, len(names))
+	for _, name := range names {
+		id, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("label %q does not exist in %s/%s", name, g.owner, g.repo)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// milestoneID resolves a milestone ID or the title of an open milestone.
+func (g *Gitea) milestoneID(milestone string) (int, error) {
+	if id, err := strconv.Atoi(milestone); err == nil {
+		return id, nil
+	}
+	var milestones []struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	}
+	path := fmt.Sprintf("%s/milestones?state=open&name=%s", g.repoPath(), url.QueryEscape(milestone))
+	if err := g.api.do(http.MethodGet, path, nil, &milestones); err != nil {
+		return 0, fmt.Errorf("failed to look up milestone %s: %w", milestone, err)
+	}
+	for _, m := range milestones {
+		if m.Title == milestone {
+			return m.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("milestone %q does not exist in %s/%s", milestone, g.owner, g.repo)
+}
+
+// repoPath returns the API path of the repository.
+func (g *Gitea) repoPath() string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(g.owner), url.PathEscape(g.repo))
+}
+
+// pullPath returns the API path of a pull request.
+func (g *Gitea) pullPath(number int) string {
+	return fmt.Sprintf("%s/pulls/%d", g.repoPath(), number)
+}
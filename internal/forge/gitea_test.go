@@ -0,0 +1,372 @@
+package forge
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeGitea starts a fake API server and a Forgejo forge pointed at it.
+func fakeGitea(t *testing.T, responses map[string]string) (*Gitea, *[]apiRequest) {
+	t.Helper()
+	server, requests := fakeAPI(t, responses)
+	g, err := NewGitea(ForgejoName, Options{
+		RemoteURL: "https://forgejo.example.com/tools/repo.git",
+		BaseURL:   server.URL + "/api/v1",
+		Token:     "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewGitea() error = %v", err)
+	}
+	return g, requests
+}
+
+func TestGiteaDefaultBranch(t *testing.T) {
+	g, requests := fakeGitea(t, map[string]string{
+		"GET /api/v1/repos/tools/repo": `{"default_branch":"develop"}`,
+	})
+
+	branch, err := g.DefaultBranch()
+	if err != nil {
+		t.Fatalf("DefaultBranch() error = %v", err)
+	}
+	if branch != "develop" {
+		t.Errorf("DefaultBranch() = %q, want %q", branch, "develop")
+	}
+	if got := (*requests)[0].Header.Get("Authorization"); got != "token secret" {
+		t.Errorf("Authorization = %q, want %q", got, "token secret")
+	}
+	if g.Name() != ForgejoName {
+		t.Errorf("Name() = %q, want %q", g.Name(), ForgejoName)
+	}
+}
+
+func TestGiteaCreatePR(t *testing.T) {
+	g, requests := fakeGitea(t, map[string]string{
+		"GET /api/v1/repos/tools/repo/labels":           `[{"id":3,"name":"chore"},{"id":8,"name":"standards"}]`,
+		"POST /api/v1/repos/tools/repo/pulls":           `{"id":501,"number":9,"html_url":"https://forgejo.example.com/tools/repo/pulls/9","state":"open","title":"WIP: Title","head":{"ref":"feature"},"base":{"ref":"main"}}`,
+		"POST /api/v1/repos/tools/repo/issues/9/labels": `[]`,
+	})
+
+	pr, err := g.CreatePR(PRRequest{
+		Base:   "main",
+		Head:   "feature",
+		Title:  "Title",
+		Body:   "Body",
+		Draft:  true,
+		Labels: []string{"standards"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	want := &PullRequest{
+		Number: 9,
+		ID:     "501",
+		URL:    "https://forgejo.example.com/tools/repo/pulls/9",
+		State:  "open",
+		Base:   "main",
+		Head:   "feature",
+		Title:  "WIP: Title",
+		Draft:  true,
+	}
+	if !reflect.DeepEqual(pr, want) {
+		t.Errorf("CreatePR() = %+v, want %+v", pr, want)
+	}
+
+	expected := map[string]any{"base": "main", "head": "feature", "title": "WIP: Title", "body": "Body"}
+	if !reflect.DeepEqual((*requests)[0].Body, expected) {
+		t.Errorf("create body = %v, want %v", (*requests)[0].Body, expected)
+	}
+	if got := (*requests)[2].Body["labels"]; !reflect.DeepEqual(got, []any{float64(8)}) {
+		t.Errorf("labels = %v, want [8]", got)
+	}
+}
+
+func TestGiteaCreatePRUnknownLabel(t *testing.T) {
+	g, _ := fakeGitea(t, map[string]string{
+		"GET /api/v1/repos/tools/repo/labels": `[{"id":3,"name":"chore"}]`,
+		"POST /api/v1/repos/tools/repo/pulls": `{"id":501,"number":9,"html_url":"https://forgejo.example.com/tools/repo/pulls/9","state":"open"}`,
+	})
+
+	pr, err := g.CreatePR(PRRequest{Base: "main", Head: "feature", Title: "Title", Labels: []string{"standards"}})
+	var metaErr *MetadataError
+	if pr == nil || !errors.As(err, &metaErr) || !strings.Contains(err.Error(), `label "standards" does not exist`) {
+		t.Errorf("CreatePR() = %v, %v, want the PR with a *MetadataError", pr, err)
+	}
+}
+
+func TestGiteaFindPR(t *testing.T) {
+	g, _ := fakeGitea(t, map[string]string{
+		"GET /api/v1/repos/tools/repo/pulls": `[
+			{"number":4,"state":"open","title":"Other","head":{"ref":"other"},"base":{"ref":"main"}},
+			{"number":9,"state":"open","title":"Title","head":{"ref":"feature"},"base":{"ref":"main"}}
+		]`,
+	})
+
+	pr, err := g.FindPR("feature")
+	if err != nil {
+		t.Fatalf("FindPR() error = %v", err)
+	}
+	if pr == nil || pr.Number != 9 {
+		t.Errorf("FindPR() = %+v, want PR #9", pr)
+	}
+
+	pr, err = g.FindPR("missing")
+	if err != nil || pr != nil {
+		t.Errorf("FindPR(missing) = %+v, %v, want nil", pr, err)
+	}
+}
+
+func TestGiteaFindPRFromFork(t *testing.T) {
+	g, _ := fakeGitea(t, map[string]string{
+		"GET /api/v1/repos/tools/repo/pulls": `[
+			{"number":4,"state":"open","head":{"ref":"feature","repo":{"owner":{"login":"tools"}}},"base":{"ref":"main"}},
+			{"number":9,"state":"open","head":{"ref":"feature","repo":{"owner":{"login":"me"}}},"base":{"ref":"main"}}
+		]`,
+	})
+
+	tests := []struct {
+		head string
+		want int
+	}{
+		{head: "me:feature", want: 9},
+		{head: "feature", want: 4},
+		{head: "tools:feature", want: 4},
+	}
+	for _, tt := range tests {
+		pr, err := g.FindPR(tt.head)
+		if err != nil || pr == nil || pr.Number != tt.want {
+			t.Errorf("FindPR(%q) = %+v, %v, want PR #%d", tt.head, pr, err, tt.want)
+		}
+	}
+
+	if pr, err := g.FindPR("other:feature"); err != nil || pr != nil {
+		t.Errorf("FindPR(other:feature) = %+v, %v, want nil", pr, err)
+	}
+}
+
+func TestGiteaEnsureForkExisting(t *testing.T) {
+	// Gitea answers 409 when the fork already exists
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v1/repos/tools/repo/forks":
+			http.Error(w, `{"message":"repository is already forked"}`, http.StatusConflict)
+		case "GET /api/v1/user":
+			_, _ = w.Write([]byte(`{"login":"me"}`))
+		case "GET /api/v1/repos/me/repo":
+			_, _ = w.Write([]byte(`{"owner":{"login":"me"},"clone_url":"https://forgejo.example.com/me/repo.git","ssh_url":"git@forgejo.example.com:me/repo.git"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	g, err := NewGitea(ForgejoName, Options{
+		RemoteURL: "https://forgejo.example.com/tools/repo.git",
+		BaseURL:   server.URL + "/api/v1",
+	})
+	if err != nil {
+		t.Fatalf("NewGitea() error = %v", err)
+	}
+
+	fork, err := g.EnsureFork()
+	if err != nil {
+		t.Fatalf("EnsureFork() error = %v", err)
+	}
+	want := &Fork{Owner: "me", CloneURL: "https://forgejo.example.com/me/repo.git"}
+	if !reflect.DeepEqual(fork, want) {
+		t.Errorf("EnsureFork() = %+v, want %+v", fork, want)
+	}
+}
+
+func TestGiteaCreatePRMilestone(t *testing.T) {
+	g, requests := fakeGitea(t, map[string]string{
+		"GET /api/v1/repos/tools/repo/milestones": `[{"id":14,"title":"v2.0"}]`,
+		"POST /api/v1/repos/tools/repo/pulls":     `{"id":501,"number":9,"html_url":"https://forgejo.example.com/tools/repo/pulls/9","state":"open"}`,
+		"PATCH /api/v1/repos/tools/repo/pulls/9":  `{"number":9}`,
+	})
+
+	if _, err := g.CreatePR(PRRequest{Base: "main", Head: "feature", Title: "Title", Milestone: "v2.0"}); err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	if got := (*requests)[1].Query; got != "state=open&name=v2.0" {
+		t.Errorf("milestone query = %q", got)
+	}
+	if got := (*requests)[2].Body["milestone"]; got != float64(14) {
+		t.Errorf("milestone = %v, want 14", got)
+	}
+}
+
+func TestGiteaRequestTeamReviewers(t *testing.T) {
+	g, requests := fakeGitea(t, map[string]string{
+		"POST /api/v1/repos/tools/repo/pulls/9/requested_reviewers": `[]`,
+	})
+
+	if err := g.RequestReviewers(9, []string{"alice", "@tools/platform"}); err != nil {
+		t.Fatalf("RequestReviewers() error = %v", err)
+	}
+	want := map[string]any{"reviewers": []any{"alice"}, "team_reviewers": []any{"platform"}}
+	if got := (*requests)[0].Body; !reflect.DeepEqual(got, want) {
+		t.Errorf("body = %v, want %v", got, want)
+	}
+}
+
+func TestGiteaEnableAutoMerge(t *testing.T) {
+	g, requests := fakeGitea(t, map[string]string{
+		"POST /api/v1/repos/tools/repo/pulls/9/merge": ``,
+	})
+
+	if err := g.EnableAutoMerge(&PullRequest{Number: 9}, MergeMethodRebase); err != nil {
+		t.Fatalf("EnableAutoMerge() error = %v", err)
+	}
+	want := map[string]any{"Do": "rebase", "merge_when_checks_succeed": true}
+	if got := (*requests)[0].Body; !reflect.DeepEqual(got, want) {
+		t.Errorf("body = %v, want %v", got, want)
+	}
+}
+
+func TestGiteaPRStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		pull     string
+		combined string
+		want     PRStatus
+	}{
+		{
+			name:     "ready",
+			pull:     `{"number":9,"mergeable":true,"head":{"ref":"feature","sha":"abc123"}}`,
+			combined: `{"state":"success"}`,
+			want:     PRStatus{Checks: ChecksPassing},
+		},
+		{
+			name:     "pending",
+			pull:     `{"number":9,"mergeable":true,"head":{"ref":"feature","sha":"abc123"}}`,
+			combined: `{"state":"pending"}`,
+			want:     PRStatus{Checks: ChecksPending},
+		},
+		{
+			name:     "failing",
+			pull:     `{"number":9,"mergeable":true,"head":{"ref":"feature","sha":"abc123"}}`,
+			combined: `{"state":"failure"}`,
+			want:     PRStatus{Checks: ChecksFailing},
+		},
+		{
+			name:     "checking mergeability",
+			pull:     `{"number":9,"mergeable":false,"head":{"ref":"feature","sha":"abc123"}}`,
+			combined: `{"state":"success"}`,
+			want:     PRStatus{Checks: ChecksPending},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := fakeGitea(t, map[string]string{
+				"GET /api/v1/repos/tools/repo/pulls/9":               tt.pull,
+				"GET /api/v1/repos/tools/repo/commits/abc123/status": tt.combined,
+			})
+
+			status, err := g.PRStatus(&PullRequest{Number: 9})
+			if err != nil {
+				t.Fatalf("PRStatus() error = %v", err)
+			}
+			if *status != tt.want {
+				t.Errorf("PRStatus() = %+v, want %+v", *status, tt.want)
+			}
+		})
+	}
+}
+
+func TestGiteaPRStatusConflict(t *testing.T) {
+	g, _ := fakeGitea(t, map[string]string{
+		"GET /api/v1/repos/tools/repo/pulls/9":               `{"number":9,"mergeable":false,"head":{"ref":"feature","sha":"abc123"}}`,
+		"GET /api/v1/repos/tools/repo/commits/abc123/status": `{"state":"success"}`,
+	})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return now }
+
+	// Unmergeable is first taken to mean the check is still running
+	for _, elapsed := range []time.Duration{0, 30 * time.Second} {
+		now = now.Add(elapsed)
+		status, err := g.PRStatus(&PullRequest{Number: 9})
+		if err != nil {
+			t.Fatalf("PRStatus() error = %v", err)
+		}
+		if status.Conflicts || status.Checks != ChecksPending {
+			t.Errorf("PRStatus() after %s = %+v, want pending without conflicts", elapsed, *status)
+		}
+	}
+
+	now = now.Add(giteaConflictGrace)
+	status, err := g.PRStatus(&PullRequest{Number: 9})
+	if err != nil {
+		t.Fatalf("PRStatus() error = %v", err)
+	}
+	if !status.Conflicts || status.Checks != ChecksPassing {
+		t.Errorf("PRStatus() after the grace period = %+v, want conflicts", *status)
+	}
+}
+
+func TestGiteaMergePR(t *testing.T) {
+	g, requests := fakeGitea(t, map[string]string{
+		"POST /api/v1/repos/tools/repo/pulls/9/merge": ``,
+	})
+
+	if err := g.MergePR(&PullRequest{Number: 9}, MergeMethodSquash); err != nil {
+		t.Fatalf("MergePR() error = %v", err)
+	}
+	want := map[string]any{"Do": "squash"}
+	if got := (*requests)[0].Body; !reflect.DeepEqual(got, want) {
+		t.Errorf("body = %v, want %v", got, want)
+	}
+}
+
+func TestGiteaCreateIssue(t *testing.T) {
+	g, requests := fakeGitea(t, map[string]string{
+		"GET /api/v1/repos/tools/repo/issues":  `[]`,
+		"POST /api/v1/repos/tools/repo/issues": `{"number":8,"html_url":"https://gitea.example.com/tools/repo/issues/8","title":"Rollout","body":"Body"}`,
+	})
+
+	issue, err := g.FindIssue("Rollout")
+	if err != nil || issue != nil {
+		t.Fatalf("FindIssue() = %+v, %v, want nil", issue, err)
+	}
+	if got := (*requests)[0].Query; got != "state=open&type=issues&page=1&limit=50" {
+		t.Errorf("query = %q", got)
+	}
+
+	issue, err = g.CreateIssue("Rollout", "Body")
+	if err != nil {
+		t.Fatalf("CreateIssue() error = %v", err)
+	}
+	if issue.Number != 8 || issue.URL != "https://gitea.example.com/tools/repo/issues/8" {
+		t.Errorf("CreateIssue() = %+v", issue)
+	}
+}
+
+func TestGiteaRequiresSignedCommits(t *testing.T) {
+	g, _ := fakeGitea(t, map[string]string{
+		"GET /api/v1/repos/tools/repo/branch_protections": `[
+			{"rule_name":"main","branch_name":"main","require_signed_commits":false},
+			{"rule_name":"release/*","branch_name":"","require_signed_commits":true},
+			{"rule_name":"","branch_name":"stable","require_signed_commits":true}
+		]`,
+	})
+
+	tests := map[string]bool{
+		"main":        false,
+		"release/1.x": true,
+		"stable":      true,
+		"feature":     false,
+	}
+	for branch, want := range tests {
+		got, err := g.RequiresSignedCommits(branch)
+		if err != nil {
+			t.Fatalf("RequiresSignedCommits(%q) error = %v", branch, err)
+		}
+		if got != want {
+			t.Errorf("RequiresSignedCommits(%q) = %v, want %v", branch, got, want)
+		}
+	}
+}
@@ -0,0 +1,568 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GitHubAPIURL is the REST API base URL for github.com.
+const GitHubAPIURL = "https://api.github.com"
+
+// githubPageSize is the number of items requested per page when listing.
+const githubPageSize = 100
+
+// GitHub implements Forge for GitHub using the REST API.
+type GitHub struct {
+	api       *apiClient
+	graphql   *apiClient
+	owner     string
+	repo      string
+	remoteURL string
+}
+
+// githubToken returns the token from the options, GITHUB_TOKEN or GH_TOKEN.
+func githubToken(opts Options) string {
+	if opts.Token != "" {
+		return opts.Token
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token
+	}
+	return os.Getenv("GH_TOKEN")
+}
+
+// newGitHubForge creates the GitHub forge: the REST API client when a token
+// is available, otherwise the GitHub CLI, which manages its own login.
+func newGitHubForge(opts Options) (Forge, error) {
+	if githubToken(opts) == "" {
+		g := NewGitHubCLI(opts.RepoDir)
+		g.RemoteURL = opts.RemoteURL
+		return g, nil
+	}
+	return NewGitHub(opts)
+}
+
+// NewGitHub creates a GitHub API client for the repository the remote URL
+// points at. The API defaults to api.github.com for github.com remotes and to
+// https://<remote host>/api/v3 for GitHub Enterprise Server.
+func NewGitHub(opts Options) (*GitHub, error) {
+	info, err := ParseRemoteURL(opts.RemoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate GitHub repository: %w", err)
+	}
+	owner, repo, err := info.OwnerRepo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate GitHub repository: %w", err)
+	}
+
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = GitHubAPIURL
+		if info.Host != "github.com" {
+			baseURL = fmt.Sprintf("https://%s/api/v3", info.Host)
+		}
+	}
+
+	headers := map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"X-GitHub-Api-Version": "2022-11-28",
+	}
+	if token := githubToken(opts); token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	return &GitHub{
+		api:       newAPIClient(baseURL, headers),
+		graphql:   newAPIClient(graphQLBaseURL(baseURL), headers),
+		owner:     owner,
+		repo:      repo,
+		remoteURL: opts.RemoteURL,
+	}, nil
+}
+
+// graphQLBaseURL returns the base URL of the GraphQL endpoint that belongs to
+// a REST API base URL. GitHub Enterprise Server serves it from /api/graphql
+// instead of /api/v3/graphql.
+func graphQLBaseURL(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return strings.TrimSuffix(baseURL, "/v3")
+}
+
+// Name returns the name used to select the forge.
+func (g *GitHub) Name() string {
+	return GitHubName
+}
+
+// githubPullRequest is the subset of GitHub's pull request fields that is used.
+type githubPullRequest struct {
+	Number  int    `json:"number"`
+	NodeID  string `json:"node_id"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	Draft   bool   `json:"draft"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// pullRequest converts the GitHub pull request into a PullRequest.
+func (pr *githubPullRequest) pullRequest() *PullRequest {
+	return &PullRequest{
+		Number: pr.Number,
+		ID:     pr.NodeID,
+		URL:    pr.HTMLURL,
+		State:  pr.State,
+		Base:   pr.Base.Ref,
+		Head:   pr.Head.Ref,
+		Title:  pr.Title,
+		Body:   pr.Body,
+		Draft:  pr.Draft,
+	}
+}
+
+// DefaultBranch returns the repository's default branch.
+func (g *GitHub) DefaultBranch() (string, error) {
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := g.api.do(http.MethodGet, g.repoPath(), nil, &repo); err != nil {
+		return "", fmt.Errorf("failed to get default branch: %w", err)
+	}
+	if repo.DefaultBranch == "" {
+		return "", fmt.Errorf("failed to get default branch: empty response")
+	}
+	return repo.DefaultBranch, nil
+}
+
+// SupportsMetadata reports whether the kind of metadata can be applied.
+// GitHub has no work items.
+func (g *GitHub) SupportsMetadata(kind Metadata) bool {
+	return kind != MetadataWorkItems
+}
+
+// CreatePR creates a pull request. Labels, assignees and reviewers are added with
+// follow-up calls since the create endpoint does not accept them; if those
+// fail, the error names the PR that was created.
+func (g *GitHub) CreatePR(req PRRequest) (*PullRequest, error) {
+	create := map[string]any{
+		"base":  req.Base,
+		"head":  req.Head,
+		"title": req.Title,
+		"body":  req.Body,
+		"draft": req.Draft,
+	}
+	var created githubPullRequest
+	if err := g.api.do(http.MethodPost, g.repoPath()+"/pulls", create, &created); err != nil {
+		return nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+	pr := created.pullRequest()
+
+	// Metadata is applied after creation; each failure is reported without
+	// stopping the others
+	var errs []error
+	if len(req.Labels) > 0 {
+		if err := g.AddLabels(pr.Number, req.Labels); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(req.Assignees) > 0 {
+		assignees := map[string]any{"assignees": req.Assignees}
+		if err := g.api.do(http.MethodPost, g.issuePath(pr.Number)+"/assignees", assignees, nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to add assignees: %w", err))
+		}
+	}
+	if len(req.Reviewers) > 0 {
+		if err := g.RequestReviewers(pr.Number, req.Reviewers); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if req.Milestone != "" {
+		if err := g.setMilestone(pr.Number, req.Milestone); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, project := range req.Projects {
+		if err := g.addToProject(pr.ID, project); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return pr, metadataError(pr, errs)
+}
+
+// FindPR returns the open pull request for the head branch, or nil if there is none.
+func (g *GitHub) FindPR(head string) (*PullRequest, error) {
+	if !strings.Contains(head, ":") {
+		head = g.owner + ":" + head
+	}
+	var prs []githubPullRequest
+	path := g.repoPath() + "/pulls?state=open&head=" + url.QueryEscape(head)
+	if err := g.api.do(http.MethodGet, path, nil, &prs); err != nil {
+		return nil, fmt.Errorf("failed to find PR for %s: %w", head, err)
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	return prs[0].pullRequest(), nil
+}
+
+// UpdatePR updates the title and body of a pull request.
+func (g *GitHub) UpdatePR(number int, title, body string) error {
+	update := map[string]any{"title": title, "body": body}
+	if err := g.api.do(http.MethodPatch, g.pullPath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to update PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// ClosePR closes a pull request without merging it.
+func (g *GitHub) ClosePR(number int) error {
+	update := map[string]any{"state": "closed"}
+	if err := g.api.do(http.MethodPatch, g.pullPath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to close PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// AddLabels adds labels to a pull request.
+func (g *GitHub) AddLabels(number int, labels []string) error {
+	add := map[string]any{"labels": labels}
+	if err := g.api.do(http.MethodPost, g.issuePath(number)+"/labels", add, nil); err != nil {
+		return fmt.Errorf("failed to add labels to PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// RequestReviewers requests reviews on a pull request. Reviewers in org/team
+// form are requested as teams.
+func (g *GitHub) RequestReviewers(number int, reviewers []string) error {
+	users := []string{}
+	teams := []string{}
+	for _, reviewer := range reviewers {
+		reviewer = strings.TrimPrefix(reviewer, "@")
+		if _, team, ok := strings.Cut(reviewer, "/"); ok {
+			teams = append(teams, team)
+		} else {
+			users = append(users, reviewer)
+		}
+	}
+
+	request := map[string]any{"reviewers": users, "team_reviewers": teams}
+	if err := g.api.do(http.MethodPost, g.pullPath(number)+"/requested_reviewers", request, nil); err != nil {
+		return fmt.Errorf("failed to request reviewers on PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// setMilestone adds a pull request to a milestone given by number or title.
+func (g *GitHub) setMilestone(number int, milestone string) error {
+	id, err := g.milestoneNumber(milestone)
+	if err != nil {
+		return fmt.Errorf("failed to set milestone on PR #%d: %w", number, err)
+	}
+	update := map[string]any{"milestone": id}
+	if err := g.api.do(http.MethodPatch, g.issuePath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to set milestone on PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// milestoneNumber resolves a milestone number or the title of an open milestone.
+func (g *GitHub) milestoneNumber(milestone string) (int, error) {
+	if number, err := strconv.Atoi(milestone); err == nil {
+		return number, nil
+	}
+	var milestones []struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+	}
+	if err := g.api.do(http.MethodGet, g.repoPath()+"/milestones?state=open&per_page=100", nil, &milestones); err != nil {
+		return 0, err
+	}
+	for _, m := range milestones {
+		if m.Title == milestone {
+			return m.Number, nil
+		}
+	}
+	return 0, fmt.Errorf("milestone %q not found", milestone)
+}
+
+// addToProject adds a pull request, by GraphQL node ID, to a project board
+// given as <number> (owned by the repository owner) or <owner>/<number>.
+func (g *GitHub) addToProject(nodeID, project string) error {
+	owner, numberText := g.owner, project
+	if before, after, ok := strings.Cut(project, "/"); ok {
+		owner, numberText = before, after
+	}
+	number, err := strconv.Atoi(numberText)
+	if err != nil {
+		return fmt.Errorf("invalid project %q: want <number> or <owner>/<number>", project)
+	}
+
+	var found struct {
+		RepositoryOwner *struct {
+			ProjectV2 *struct {
+				ID string `json:"id"`
+			} `json:"projectV2"`
+		} `json:"repositoryOwner"`
+	}
+	query := `query($owner: String!, $number: Int!) {
+  repositoryOwner(login: $owner) { ... on ProjectV2Owner { projectV2(number: $number) { id } } }
+}`
+	variables := map[string]any{"owner": owner, "number": number}
+	if err := g.query(query, variables, &found); err != nil {
+		return fmt.Errorf("failed to find project %s: %w", project, err)
+	}
+	if found.RepositoryOwner == nil || found.RepositoryOwner.ProjectV2 == nil {
+		return fmt.Errorf("failed to find project %s: not found", project)
+	}
+
+	mutation := `mutation($project: ID!, $content: ID!) {
+  addProjectV2ItemById(input: {projectId: $project, contentId: $content}) { item { id } }
+}`
+	variables = map[string]any{"project": found.RepositoryOwner.ProjectV2.ID, "content": nodeID}
+	if err := g.query(mutation, variables, nil); err != nil {
+		return fmt.Errorf("failed to add PR to project %s: %w", project, err)
+	}
+	return nil
+}
+
+// query runs a GraphQL query and decodes its data into out (if non-nil).
+func (g *GitHub) query(query string, variables map[string]any, out any) error {
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	request := map[string]any{"query": query, "variables": variables}
+	if err := g.graphql.do(http.MethodPost, "/graphql", request, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("GraphQL error: %s", resp.Errors[0].Message)
+	}
+	if out != nil {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return fmt.Errorf("failed to decode GraphQL response: %w", err)
+		}
+	}
+	return nil
+}
+
+// EnableAutoMerge turns on auto-merge for a pull request. It fails if the
+// repository does not allow auto-merge, or if the pull request can already be
+// merged because no required checks are pending.
+func (g *GitHub) EnableAutoMerge(pr *PullRequest, method MergeMethod) error {
+	mutation := `mutation($pr: ID!, $method: PullRequestMergeMethod!) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $pr, mergeMethod: $method}) { clientMutationId }
+}`
+	variables := map[string]any{"pr": pr.ID, "method": strings.ToUpper(string(method))}
+	if err := g.query(mutation, variables, nil); err != nil {
+		return fmt.Errorf("failed to enable auto-merge on PR #%d: %w", pr.Number, err)
+	}
+	return nil
+}
+
+// PRStatus returns the checks, conflict and review state of a pull request.
+// GitHub computes mergeability in the background, so while it is unknown the
+// checks are reported as pending.
+func (g *GitHub) PRStatus(pr *PullRequest) (*PRStatus, error) {
+	var found struct {
+		Repository struct {
+			PullRequest struct {
+				Mergeable      string `json:"mergeable"`
+				ReviewDecision string `json:"reviewDecision"`
+				Commits        struct {
+					Nodes []struct {
+						Commit struct {
+							StatusCheckRollup *struct {
+								State string `json:"state"`
+							} `json:"statusCheckRollup"`
+						} `json:"commit"`
+					} `json:"nodes"`
+				} `json:"commits"`
+			} `json:"pullRequest"`
+		} `json:"repository"`
+	}
+	query := `query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      mergeable
+      reviewDecision
+      commits(last: 1) { nodes { commit { statusCheckRollup { state } } } }
+    }
+  }
+}`
+	variables := map[string]any{"owner": g.owner, "repo": g.repo, "number": pr.Number}
+	if err := g.query(query, variables, &found); err != nil {
+		return nil, fmt.Errorf("failed to get status of PR #%d: %w", pr.Number, err)
+	}
+
+	result := found.Repository.PullRequest
+	status := &PRStatus{
+		Checks:         ChecksPassing,
+		Conflicts:      result.Mergeable == "CONFLICTING",
+		ReviewRequired: result.ReviewDecision == "REVIEW_REQUIRED" || result.ReviewDecision == "CHANGES_REQUESTED",
+	}
+	if nodes := result.Commits.Nodes; len(nodes) > 0 && nodes[0].Commit.StatusCheckRollup != nil {
+		status.Checks = githubCheckState(nodes[0].Commit.StatusCheckRollup.State)
+	}
+	if result.Mergeable == "UNKNOWN" && status.Checks == ChecksPassing {
+		status.Checks = ChecksPending
+	}
+	return status, nil
+}
+
+// githubCheckState converts a GitHub status check rollup state.
+func githubCheckState(state string) CheckState {
+	switch state {
+	case "SUCCESS":
+		return ChecksPassing
+	case "FAILURE", "ERROR":
+		return ChecksFailing
+	default:
+		return ChecksPending
+	}
+}
+
+// MergePR merges a pull request with the given method.
+func (g *GitHub) MergePR(pr *PullRequest, method MergeMethod) error {
+	merge := map[string]any{"merge_method": string(method)}
+	if err := g.api.do(http.MethodPut, g.pullPath(pr.Number)+"/merge", merge, nil); err != nil {
+		return fmt.Errorf("failed to merge PR #%d: %w", pr.Number, err)
+	}
+	return nil
+}
+
+// RequiresSignedCommits reports whether the branch requires signed commits
+// through a repository ruleset or classic branch protection.
+func (g *GitHub) RequiresSignedCommits(branch string) (bool, error) {
+	var rules []struct {
+		Type string `json:"type"`
+	}
+	// Older GitHub Enterprise Server versions have no rulesets
+	err := g.api.do(http.MethodGet, g.repoPath()+"/rules/branches/"+url.PathEscape(branch), nil, &rules)
+	if err != nil && !isStatus(err, http.StatusNotFound) {
+		return false, fmt.Errorf("failed to read rules for %s: %w", branch, err)
+	}
+	for _, rule := range rules {
+		if rule.Type == "required_signatures" {
+			return true, nil
+		}
+	}
+
+	// Reading classic protection needs admin access, and an unprotected
+	// branch or one without the setting answers 404
+	var signatures struct {
+		Enabled bool `json:"enabled"`
+	}
+	err = g.api.do(http.MethodGet, g.repoPath()+"/branches/"+url.PathEscape(branch)+"/protection/required_signatures", nil, &signatures)
+	if isStatus(err, http.StatusForbidden, http.StatusNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read protection for %s: %w", branch, err)
+	}
+	return signatures.Enabled, nil
+}
+
+// githubIssue is the subset of GitHub's issue fields that is used.
+type githubIssue struct {
+	Number      int    `json:"number"`
+	HTMLURL     string `json:"html_url"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	PullRequest *struct {
+		URL string `json:"url"`
+	} `json:"pull_request"`
+}
+
+// issue converts the GitHub issue into an Issue.
+func (i *githubIssue) issue() *Issue {
+	return &Issue{Number: i.Number, URL: i.HTMLURL, Title: i.Title, Body: i.Body}
+}
+
+// FindIssue returns the open issue with exactly the title, or nil if there is
+// none. Open issues are listed rather than searched, since the search index
+// lags behind newly created issues.
+func (g *GitHub) FindIssue(title string) (*Issue, error) {
+	for page := 1; ; page++ {
+		var issues []githubIssue
+		path := fmt.Sprintf("%s/issues?state=open&page=%d&per_page=%d", g.repoPath(), page, githubPageSize)
+		if err := g.api.do(http.MethodGet, path, nil, &issues); err != nil {
+			return nil, fmt.Errorf("failed to find issue %q: %w", title, err)
+		}
+		for i := range issues {
+			// The issues endpoint also lists pull requests
+			if issues[i].PullRequest == nil && issues[i].Title == title {
+				return issues[i].issue(), nil
+			}
+		}
+		if len(issues) < githubPageSize {
+			return nil, nil
+		}
+	}
+}
+
+// CreateIssue creates an issue.
+func (g *GitHub) CreateIssue(title, body string) (*Issue, error) {
+	create := map[string]any{"title": title, "body": body}
+	var created githubIssue
+	if err := g.api.do(http.MethodPost, g.repoPath()+"/issues", create, &created); err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	return created.issue(), nil
+}
+
+// UpdateIssue replaces the body of an issue.
+func (g *GitHub) UpdateIssue(number int, body string) error {
+	update := map[string]any{"body": body}
+	if err := g.api.do(http.MethodPatch, g.issuePath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to update issue #%d: %w", number, err)
+	}
+	return nil
+}
+
+// EnsureFork returns the user's fork of the repository. GitHub returns the
+// existing fork when one exists, so this is safe to call on every run.
+func (g *GitHub) EnsureFork() (*Fork, error) {
+	var fork struct {
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+		CloneURL string `json:"clone_url"`
+		SSHURL   string `json:"ssh_url"`
+	}
+	if err := g.api.do(http.MethodPost, g.repoPath()+"/forks", map[string]any{}, &fork); err != nil {
+		return nil, fmt.Errorf("failed to fork %s/%s: %w", g.owner, g.repo, err)
+	}
+	return &Fork{
+		Owner:    fork.Owner.Login,
+		CloneURL: cloneURLFor(g.remoteURL, fork.CloneURL, fork.SSHURL),
+	}, nil
+}
+
+// repoPath returns the API path of the repository.
+func (g *GitHub) repoPath() string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(g.owner), url.PathEscape(g.repo))
+}
+
+// pullPath returns the API path of a pull request.
+func (g *GitHub) pullPath(number int) string {
+	return fmt.Sprintf("%s/pulls/%d", g.repoPath(), number)
+}
+
+// issuePath returns the API path of the issue backing a pull request, which
+// carries its labels and assignees.
+func (g *GitHub) issuePath(number int) string {
+	return fmt.Sprintf("%s/issues/%d", g.repoPath(), number)
+}
@@ -0,0 +1,424 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// GitHubName is the name of the GitHub forge.
+const GitHubName = "github"
+
+// GitHubCLI implements Forge for GitHub using the GitHub CLI (gh).
+type GitHubCLI struct {
+	// RepoDir is the repository directory to run gh in.
+	RepoDir string
+	// Binary is the gh executable to run (default: gh).
+	Binary string
+	// RemoteURL is the URL of the upstream remote, used to pick the fork's
+	// clone URL protocol (optional).
+	RemoteURL string
+}
+
+// NewGitHubCLI creates a new GitHubCLI instance for the given directory.
+func NewGitHubCLI(repoDir string) *GitHubCLI {
+	return &GitHubCLI{RepoDir: repoDir, Binary: "gh"}
+}
+
+// runGH runs a gh command in the repository directory.
+func (g *GitHubCLI) runGH(args ...string) (string, error) {
+	cmd := exec.Command(g.Binary, args...)
+	cmd.Dir = g.RepoDir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("gh %s failed: %w\nOutput: %s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Name returns the name used to select the forge.
+func (g *GitHubCLI) Name() string {
+	return GitHubName
+}
+
+// DefaultBranch returns the default branch name using GitHub CLI.
+func (g *GitHubCLI) DefaultBranch() (string, error) {
+	output, err := g.runGH("repo", "view", "--json", "defaultBranchRef", "--jq", ".defaultBranchRef.name")
+	if err != nil {
+		return "", fmt.Errorf("failed to get default branch: %w", err)
+	}
+	if output == "" {
+		return "", fmt.Errorf("failed to get default branch: empty response")
+	}
+	return output, nil
+}
+
+// SupportsMetadata reports whether the kind of metadata can be applied.
+// GitHub has no work items.
+func (g *GitHubCLI) SupportsMetadata(kind Metadata) bool {
+	return kind != MetadataWorkItems
+}
+
+// CreatePR creates a pull request using GitHub CLI. Metadata is added with
+// gh pr edit once the PR exists, so a label or reviewer gh rejects does not
+// lose the PR; the failures are returned as a *MetadataError.
+func (g *GitHubCLI) CreatePR(req PRRequest) (*PullRequest, error) {
+	args := []string{"pr", "create", "--base", req.Base, "--head", req.Head, "--title", req.Title, "--body", req.Body}
+	if req.Draft {
+		args = append(args, "--draft")
+	}
+	output, err := g.runGH(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+
+	// gh prints the PR URL as the last line of its output
+	lines := strings.Split(output, "\n")
+	url := strings.TrimSpace(lines[len(lines)-1])
+	pr := &PullRequest{
+		Number: prNumberFromURL(url),
+		URL:    url,
+		State:  "open",
+		Base:   req.Base,
+		Head:   req.Head,
+		Title:  req.Title,
+		Body:   req.Body,
+		Draft:  req.Draft,
+	}
+
+	var errs []error
+	if len(req.Labels) > 0 {
+		if err := g.AddLabels(pr.Number, req.Labels); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(req.Assignees) > 0 {
+		if _, err := g.runGH("pr", "edit", strconv.Itoa(pr.Number), "--add-assignee", strings.Join(req.Assignees, ",")); err != nil {
+			errs = append(errs, fmt.Errorf("failed to add assignees: %w", err))
+		}
+	}
+	if len(req.Reviewers) > 0 {
+		if err := g.RequestReviewers(pr.Number, req.Reviewers); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if req.Milestone != "" {
+		if _, err := g.runGH("pr", "edit", strconv.Itoa(pr.Number), "--milestone", req.Milestone); err != nil {
+			errs = append(errs, fmt.Errorf("failed to set milestone on PR #%d: %w", pr.Number, err))
+		}
+	}
+	for _, project := range req.Projects {
+		if err := g.addToProject(pr.Number, project); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return pr, metadataError(pr, errs)
+}
+
+// addToProject adds a pull request to a project board given as <number>
+// (owned by the repository owner) or <owner>/<number>, like the REST API
+// backend. gh pr edit takes the project title, so it is looked up first.
+func (g *GitHubCLI) addToProject(number int, project string) error {
+	owner, numberText := "", project
+	if before, after, ok := strings.Cut(project, "/"); ok {
+		owner, numberText = before, after
+	}
+	if _, err := strconv.Atoi(numberText); err != nil {
+		return fmt.Errorf("invalid project %q: want <number> or <owner>/<number>", project)
+	}
+	if owner == "" {
+		output, err := g.runGH("repo", "view", "--json", "owner", "--jq", ".owner.login")
+		if err != nil {
+			return fmt.Errorf("failed to find project %s: %w", project, err)
+		}
+		owner = output
+	}
+
+	title, err := g.runGH("project", "view", numberText, "--owner", owner, "--format", "json", "--jq", ".title")
+	if err != nil {
+		return fmt.Errorf("failed to find project %s: %w", project, err)
+	}
+	if _, err := g.runGH("pr", "edit", strconv.Itoa(number), "--add-project", title); err != nil {
+		return fmt.Errorf("failed to add PR to project %s: %w", project, err)
+	}
+	return nil
+}
+
+// ghPullRequest is the subset of gh's JSON pull request fields that is used.
+type ghPullRequest struct {
+	Number      int    `json:"number"`
+	ID          string `json:"id"`
+	URL         string `json:"url"`
+	State       string `json:"state"`
+	BaseRefName string `json:"baseRefName"`
+	HeadRefName string `json:"headRefName"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	IsDraft     bool   `json:"isDraft"`
+	// HeadRepositoryOwner is the owner of the repository the head branch is in.
+	HeadRepositoryOwner struct {
+		Login string `json:"login"`
+	} `json:"headRepositoryOwner"`
+}
+
+// FindPR returns the open pull request for the head branch, or nil if there
+// is none. The head is a branch name, matched in any repository, or
+// <owner>:<branch> for a branch in the owner's fork; gh filters by branch
+// name only, so the owner is matched here.
+func (g *GitHubCLI) FindPR(head string) (*PullRequest, error) {
+	owner, branch := "", head
+	if before, after, ok := strings.Cut(head, ":"); ok {
+		owner, branch = before, after
+	}
+	output, err := g.runGH("pr", "list", "--head", branch, "--state", "open", "--limit", "100",
+		"--json", "number,id,url,state,baseRefName,headRefName,headRepositoryOwner,title,body,isDraft")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find PR for %s: %w", head, err)
+	}
+
+	var prs []ghPullRequest
+	if err := json.Unmarshal([]byte(output), &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse PR list: %w", err)
+	}
+	for _, pr := range prs {
+		if owner != "" && !strings.EqualFold(pr.HeadRepositoryOwner.Login, owner) {
+			continue
+		}
+		return &PullRequest{
+			Number: pr.Number,
+			ID:     pr.ID,
+			URL:    pr.URL,
+			State:  strings.ToLower(pr.State),
+			Base:   pr.BaseRefName,
+			Head:   pr.HeadRefName,
+			Title:  pr.Title,
+			Body:   pr.Body,
+			Draft:  pr.IsDraft,
+		}, nil
+	}
+	return nil, nil
+}
+
+// UpdatePR updates the title and body of a pull request.
+func (g *GitHubCLI) UpdatePR(number int, title, body string) error {
+	_, err := g.runGH("pr", "edit", strconv.Itoa(number), "--title", title, "--body", body)
+	if err != nil {
+		return fmt.Errorf("failed to update PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// ClosePR closes a pull request without merging it.
+func (g *GitHubCLI) ClosePR(number int) error {
+	_, err := g.runGH("pr", "close", strconv.Itoa(number))
+	if err != nil {
+		return fmt.Errorf("failed to close PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// AddLabels adds labels to a pull request.
+func (g *GitHubCLI) AddLabels(number int, labels []string) error {
+	_, err := g.runGH("pr", "edit", strconv.Itoa(number), "--add-label", strings.Join(labels, ","))
+	if err != nil {
+		return fmt.Errorf("failed to add labels to PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// RequestReviewers requests reviews on a pull request.
+func (g *GitHubCLI) RequestReviewers(number int, reviewers []string) error {
+	_, err := g.runGH("pr", "edit", strconv.Itoa(number), "--add-reviewer", strings.Join(reviewers, ","))
+	if err != nil {
+		return fmt.Errorf("failed to request reviewers on PR #%d: %w", number, err)
+	}
+	return nil
+}
+
+// EnableAutoMerge turns on auto-merge for a pull request.
+func (g *GitHubCLI) EnableAutoMerge(pr *PullRequest, method MergeMethod) error {
+	if _, err := g.runGH("pr", "merge", pr.URL, "--auto", "--"+string(method)); err != nil {
+		return fmt.Errorf("failed to enable auto-merge on PR #%d: %w", pr.Number, err)
+	}
+	return nil
+}
+
+// PRStatus returns the checks, conflict and review state of a pull request.
+// While GitHub is still computing mergeability the checks are reported as
+// pending.
+func (g *GitHubCLI) PRStatus(pr *PullRequest) (*PRStatus, error) {
+	output, err := g.runGH("pr", "view", pr.URL, "--json", "mergeable,reviewDecision,statusCheckRollup")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status of PR #%d: %w", pr.Number, err)
+	}
+	var view struct {
+		Mergeable         string `json:"mergeable"`
+		ReviewDecision    string `json:"reviewDecision"`
+		StatusCheckRollup []struct {
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+			State      string `json:"state"`
+		} `json:"statusCheckRollup"`
+	}
+	if err := json.Unmarshal([]byte(output), &view); err != nil {
+		return nil, fmt.Errorf("failed to parse status of PR #%d: %w", pr.Number, err)
+	}
+
+	status := &PRStatus{
+		Checks:         ChecksPassing,
+		Conflicts:      view.Mergeable == "CONFLICTING",
+		ReviewRequired: view.ReviewDecision == "REVIEW_REQUIRED" || view.ReviewDecision == "CHANGES_REQUESTED",
+	}
+	for _, check := range view.StatusCheckRollup {
+		// Check runs report a status and conclusion, commit statuses a state
+		state := check.State
+		if state == "" {
+			switch {
+			case check.Status != "COMPLETED":
+				state = "PENDING"
+			case check.Conclusion == "SUCCESS" || check.Conclusion == "NEUTRAL" || check.Conclusion == "SKIPPED":
+				state = "SUCCESS"
+			default:
+				state = "FAILURE"
+			}
+		}
+		switch githubCheckState(state) {
+		case ChecksFailing:
+			status.Checks = ChecksFailing
+		case ChecksPending:
+			if status.Checks == ChecksPassing {
+				status.Checks = ChecksPending
+			}
+		}
+	}
+	if view.Mergeable == "UNKNOWN" && status.Checks == ChecksPassing {
+		status.Checks = ChecksPending
+	}
+	return status, nil
+}
+
+// MergePR merges a pull request with the given method.
+func (g *GitHubCLI) MergePR(pr *PullRequest, method MergeMethod) error {
+	if _, err := g.runGH("pr", "merge", pr.URL, "--"+string(method)); err != nil {
+		return fmt.Errorf("failed to merge PR #%d: %w", pr.Number, err)
+	}
+	return nil
+}
+
+// RequiresSignedCommits reports whether the branch requires signed commits
+// through a repository ruleset or classic branch protection.
+func (g *GitHubCLI) RequiresSignedCommits(branch string) (bool, error) {
+	path := "repos/{owner}/{repo}/rules/branches/" + url.PathEscape(branch)
+	output, err := g.runGH("api", path, "--jq", `any(.[]; .type == "required_signatures")`)
+	// Older GitHub Enterprise Server versions have no rulesets
+	if err != nil && !strings.Contains(output, "HTTP 404") {
+		return false, fmt.Errorf("failed to read rules for %s: %w", branch, err)
+	}
+	if err == nil && output == "true" {
+		return true, nil
+	}
+
+	// Reading classic protection needs admin access, and an unprotected
+	// branch or one without the setting answers 404
+	path = "repos/{owner}/{repo}/branches/" + url.PathEscape(branch) + "/protection/required_signatures"
+	output, err = g.runGH("api", path, "--jq", ".enabled")
+	if err != nil {
+		if strings.Contains(output, "HTTP 403") || strings.Contains(output, "HTTP 404") {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read protection for %s: %w", branch, err)
+	}
+	return output == "true", nil
+}
+
+// issueRepo returns the [HOST/]OWNER/REPO argument for gh issue commands, so
+// issues can be managed in a repository other than the one gh runs in.
+func (g *GitHubCLI) issueRepo() (string, error) {
+	info, err := ParseRemoteURL(g.RemoteURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate GitHub repository: %w", err)
+	}
+	return info.Host + "/" + info.Path, nil
+}
+
+// FindIssue returns the open issue with exactly the title, or nil if there is none.
+func (g *GitHubCLI) FindIssue(title string) (*Issue, error) {
+	repo, err := g.issueRepo()
+	if err != nil {
+		return nil, err
+	}
+	output, err := g.runGH("issue", "list", "--repo", repo, "--state", "open", "--limit", "1000", "--json", "number,url,title,body")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find issue %q: %w", title, err)
+	}
+	var issues []Issue
+	if err := json.Unmarshal([]byte(output), &issues); err != nil {
+		return nil, fmt.Errorf("failed to parse issue list: %w", err)
+	}
+	for i := range issues {
+		if issues[i].Title == title {
+			return &issues[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateIssue creates an issue using GitHub CLI.
+func (g *GitHubCLI) CreateIssue(title, body string) (*Issue, error) {
+	repo, err := g.issueRepo()
+	if err != nil {
+		return nil, err
+	}
+	output, err := g.runGH("issue", "create", "--repo", repo, "--title", title, "--body", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+
+	// gh prints the issue URL as the last line of its output
+	lines := strings.Split(output, "\n")
+	url := strings.TrimSpace(lines[len(lines)-1])
+	return &Issue{Number: prNumberFromURL(url), URL: url, Title: title, Body: body}, nil
+}
+
+// UpdateIssue replaces the body of an issue using GitHub CLI.
+func (g *GitHubCLI) UpdateIssue(number int, body string) error {
+	repo, err := g.issueRepo()
+	if err != nil {
+		return err
+	}
+	if _, err := g.runGH("issue", "edit", strconv.Itoa(number), "--repo", repo, "--body", body); err != nil {
+		return fmt.Errorf("failed to update issue #%d: %w", number, err)
+	}
+	return nil
+}
+
+// EnsureFork returns the user's fork of the repository, creating it if needed.
+// GitHub returns the existing fork when one exists.
+func (g *GitHubCLI) EnsureFork() (*Fork, error) {
+	output, err := g.runGH("api", "--method", "POST", "repos/{owner}/{repo}/forks",
+		"--jq", ".owner.login, .clone_url, .ssh_url")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fork repository: %w", err)
+	}
+	fields := strings.Split(output, "\n")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("failed to fork repository: unexpected output: %s", output)
+	}
+	return &Fork{
+		Owner:    strings.TrimSpace(fields[0]),
+		CloneURL: cloneURLFor(g.RemoteURL, strings.TrimSpace(fields[1]), strings.TrimSpace(fields[2])),
+	}, nil
+}
+
+// prNumberFromURL returns the number at the end of a pull request URL, or 0
+// if the URL does not end in a number.
+func prNumberFromURL(url string) int {
+	n, err := strconv.Atoi(url[strings.LastIndex(url, "/")+1:])
+	if err != nil {
+		return 0
+	}
+	return n
+}
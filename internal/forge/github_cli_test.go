@@ -0,0 +1,272 @@
+package forge
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeGH writes a gh stand-in script that records its arguments and prints output.
+func fakeGH(t *testing.T, output string) (*GitHubCLI, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake gh script requires a POSIX shell")
+	}
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := "#!/bin/sh\nfor a in \"$@\"; do echo \"$a\" >> '" + argsFile + "'; done\ncat <<'EOF'\n" + output + "\nEOF\n"
+	binary := filepath.Join(dir, "gh")
+	if err := os.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake gh: %v", err)
+	}
+	g := NewGitHubCLI(dir)
+	g.Binary = binary
+	return g, argsFile
+}
+
+func readArgs(t *testing.T, argsFile string) []string {
+	t.Helper()
+	data, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("failed to read args: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestGitHubCLICreatePR(t *testing.T) {
+	g, argsFile := fakeGH(t, "Creating pull request for feature into main\n\nhttps://github.com/owner/repo/pull/42")
+
+	pr, err := g.CreatePR(PRRequest{Base: "main", Head: "feature", Title: "Title", Body: "Body", Draft: true})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	if pr.URL != "https://github.com/owner/repo/pull/42" {
+		t.Errorf("URL = %q, want %q", pr.URL, "https://github.com/owner/repo/pull/42")
+	}
+	if pr.Number != 42 {
+		t.Errorf("Number = %d, want 42", pr.Number)
+	}
+
+	args := strings.Join(readArgs(t, argsFile), " ")
+	if args != "pr create --base main --head feature --title Title --body Body --draft" {
+		t.Errorf("gh args = %q", args)
+	}
+}
+
+func TestGitHubCLIFindPR(t *testing.T) {
+	g, _ := fakeGH(t, `[{"number":7,"id":"PR_kw","url":"https://github.com/owner/repo/pull/7","state":"OPEN","baseRefName":"main","headRefName":"feature","title":"Title","isDraft":false}]`)
+
+	pr, err := g.FindPR("feature")
+	if err != nil {
+		t.Fatalf("FindPR() error = %v", err)
+	}
+	if pr == nil || pr.Number != 7 || pr.ID != "PR_kw" || pr.State != "open" || pr.Head != "feature" {
+		t.Errorf("FindPR() = %+v, want PR #7", pr)
+	}
+
+	g, _ = fakeGH(t, `[]`)
+	pr, err = g.FindPR("feature")
+	if err != nil || pr != nil {
+		t.Errorf("FindPR() = %+v, %v, want nil", pr, err)
+	}
+}
+
+func TestGitHubCLIFindPRFromFork(t *testing.T) {
+	g, argsFile := fakeGH(t, `[{"number":7,"headRefName":"feature","headRepositoryOwner":{"login":"owner"}},{"number":9,"headRefName":"feature","headRepositoryOwner":{"login":"me"}}]`)
+
+	pr, err := g.FindPR("me:feature")
+	if err != nil || pr == nil || pr.Number != 9 {
+		t.Errorf("FindPR(me:feature) = %+v, %v, want PR #9", pr, err)
+	}
+	if args := readArgs(t, argsFile); args[3] != "feature" {
+		t.Errorf("gh args = %q, want --head feature", args)
+	}
+	if pr, err := g.FindPR("other:feature"); err != nil || pr != nil {
+		t.Errorf("FindPR(other:feature) = %+v, %v, want nil", pr, err)
+	}
+}
+
+func TestGitHubCLIDefaultBranch(t *testing.T) {
+	g, _ := fakeGH(t, "trunk")
+
+	branch, err := g.DefaultBranch()
+	if err != nil {
+		t.Fatalf("DefaultBranch() error = %v", err)
+	}
+	if branch != "trunk" {
+		t.Errorf("DefaultBranch() = %q, want %q", branch, "trunk")
+	}
+}
+
+func TestPRNumberFromURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected int
+	}{
+		{url: "https://github.com/owner/repo/pull/123", expected: 123},
+		{url: "https://github.com/owner/repo/pull/", expected: 0},
+		{url: "not a url", expected: 0},
+	}
+
+	for _, tt := range tests {
+		if got := prNumberFromURL(tt.url); got != tt.expected {
+			t.Errorf("prNumberFromURL(%q) = %d, want %d", tt.url, got, tt.expected)
+		}
+	}
+}
+
+func TestGitHubCLIEnsureFork(t *testing.T) {
+	g, argsFile := fakeGH(t, "me\nhttps://github.com/me/repo.git\ngit@github.com:me/repo.git")
+	g.RemoteURL = "https://github.com/owner/repo.git"
+
+	fork, err := g.EnsureFork()
+	if err != nil {
+		t.Fatalf("EnsureFork() error = %v", err)
+	}
+	if fork.Owner != "me" || fork.CloneURL != "https://github.com/me/repo.git" {
+		t.Errorf("EnsureFork() = %+v, want me with the HTTPS clone URL", fork)
+	}
+	if args := readArgs(t, argsFile); args[0] != "api" || args[3] != "repos/{owner}/{repo}/forks" {
+		t.Errorf("gh args = %q", args)
+	}
+}
+
+func TestGitHubCLICreatePRMilestoneAndProject(t *testing.T) {
+	g, argsFile := fakeGH(t, "https://github.com/owner/repo/pull/42")
+
+	_, err := g.CreatePR(PRRequest{Base: "main", Head: "feature", Title: "Title", Body: "Body", Milestone: "Q4", Projects: []string{"octo/7"}})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	args := strings.Join(readArgs(t, argsFile), " ")
+	for _, want := range []string{
+		"pr create --base main --head feature --title Title --body Body pr edit 42 --milestone Q4",
+		"project view 7 --owner octo --format json --jq .title",
+		"pr edit 42 --add-project https://github.com/owner/repo/pull/42",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("gh args = %q, want %q", args, want)
+		}
+	}
+}
+
+func TestGitHubCLICreatePRInvalidProject(t *testing.T) {
+	g, _ := fakeGH(t, "https://github.com/owner/repo/pull/42")
+
+	pr, err := g.CreatePR(PRRequest{Base: "main", Head: "feature", Title: "Title", Body: "Body", Projects: []string{"Roadmap"}})
+	var metaErr *MetadataError
+	if pr == nil || !errors.As(err, &metaErr) || !strings.Contains(err.Error(), "want <number> or <owner>/<number>") {
+		t.Errorf("CreatePR() = %v, %v, want the PR with a *MetadataError", pr, err)
+	}
+}
+
+func TestGitHubCLIPRStatus(t *testing.T) {
+	g, argsFile := fakeGH(t, `{"mergeable":"MERGEABLE","reviewDecision":"","statusCheckRollup":[{"status":"COMPLETED","conclusion":"SUCCESS"},{"status":"IN_PROGRESS","conclusion":""},{"state":"SUCCESS"}]}`)
+
+	status, err := g.PRStatus(&PullRequest{Number: 42, URL: "https://github.com/owner/repo/pull/42"})
+	if err != nil {
+		t.Fatalf("PRStatus() error = %v", err)
+	}
+	if *status != (PRStatus{Checks: ChecksPending}) {
+		t.Errorf("PRStatus() = %+v, want pending checks", *status)
+	}
+
+	args := strings.Join(readArgs(t, argsFile), " ")
+	if args != "pr view https://github.com/owner/repo/pull/42 --json mergeable,reviewDecision,statusCheckRollup" {
+		t.Errorf("gh args = %q", args)
+	}
+}
+
+func TestGitHubCLIPRStatusFailing(t *testing.T) {
+	g, _ := fakeGH(t, `{"mergeable":"CONFLICTING","reviewDecision":"CHANGES_REQUESTED","statusCheckRollup":[{"status":"IN_PROGRESS","conclusion":""},{"status":"COMPLETED","conclusion":"FAILURE"}]}`)
+
+	status, err := g.PRStatus(&PullRequest{Number: 42, URL: "https://github.com/owner/repo/pull/42"})
+	if err != nil {
+		t.Fatalf("PRStatus() error = %v", err)
+	}
+	want := PRStatus{Checks: ChecksFailing, Conflicts: true, ReviewRequired: true}
+	if *status != want {
+		t.Errorf("PRStatus() = %+v, want %+v", *status, want)
+	}
+}
+
+func TestGitHubCLIMergePR(t *testing.T) {
+	g, argsFile := fakeGH(t, "")
+
+	if err := g.MergePR(&PullRequest{Number: 42, URL: "https://github.com/owner/repo/pull/42"}, MergeMethodRebase); err != nil {
+		t.Fatalf("MergePR() error = %v", err)
+	}
+	args := strings.Join(readArgs(t, argsFile), " ")
+	if args != "pr merge https://github.com/owner/repo/pull/42 --rebase" {
+		t.Errorf("gh args = %q", args)
+	}
+}
+
+func TestGitHubCLICreateIssue(t *testing.T) {
+	g, argsFile := fakeGH(t, "https://github.com/acme/rollouts/issues/3")
+	g.RemoteURL = "git@github.com:acme/rollouts.git"
+
+	issue, err := g.CreateIssue("Rollout", "Body")
+	if err != nil {
+		t.Fatalf("CreateIssue() error = %v", err)
+	}
+	if issue.Number != 3 || issue.URL != "https://github.com/acme/rollouts/issues/3" {
+		t.Errorf("CreateIssue() = %+v", issue)
+	}
+	args := strings.Join(readArgs(t, argsFile), " ")
+	if args != "issue create --repo github.com/acme/rollouts --title Rollout --body Body" {
+		t.Errorf("gh args = %q", args)
+	}
+}
+
+func TestGitHubCLIFindIssue(t *testing.T) {
+	g, _ := fakeGH(t, `[{"number":2,"url":"https://github.com/acme/rollouts/issues/2","title":"Rollout of CI","body":""},{"number":3,"url":"https://github.com/acme/rollouts/issues/3","title":"Rollout","body":"entries"}]`)
+	g.RemoteURL = "https://github.com/acme/rollouts"
+
+	issue, err := g.FindIssue("Rollout")
+	if err != nil {
+		t.Fatalf("FindIssue() error = %v", err)
+	}
+	if issue == nil || issue.Number != 3 || issue.Body != "entries" {
+		t.Errorf("FindIssue() = %+v, want issue 3", issue)
+	}
+}
+
+func TestGitHubCLIRequiresSignedCommits(t *testing.T) {
+	g, argsFile := fakeGH(t, "true")
+	required, err := g.RequiresSignedCommits("release/1.x")
+	if err != nil || !required {
+		t.Fatalf("RequiresSignedCommits() = %v, %v, want true", required, err)
+	}
+	if args := readArgs(t, argsFile); args[0] != "api" || args[1] != "repos/{owner}/{repo}/rules/branches/release%2F1.x" {
+		t.Errorf("gh args = %q", args)
+	}
+
+	g, argsFile = fakeGH(t, "false")
+	required, err = g.RequiresSignedCommits("main")
+	if err != nil || required {
+		t.Fatalf("RequiresSignedCommits() = %v, %v, want false", required, err)
+	}
+	// Without a ruleset, classic protection is checked too
+	if args := readArgs(t, argsFile); len(args) != 8 || args[5] != "repos/{owner}/{repo}/branches/main/protection/required_signatures" {
+		t.Errorf("gh args = %q", args)
+	}
+}
+
+func TestGitHubCLIRequiresSignedCommitsFailingRunner(t *testing.T) {
+	g := NewGitHubCLI(t.TempDir())
+	g.Binary = filepath.Join(t.TempDir(), "missing-gh")
+
+	// The caller decides what an unknown answer means, so the failure is
+	// reported rather than read as "not required"
+	required, err := g.RequiresSignedCommits("main")
+	if err == nil {
+		t.Fatal("RequiresSignedCommits() error = nil, want the runner's error")
+	}
+	if required {
+		t.Error("RequiresSignedCommits() = true for a failing runner")
+	}
+}
@@ -0,0 +1,408 @@
+package forge
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeGitHub starts a fake API server and a GitHub forge pointed at it.
+func fakeGitHub(t *testing.T, responses map[string]string) (*GitHub, *[]apiRequest) {
+	t.Helper()
+	server, requests := fakeAPI(t, responses)
+	g, err := NewGitHub(Options{
+		RemoteURL: "git@github.com:owner/repo.git",
+		BaseURL:   server.URL,
+		Token:     "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewGitHub() error = %v", err)
+	}
+	return g, requests
+}
+
+func TestNewGitHubSelection(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	f, err := New(GitHubName, Options{RepoDir: ".", RemoteURL: "git@github.com:owner/repo.git"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, ok := f.(*GitHubCLI); !ok {
+		t.Errorf("New() without token = %T, want *GitHubCLI", f)
+	}
+
+	t.Setenv("GH_TOKEN", "secret")
+	f, err = New(GitHubName, Options{RepoDir: ".", RemoteURL: "git@github.com:owner/repo.git"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	g, ok := f.(*GitHub)
+	if !ok {
+		t.Fatalf("New() with token = %T, want *GitHub", f)
+	}
+	if g.api.baseURL != GitHubAPIURL {
+		t.Errorf("baseURL = %q, want %q", g.api.baseURL, GitHubAPIURL)
+	}
+}
+
+func TestNewGitHubEnterpriseURL(t *testing.T) {
+	g, err := NewGitHub(Options{RemoteURL: "https://ghe.example.com/owner/repo.git", Token: "secret"})
+	if err != nil {
+		t.Fatalf("NewGitHub() error = %v", err)
+	}
+	if g.api.baseURL != "https://ghe.example.com/api/v3" {
+		t.Errorf("baseURL = %q, want %q", g.api.baseURL, "https://ghe.example.com/api/v3")
+	}
+}
+
+func TestGitHubDefaultBranch(t *testing.T) {
+	g, requests := fakeGitHub(t, map[string]string{
+		"GET /repos/owner/repo": `{"default_branch":"trunk"}`,
+	})
+
+	branch, err := g.DefaultBranch()
+	if err != nil {
+		t.Fatalf("DefaultBranch() error = %v", err)
+	}
+	if branch != "trunk" {
+		t.Errorf("DefaultBranch() = %q, want %q", branch, "trunk")
+	}
+	header := (*requests)[0].Header
+	if header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", header.Get("Authorization"), "Bearer secret")
+	}
+	if header.Get("Accept") != "application/vnd.github+json" {
+		t.Errorf("Accept = %q, want %q", header.Get("Accept"), "application/vnd.github+json")
+	}
+}
+
+func TestGitHubCreatePR(t *testing.T) {
+	g, requests := fakeGitHub(t, map[string]string{
+		"POST /repos/owner/repo/pulls":               `{"number":42,"node_id":"PR_kwDO","html_url":"https://github.com/owner/repo/pull/42","state":"open","title":"Title","draft":true,"head":{"ref":"feature"},"base":{"ref":"main"}}`,
+		"POST /repos/owner/repo/issues/42/labels":    `[]`,
+		"POST /repos/owner/repo/issues/42/assignees": `{}`,
+	})
+
+	pr, err := g.CreatePR(PRRequest{
+		Base:      "main",
+		Head:      "feature",
+		Title:     "Title",
+		Body:      "Body",
+		Draft:     true,
+		Labels:    []string{"standards"},
+		Assignees: []string{"octocat"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	want := &PullRequest{
+		Number: 42,
+		ID:     "PR_kwDO",
+		URL:    "https://github.com/owner/repo/pull/42",
+		State:  "open",
+		Base:   "main",
+		Head:   "feature",
+		Title:  "Title",
+		Draft:  true,
+	}
+	if !reflect.DeepEqual(pr, want) {
+		t.Errorf("CreatePR() = %+v, want %+v", pr, want)
+	}
+
+	if len(*requests) != 3 {
+		t.Fatalf("requests = %d, want 3", len(*requests))
+	}
+	expected := map[string]any{"base": "main", "head": "feature", "title": "Title", "body": "Body", "draft": true}
+	if !reflect.DeepEqual((*requests)[0].Body, expected) {
+		t.Errorf("create body = %v, want %v", (*requests)[0].Body, expected)
+	}
+	if !reflect.DeepEqual((*requests)[1].Body["labels"], []any{"standards"}) {
+		t.Errorf("labels = %v, want [standards]", (*requests)[1].Body["labels"])
+	}
+	if !reflect.DeepEqual((*requests)[2].Body["assignees"], []any{"octocat"}) {
+		t.Errorf("assignees = %v, want [octocat]", (*requests)[2].Body["assignees"])
+	}
+}
+
+func TestGitHubCreatePRLabelFailure(t *testing.T) {
+	g, requests := fakeGitHub(t, map[string]string{
+		"POST /repos/owner/repo/pulls":                        `{"number":42,"html_url":"https://github.com/owner/repo/pull/42","state":"open"}`,
+		"POST /repos/owner/repo/pulls/42/requested_reviewers": `{}`,
+	})
+
+	pr, err := g.CreatePR(PRRequest{Base: "main", Head: "feature", Title: "Title", Labels: []string{"standards"}, Reviewers: []string{"octocat"}})
+	var metaErr *MetadataError
+	if !errors.As(err, &metaErr) {
+		t.Fatalf("CreatePR() error = %v, want a *MetadataError", err)
+	}
+	if len(metaErr.Errs) != 1 || metaErr.URL != "https://github.com/owner/repo/pull/42" {
+		t.Errorf("MetadataError = %+v, want one failure for the created PR", metaErr)
+	}
+	if pr == nil || pr.Number != 42 {
+		t.Errorf("CreatePR() = %+v, want the created PR alongside the error", pr)
+	}
+	// Reviewers are still requested after the labels fail
+	if len(*requests) != 3 {
+		t.Errorf("requests = %d, want 3", len(*requests))
+	}
+}
+
+func TestGitHubCreatePRMilestoneAndProject(t *testing.T) {
+	g, requests := fakeGitHub(t, map[string]string{
+		"POST /repos/owner/repo/pulls":      `{"number":42,"node_id":"PR_kwDO","html_url":"https://github.com/owner/repo/pull/42","state":"open"}`,
+		"GET /repos/owner/repo/milestones":  `[{"number":3,"title":"Q3"},{"number":5,"title":"Q4"}]`,
+		"PATCH /repos/owner/repo/issues/42": `{}`,
+		// Both the project lookup and the mutation are answered from the same data
+		"POST /graphql": `{"data":{"repositoryOwner":{"projectV2":{"id":"PVT_1"}},"addProjectV2ItemById":{"item":{"id":"PVTI_1"}}}}`,
+	})
+
+	_, err := g.CreatePR(PRRequest{Base: "main", Head: "feature", Title: "Title", Milestone: "Q4", Projects: []string{"acme/7"}})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	if len(*requests) != 5 {
+		t.Fatalf("requests = %d, want 5", len(*requests))
+	}
+	if got := (*requests)[2].Body["milestone"]; got != float64(5) {
+		t.Errorf("milestone = %v, want 5", got)
+	}
+	lookup := (*requests)[3].Body["variables"]
+	if !reflect.DeepEqual(lookup, map[string]any{"owner": "acme", "number": float64(7)}) {
+		t.Errorf("project lookup variables = %v", lookup)
+	}
+	add := (*requests)[4].Body["variables"]
+	if !reflect.DeepEqual(add, map[string]any{"project": "PVT_1", "content": "PR_kwDO"}) {
+		t.Errorf("add item variables = %v", add)
+	}
+}
+
+func TestGitHubCreatePRProjectNotFound(t *testing.T) {
+	g, _ := fakeGitHub(t, map[string]string{
+		"POST /repos/owner/repo/pulls": `{"number":42,"node_id":"PR_kwDO","html_url":"https://github.com/owner/repo/pull/42","state":"open"}`,
+		"POST /graphql":                `{"data":{"repositoryOwner":{"projectV2":null}},"errors":[{"message":"Could not resolve to a ProjectV2 with the number 9."}]}`,
+	})
+
+	pr, err := g.CreatePR(PRRequest{Base: "main", Head: "feature", Title: "Title", Projects: []string{"9"}})
+	var metaErr *MetadataError
+	if !errors.As(err, &metaErr) || pr == nil {
+		t.Errorf("CreatePR() = %v, %v, want the PR with a *MetadataError", pr, err)
+	}
+}
+
+func TestGraphQLBaseURL(t *testing.T) {
+	tests := map[string]string{
+		GitHubAPIURL:                     GitHubAPIURL,
+		"https://ghe.example.com/api/v3": "https://ghe.example.com/api",
+	}
+	for base, want := range tests {
+		if got := graphQLBaseURL(base); got != want {
+			t.Errorf("graphQLBaseURL(%q) = %q, want %q", base, got, want)
+		}
+	}
+}
+
+func TestGitHubFindPR(t *testing.T) {
+	g, requests := fakeGitHub(t, map[string]string{
+		"GET /repos/owner/repo/pulls": `[{"number":7,"node_id":"PR_kw","html_url":"https://github.com/owner/repo/pull/7","state":"open","head":{"ref":"feature"},"base":{"ref":"main"}}]`,
+	})
+
+	pr, err := g.FindPR("feature")
+	if err != nil {
+		t.Fatalf("FindPR() error = %v", err)
+	}
+	if pr == nil || pr.Number != 7 || pr.Head != "feature" {
+		t.Errorf("FindPR() = %+v, want PR #7", pr)
+	}
+	if (*requests)[0].Query != "state=open&head=owner%3Afeature" {
+		t.Errorf("query = %q", (*requests)[0].Query)
+	}
+}
+
+func TestGitHubRequestReviewers(t *testing.T) {
+	g, requests := fakeGitHub(t, map[string]string{
+		"POST /repos/owner/repo/pulls/7/requested_reviewers": `{}`,
+	})
+
+	if err := g.RequestReviewers(7, []string{"@octocat", "org/platform"}); err != nil {
+		t.Fatalf("RequestReviewers() error = %v", err)
+	}
+	expected := map[string]any{"reviewers": []any{"octocat"}, "team_reviewers": []any{"platform"}}
+	if !reflect.DeepEqual((*requests)[0].Body, expected) {
+		t.Errorf("request body = %v, want %v", (*requests)[0].Body, expected)
+	}
+}
+
+func TestGitHubEnsureFork(t *testing.T) {
+	g, requests := fakeGitHub(t, map[string]string{
+		"POST /repos/owner/repo/forks": `{"owner":{"login":"me"},"clone_url":"https://github.com/me/repo.git","ssh_url":"git@github.com:me/repo.git"}`,
+	})
+
+	fork, err := g.EnsureFork()
+	if err != nil {
+		t.Fatalf("EnsureFork() error = %v", err)
+	}
+	// The upstream remote uses SSH, so the fork does too
+	want := &Fork{Owner: "me", CloneURL: "git@github.com:me/repo.git"}
+	if !reflect.DeepEqual(fork, want) {
+		t.Errorf("EnsureFork() = %+v, want %+v", fork, want)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("requests = %d, want 1", len(*requests))
+	}
+}
+
+func TestGitHubEnableAutoMerge(t *testing.T) {
+	g, requests := fakeGitHub(t, map[string]string{
+		"POST /graphql": `{"data":{"enablePullRequestAutoMerge":{"clientMutationId":null}}}`,
+	})
+
+	if err := g.EnableAutoMerge(&PullRequest{Number: 42, ID: "PR_kwDO"}, MergeMethodSquash); err != nil {
+		t.Fatalf("EnableAutoMerge() error = %v", err)
+	}
+	want := map[string]any{"pr": "PR_kwDO", "method": "SQUASH"}
+	if got := (*requests)[0].Body["variables"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("variables = %v, want %v", got, want)
+	}
+}
+
+func TestGitHubEnableAutoMergeNotAllowed(t *testing.T) {
+	g, _ := fakeGitHub(t, map[string]string{
+		"POST /graphql": `{"data":{"enablePullRequestAutoMerge":null},"errors":[{"message":"Pull request Auto merge is not allowed for this repository"}]}`,
+	})
+
+	err := g.EnableAutoMerge(&PullRequest{Number: 42, ID: "PR_kwDO"}, MergeMethodMerge)
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("EnableAutoMerge() error = %v, want the GraphQL error", err)
+	}
+}
+
+func TestGitHubPRStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     PRStatus
+	}{
+		{
+			name:     "ready",
+			response: `{"data":{"repository":{"pullRequest":{"mergeable":"MERGEABLE","reviewDecision":"APPROVED","commits":{"nodes":[{"commit":{"statusCheckRollup":{"state":"SUCCESS"}}}]}}}}}`,
+			want:     PRStatus{Checks: ChecksPassing},
+		},
+		{
+			name:     "no checks",
+			response: `{"data":{"repository":{"pullRequest":{"mergeable":"MERGEABLE","reviewDecision":null,"commits":{"nodes":[{"commit":{"statusCheckRollup":null}}]}}}}}`,
+			want:     PRStatus{Checks: ChecksPassing},
+		},
+		{
+			name:     "mergeability unknown",
+			response: `{"data":{"repository":{"pullRequest":{"mergeable":"UNKNOWN","reviewDecision":null,"commits":{"nodes":[]}}}}}`,
+			want:     PRStatus{Checks: ChecksPending},
+		},
+		{
+			name:     "blocked",
+			response: `{"data":{"repository":{"pullRequest":{"mergeable":"CONFLICTING","reviewDecision":"REVIEW_REQUIRED","commits":{"nodes":[{"commit":{"statusCheckRollup":{"state":"FAILURE"}}}]}}}}}`,
+			want:     PRStatus{Checks: ChecksFailing, Conflicts: true, ReviewRequired: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, requests := fakeGitHub(t, map[string]string{"POST /graphql": tt.response})
+
+			status, err := g.PRStatus(&PullRequest{Number: 42})
+			if err != nil {
+				t.Fatalf("PRStatus() error = %v", err)
+			}
+			if *status != tt.want {
+				t.Errorf("PRStatus() = %+v, want %+v", *status, tt.want)
+			}
+			want := map[string]any{"owner": "owner", "repo": "repo", "number": float64(42)}
+			if got := (*requests)[0].Body["variables"]; !reflect.DeepEqual(got, want) {
+				t.Errorf("variables = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGitHubMergePR(t *testing.T) {
+	g, requests := fakeGitHub(t, map[string]string{
+		"PUT /repos/owner/repo/pulls/42/merge": `{"merged":true}`,
+	})
+
+	if err := g.MergePR(&PullRequest{Number: 42}, MergeMethodSquash); err != nil {
+		t.Fatalf("MergePR() error = %v", err)
+	}
+	want := map[string]any{"merge_method": "squash"}
+	if got := (*requests)[0].Body; !reflect.DeepEqual(got, want) {
+		t.Errorf("body = %v, want %v", got, want)
+	}
+}
+
+func TestGitHubFindIssue(t *testing.T) {
+	g, requests := fakeGitHub(t, map[string]string{
+		"GET /repos/owner/repo/issues": `[
+			{"number":3,"html_url":"https://github.com/owner/repo/pull/3","title":"Rollout","pull_request":{"url":"x"}},
+			{"number":4,"html_url":"https://github.com/owner/repo/issues/4","title":"Rollout of CI"},
+			{"number":5,"html_url":"https://github.com/owner/repo/issues/5","title":"Rollout","body":"entries"}
+		]`,
+	})
+
+	issue, err := g.FindIssue("Rollout")
+	if err != nil {
+		t.Fatalf("FindIssue() error = %v", err)
+	}
+	want := &Issue{Number: 5, URL: "https://github.com/owner/repo/issues/5", Title: "Rollout", Body: "entries"}
+	if !reflect.DeepEqual(issue, want) {
+		t.Errorf("FindIssue() = %+v, want %+v", issue, want)
+	}
+	if got := (*requests)[0].Query; got != "state=open&page=1&per_page=100" {
+		t.Errorf("query = %q", got)
+	}
+}
+
+func TestGitHubCreateAndUpdateIssue(t *testing.T) {
+	g, requests := fakeGitHub(t, map[string]string{
+		"POST /repos/owner/repo/issues":    `{"number":6,"html_url":"https://github.com/owner/repo/issues/6","title":"Rollout","body":"Body"}`,
+		"PATCH /repos/owner/repo/issues/6": `{}`,
+	})
+
+	issue, err := g.CreateIssue("Rollout", "Body")
+	if err != nil {
+		t.Fatalf("CreateIssue() error = %v", err)
+	}
+	if issue.Number != 6 || issue.URL != "https://github.com/owner/repo/issues/6" {
+		t.Errorf("CreateIssue() = %+v", issue)
+	}
+	if err := g.UpdateIssue(6, "New body"); err != nil {
+		t.Fatalf("UpdateIssue() error = %v", err)
+	}
+	want := map[string]any{"body": "New body"}
+	if got := (*requests)[1].Body; !reflect.DeepEqual(got, want) {
+		t.Errorf("update body = %v, want %v", got, want)
+	}
+}
+
+func TestGitHubRequiresSignedCommits(t *testing.T) {
+	g, _ := fakeGitHub(t, map[string]string{
+		"GET /repos/owner/repo/rules/branches/main":                                   `[{"type":"pull_request"},{"type":"required_signatures"}]`,
+		"GET /repos/owner/repo/branches/release%2F1.x/protection/required_signatures": `{"enabled":true}`,
+		"GET /repos/owner/repo/branches/develop/protection/required_signatures":       `{"enabled":false}`,
+	})
+
+	tests := map[string]bool{
+		"main":        true,  // ruleset
+		"release/1.x": true,  // classic protection
+		"develop":     false, // protected without signatures
+		"feature":     false, // unprotected: both endpoints answer 404
+	}
+	for branch, want := range tests {
+		got, err := g.RequiresSignedCommits(branch)
+		if err != nil {
+			t.Fatalf("RequiresSignedCommits(%q) error = %v", branch, err)
+		}
+		if got != want {
+			t.Errorf("RequiresSignedCommits(%q) = %v, want %v", branch, got, want)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package forge
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// GitLabName is the name of the GitLab forge.
+const GitLabName = "gitlab"
+
+// GitLab implements Forge for GitLab merge requests using the REST API.
+// The token is read from GITLAB_TOKEN unless set in the options.
+type GitLab struct {
+	api     *apiClient
+	project string
+}
+
+// NewGitLab creates a GitLab forge for the project the remote URL points at.
+// The API defaults to https://<remote host>/api/v4 so self-managed instances
+// work without configuration.
+func NewGitLab(opts Options) (*GitLab, error) {
+	info, err := ParseRemoteURL(opts.RemoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate GitLab project: %w", err)
+	}
+
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://%s/api/v4", info.Host)
+	}
+	token := opts.Token
+	if token == "" {
+		token = os.Getenv("GITLAB_TOKEN")
+	}
+
+	headers := map[string]string{}
+	if token != "" {
+		headers["PRIVATE-TOKEN"] = token
+	}
+	return &GitLab{
+		api:     newAPIClient(baseURL, headers),
+		project: url.PathEscape(info.Path),
+	}, nil
+}
+
+// Name returns the name used to select the forge.
+func (g *GitLab) Name() string {
+	return GitLabName
+}
+
+// gitlabMergeRequest is the subset of GitLab's merge request fields that is used.
+type gitlabMergeRequest struct {
+	ID           int    `json:"id"`
+	IID          int    `json:"iid"`
+	WebURL       string `json:"web_url"`
+	State        string `json:"state"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Draft        bool   `json:"draft"`
+	Reviewers    []struct {
+		ID int `json:"id"`
+	} `json:"reviewers"`
+	HasConflicts        bool   `json:"has_conflicts"`
+	DetailedMergeStatus string `json:"detailed_merge_status"`
+	HeadPipeline        *struct {
+		Status string `json:"status"`
+	} `json:"head_pipeline"`
+}
+
+// pullRequest converts the merge request into a PullRequest.
+func (mr *gitlabMergeRequest) pullRequest() *PullRequest {
+	state := mr.State
+	if state == "opened" {
+		state = "open"
+	}
+	return &PullRequest{
+		Number: mr.IID,
+		ID:     fmt.Sprint(mr.ID),
+		URL:    mr.WebURL,
+		State:  state,
+		Base:   mr.TargetBranch,
+		Head:   mr.SourceBranch,
+		Title:  mr.Title,
+		Body:   mr.Description,
+		Draft:  mr.Draft,
+	}
+}
+
+// DefaultBranch returns the project's default branch.
+func (g *GitLab) DefaultBranch() (string, error) {
+	var project struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := g.api.do(http.MethodGet, "/projects/"+g.project, nil, &project); err != nil {
+		return "", fmt.Errorf("failed to get default branch: %w", err)
+	}
+	if project.DefaultBranch == "" {
+		return "", fmt.Errorf("failed to get default branch: empty response")
+	}
+	return project.DefaultBranch, nil
+}
+
+// SupportsMetadata reports whether the kind of metadata can be applied.
+// GitLab reviewers are users, and there are no merge request project boards
+// or work items.
+func (g *GitLab) SupportsMetadata(kind Metadata) bool {
+	return kind != MetadataTeamReviewers && kind != MetadataProjects && kind != MetadataWorkItems
+}
+
+// CreatePR creates a merge request. Drafts are created with the "Draft:"
+// title prefix, which GitLab recognizes on every supported version. Labels
+// are created along with the merge request; assignees, reviewers and the
+// milestone are resolved and set afterwards, so a user or milestone that
+// cannot be found is returned as a *MetadataError without losing the merge
+// request.
+func (g *GitLab) CreatePR(req PRRequest) (*PullRequest, error) {
+	title := req.Title
+	if req.Draft && !strings.HasPrefix(title, "Draft:") {
+		title = "Draft: " + title
+	}
+
+	body := map[string]any{
+		"source_branch":        req.Head,
+		"target_branch":        req.Base,
+		"title":                title,
+		"description":          req.Body,
+		"remove_source_branch": req.RemoveSourceBranch,
+	}
+	if len(req.Labels) > 0 {
+		body["labels"] = strings.Join(req.Labels, ",")
+	}
+
+	var mr gitlabMergeRequest
+	if err := g.api.do(http.MethodPost, g.mergeRequestsPath(), body, &mr); err != nil {
+		return nil, fmt.Errorf("failed to create merge request: %w", err)
+	}
+	pr := mr.pullRequest()
+
+	var errs []error
+	if len(req.Assignees) > 0 {
+		if err := g.setAssignees(pr.Number, req.Assignees); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(req.Reviewers) > 0 {
+		if err := g.RequestReviewers(pr.Number, req.Reviewers); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if req.Milestone != "" {
+		if err := g.setMilestone(pr.Number, req.Milestone); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return pr, metadataError(pr, errs)
+}
+
+// setAssignees assigns users, given by user name, to a merge request.
+func (g *GitLab) setAssignees(number int, assignees []string) error {
+	ids, err := g.userIDs(assignees)
+	if err != nil {
+		return fmt.Errorf("failed to add assignees to merge request !%d: %w", number, err)
+	}
+	update := map[string]any{"assignee_ids": ids}
+	if err := g.api.do(http.MethodPut, g.mergeRequestPath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to add assignees to merge request !%d: %w", number, err)
+	}
+	return nil
+}
+
+// setMilestone adds a merge request to an active milestone given by title.
+func (g *GitLab) setMilestone(number int, milestone string) error {
+	id, err := g.milestoneID(milestone)
+	if err != nil {
+		return fmt.Errorf("failed to set milestone on merge request !%d: %w", number, err)
+	}
+	update := map[string]any{"milestone_id": id}
+	if err := g.api.do(http.MethodPut, g.mergeRequestPath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to set milestone on merge request !%d: %w", number, err)
+	}
+	return nil
+}
+
+// FindPR returns the open merge request for the source branch, or nil if there is none.
+func (g *GitLab) FindPR(head string) (*PullRequest, error) {
+	var mrs []gitlabMergeRequest
+	path := g.mergeRequestsPath() + "?state=opened&source_branch=" + url.QueryEscape(head)
+	if err := g.api.do(http.MethodGet, path, nil, &mrs); err != nil {
+		return nil, fmt.Errorf("failed to find merge request for %s: %w", head, err)
+	}
+	if len(mrs) == 0 {
+		return nil, nil
+	}
+	return mrs[0].pullRequest(), nil
+}
+
+// UpdatePR updates the title and description of a merge request.
+func (g *GitLab) UpdatePR(number int, title, body string) error {
+	update := map[string]any{"title": title, "description": body}
+	if err := g.api.do(http.MethodPut, g.mergeRequestPath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to update merge request !%d: %w", number, err)
+	}
+	return nil
+}
+
+// ClosePR closes a merge request without merging it.
+func (g *GitLab) ClosePR(number int) error {
+	update := map[string]any{"state_event": "close"}
+	if err := g.api.do(http.MethodPut, g.mergeRequestPath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to close merge request !%d: %w", number, err)
+	}
+	return nil
+}
+
+// EnableAutoMerge sets a merge request to merge when its pipeline succeeds.
+// Whether a merge commit is created is a project setting, so only the merge
+// and squash methods can be requested.
+func (g *GitLab) EnableAutoMerge(pr *PullRequest, method MergeMethod) error {
+	if method == MergeMethodRebase {
+		return fmt.Errorf("failed to enable auto-merge on merge request !%d: the rebase method is %w", pr.Number, ErrNotSupported)
+	}
+	merge := map[string]any{
+		"merge_when_pipeline_succeeds": true,
+		"squash":                       method == MergeMethodSquash,
+	}
+	if err := g.api.do(http.MethodPut, g.mergeRequestPath(pr.Number)+"/merge", merge, nil); err != nil {
+		return fmt.Errorf("failed to enable auto-merge on merge request !%d: %w", pr.Number, err)
+	}
+	return nil
+}
+
+// PRStatus returns the pipeline, conflict and approval state of a merge request.
+func (g *GitLab) PRStatus(pr *PullRequest) (*PRStatus, error) {
+	var mr gitlabMergeRequest
+	if err := g.api.do(http.MethodGet, g.mergeRequestPath(pr.Number), nil, &mr); err != nil {
+		return nil, fmt.Errorf("failed to get status of merge request !%d: %w", pr.Number, err)
+	}
+
+	status := &PRStatus{
+		Checks:         ChecksPassing,
+		Conflicts:      mr.HasConflicts,
+		ReviewRequired: mr.DetailedMergeStatus == "not_approved",
+	}
+	if mr.HeadPipeline != nil {
+		switch mr.HeadPipeline.Status {
+		case "success", "skipped", "manual":
+		case "failed", "canceled":
+			status.Checks = ChecksFailing
+		default:
+			status.Checks = ChecksPending
+		}
+	}
+	// GitLab computes mergeability in the background
+	switch mr.DetailedMergeStatus {
+	case "checking", "unchecked", "approvals_syncing":
+		if status.Checks == ChecksPassing {
+			status.Checks = ChecksPending
+		}
+	}
+	return status, nil
+}
+
+// MergePR merges a merge request. Whether a merge commit is created is a
+// project setting, so only the merge and squash methods can be requested.
+func (g *GitLab) MergePR(pr *PullRequest, method MergeMethod) error {
+	if method == MergeMethodRebase {
+		return fmt.Errorf("failed to merge merge request !%d: the rebase method is %w", pr.Number, ErrNotSupported)
+	}
+	merge := map[string]any{"squash": method == MergeMethodSquash}
+	if err := g.api.do(http.MethodPut, g.mergeRequestPath(pr.Number)+"/merge", merge, nil); err != nil {
+		return fmt.Errorf("failed to merge merge request !%d: %w", pr.Number, err)
+	}
+	return nil
+}
+
+// RequiresSignedCommits reports whether the project's push rules reject
+// unsigned commits. Push rules apply to every branch, and projects without
+// them, or on tiers without them, answer 404.
+func (g *GitLab) RequiresSignedCommits(branch string) (bool, error) {
+	var rule struct {
+		RejectUnsignedCommits bool `json:"reject_unsigned_commits"`
+	}
+	err := g.api.do(http.MethodGet, fmt.Sprintf("/projects/%s/push_rule", g.project), nil, &rule)
+	if isStatus(err, http.StatusForbidden, http.StatusNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read push rules: %w", err)
+	}
+	return rule.RejectUnsignedCommits, nil
+}
+
+// gitlabIssue is the subset of GitLab's issue fields that is used.
+type gitlabIssue struct {
+	IID         int    `json:"iid"`
+	WebURL      string `json:"web_url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// issue converts the GitLab issue into an Issue.
+func (i *gitlabIssue) issue() *Issue {
+	return &Issue{Number: i.IID, URL: i.WebURL, Title: i.Title, Body: i.Description}
+}
+
+// FindIssue returns the open issue with exactly the title, or nil if there is none.
+func (g *GitLab) FindIssue(title string) (*Issue, error) {
+	var issues []gitlabIssue
+	path := g.issuesPath() + "?state=opened&in=title&per_page=100&search=" + url.QueryEscape(title)
+	if err := g.api.do(http.MethodGet, path, nil, &issues); err != nil {
+		return nil, fmt.Errorf("failed to find issue %q: %w", title, err)
+	}
+	// The search also matches titles that only contain the words
+	for i := range issues {
+		if issues[i].Title == title {
+			return issues[i].issue(), nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateIssue creates an issue.
+func (g *GitLab) CreateIssue(title, body string) (*Issue, error) {
+	create := map[string]any{"title": title, "description": body}
+	var created gitlabIssue
+	if err := g.api.do(http.MethodPost, g.issuesPath(), create, &created); err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	return created.issue(), nil
+}
+
+// UpdateIssue replaces the description of an issue.
+func (g *GitLab) UpdateIssue(number int, body string) error {
+	update := map[string]any{"description": body}
+	if err := g.api.do(http.MethodPut, fmt.Sprintf("%s/%d", g.issuesPath(), number), update, nil); err != nil {
+		return fmt.Errorf("failed to update issue #%d: %w", number, err)
+	}
+	return nil
+}
+
+// AddLabels adds labels to a merge request.
+func (g *GitLab) AddLabels(number int, labels []string) error {
+	update := map[string]any{"add_labels": strings.Join(labels, ",")}
+	if err := g.api.do(http.MethodPut, g.mergeRequestPath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to add labels to merge request !%d: %w", number, err)
+	}
+	return nil
+}
+
+// RequestReviewers adds reviewers to a merge request, keeping existing ones.
+func (g *GitLab) RequestReviewers(number int, reviewers []string) error {
+	ids, err := g.userIDs(reviewers)
+	if err != nil {
+		return fmt.Errorf("failed to request reviewers on merge request !%d: %w", number, err)
+	}
+
+	// GitLab replaces the reviewer list, so merge in the current reviewers
+	var mr gitlabMergeRequest
+	if err := g.api.do(http.MethodGet, g.mergeRequestPath(number), nil, &mr); err != nil {
+		return fmt.Errorf("failed to request reviewers on merge request !%d: %w", number, err)
+	}
+	for _, reviewer := range mr.Reviewers {
+		ids = append(ids, reviewer.ID)
+	}
+
+	update := map[string]any{"reviewer_ids": ids}
+	if err := g.api.do(http.MethodPut, g.mergeRequestPath(number), update, nil); err != nil {
+		return fmt.Errorf("failed to request reviewers on merge request !%d: %w", number, err)
+	}
+	return nil
+}
+
+// userIDs resolves GitLab user names to user IDs.
+func (g *GitLab) userIDs(usernames []string) ([]int, error) {
+	ids := make([]int, 0, len(usernames))
+	for _, username := range usernames {
+		var users []struct {
+			ID int `json:"id"`
+		}
+		name := strings.TrimPrefix(username, "@")
+		if err := g.api.do(http.MethodGet, "/users?username="+url.QueryEscape(name), nil, &users); err != nil {
+			return nil, fmt.Errorf("failed to look up user %s: %w", name, err)
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("user %s not found", name)
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids, nil
+}
+
+// milestoneID resolves the title of an active project milestone to its ID.
+func (g *GitLab) milestoneID(title string) (int, error) {
+	var milestones []struct {
+		ID int `json:"id"`
+	}
+	path := fmt.Sprintf("/projects/%s/milestones?state=active&title=%s", g.project, url.QueryEscape(title))
+	if err := g.api.do(http.MethodGet, path, nil, &milestones); err != nil {
+		return 0, fmt.Errorf("failed to look up milestone %s: %w", title, err)
+	}
+	if len(milestones) == 0 {
+		return 0, fmt.Errorf("milestone %q not found", title)
+	}
+	return milestones[0].ID, nil
+}
+
+// issuesPath returns the API path of the project's issues.
+func (g *GitLab) issuesPath() string {
+	return fmt.Sprintf("/projects/%s/issues", g.project)
+}
+
+// mergeRequestsPath returns the API path of the project's merge requests.
+func (g *GitLab) mergeRequestsPath() string {
+	return fmt.Sprintf("/projects/%s/merge_requests", g.project)
+}
+
+// mergeRequestPath returns the API path of a single merge request.
+func (g *GitLab) mergeRequestPath(number int) string {
+	return fmt.Sprintf("%s/%d", g.mergeRequestsPath(), number)
+}
@@ -0,0 +1,312 @@
+package forge
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// fakeGitLab starts a fake API server and a GitLab forge pointed at it.
+func fakeGitLab(t *testing.T, responses map[string]string) (*GitLab, *[]apiRequest) {
+	t.Helper()
+	server, requests := fakeAPI(t, responses)
+	g, err := NewGitLab(Options{
+		RemoteURL: "git@gitlab.example.com:group/sub/repo.git",
+		BaseURL:   server.URL + "/api/v4",
+		Token:     "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewGitLab() error = %v", err)
+	}
+	return g, requests
+}
+
+const gitlabProjectPath = "/api/v4/projects/group%2Fsub%2Frepo"
+
+func TestGitLabDefaultBranch(t *testing.T) {
+	g, requests := fakeGitLab(t, map[string]string{
+		"GET " + gitlabProjectPath: `{"id":1,"default_branch":"trunk"}`,
+	})
+
+	branch, err := g.DefaultBranch()
+	if err != nil {
+		t.Fatalf("DefaultBranch() error = %v", err)
+	}
+	if branch != "trunk" {
+		t.Errorf("DefaultBranch() = %q, want %q", branch, "trunk")
+	}
+	if (*requests)[0].Header.Get("PRIVATE-TOKEN") != "secret" {
+		t.Errorf("PRIVATE-TOKEN = %q, want %q", (*requests)[0].Header.Get("PRIVATE-TOKEN"), "secret")
+	}
+}
+
+func TestGitLabCreatePR(t *testing.T) {
+	g, requests := fakeGitLab(t, map[string]string{
+		"GET /api/v4/users":                               `[{"id":17,"username":"alice"}]`,
+		"POST " + gitlabProjectPath + "/merge_requests":   `{"id":900,"iid":12,"web_url":"https://gitlab.example.com/group/sub/repo/-/merge_requests/12","state":"opened","source_branch":"feature","target_branch":"main","title":"Draft: Title","draft":true}`,
+		"PUT " + gitlabProjectPath + "/merge_requests/12": `{"iid":12}`,
+	})
+
+	pr, err := g.CreatePR(PRRequest{
+		Base:               "main",
+		Head:               "feature",
+		Title:              "Title",
+		Body:               "Body",
+		Draft:              true,
+		Labels:             []string{"chore", "standards"},
+		Assignees:          []string{"@alice"},
+		RemoveSourceBranch: true,
+	})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	want := &PullRequest{
+		Number: 12,
+		ID:     "900",
+		URL:    "https://gitlab.example.com/group/sub/repo/-/merge_requests/12",
+		State:  "open",
+		Base:   "main",
+		Head:   "feature",
+		Title:  "Draft: Title",
+		Draft:  true,
+	}
+	if !reflect.DeepEqual(pr, want) {
+		t.Errorf("CreatePR() = %+v, want %+v", pr, want)
+	}
+
+	if len(*requests) != 3 {
+		t.Fatalf("requests = %d, want 3", len(*requests))
+	}
+	body := (*requests)[0].Body
+	expected := map[string]any{
+		"source_branch":        "feature",
+		"target_branch":        "main",
+		"title":                "Draft: Title",
+		"description":          "Body",
+		"labels":               "chore,standards",
+		"remove_source_branch": true,
+	}
+	if !reflect.DeepEqual(body, expected) {
+		t.Errorf("request body = %v, want %v", body, expected)
+	}
+	if (*requests)[1].Query != "username=alice" {
+		t.Errorf("user lookup query = %q, want %q", (*requests)[1].Query, "username=alice")
+	}
+	if got := (*requests)[2].Body["assignee_ids"]; !reflect.DeepEqual(got, []any{float64(17)}) {
+		t.Errorf("assignee_ids = %v, want [17]", got)
+	}
+}
+
+func TestGitLabCreatePRUnknownAssignee(t *testing.T) {
+	g, _ := fakeGitLab(t, map[string]string{
+		"GET /api/v4/users":                             `[]`,
+		"POST " + gitlabProjectPath + "/merge_requests": `{"id":900,"iid":12,"web_url":"https://gitlab.example.com/group/sub/repo/-/merge_requests/12","state":"opened"}`,
+	})
+
+	pr, err := g.CreatePR(PRRequest{Base: "main", Head: "feature", Title: "Title", Assignees: []string{"nobody"}})
+	var metaErr *MetadataError
+	if pr == nil || !errors.As(err, &metaErr) || len(metaErr.Errs) != 1 {
+		t.Errorf("CreatePR() = %v, %v, want the merge request with a *MetadataError", pr, err)
+	}
+}
+
+func TestGitLabFindPR(t *testing.T) {
+	g, requests := fakeGitLab(t, map[string]string{
+		"GET " + gitlabProjectPath + "/merge_requests": `[{"id":900,"iid":12,"web_url":"https://gitlab.example.com/group/sub/repo/-/merge_requests/12","state":"opened","source_branch":"feature","target_branch":"main","title":"Title"}]`,
+	})
+
+	pr, err := g.FindPR("feature")
+	if err != nil {
+		t.Fatalf("FindPR() error = %v", err)
+	}
+	if pr == nil || pr.Number != 12 || pr.State != "open" {
+		t.Errorf("FindPR() = %+v, want open merge request !12", pr)
+	}
+	if (*requests)[0].Query != "state=opened&source_branch=feature" {
+		t.Errorf("query = %q", (*requests)[0].Query)
+	}
+}
+
+func TestGitLabRequestReviewers(t *testing.T) {
+	g, requests := fakeGitLab(t, map[string]string{
+		"GET /api/v4/users": `[{"id":17}]`,
+		"GET " + gitlabProjectPath + "/merge_requests/12": `{"iid":12,"reviewers":[{"id":5}]}`,
+		"PUT " + gitlabProjectPath + "/merge_requests/12": `{"iid":12}`,
+	})
+
+	if err := g.RequestReviewers(12, []string{"alice"}); err != nil {
+		t.Fatalf("RequestReviewers() error = %v", err)
+	}
+	update := (*requests)[len(*requests)-1].Body
+	if !reflect.DeepEqual(update["reviewer_ids"], []any{float64(17), float64(5)}) {
+		t.Errorf("reviewer_ids = %v, want [17 5]", update["reviewer_ids"])
+	}
+}
+
+func TestGitLabAPIError(t *testing.T) {
+	g, _ := fakeGitLab(t, map[string]string{})
+
+	err := g.ClosePR(3)
+	if err == nil {
+		t.Fatal("ClosePR() expected error, got nil")
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("ClosePR() error = %v, want APIError with status 404", err)
+	}
+}
+
+func TestGitLabCreatePRMilestone(t *testing.T) {
+	g, requests := fakeGitLab(t, map[string]string{
+		"GET " + gitlabProjectPath + "/milestones":        `[{"id":31,"title":"Sprint 4"}]`,
+		"POST " + gitlabProjectPath + "/merge_requests":   `{"id":900,"iid":12,"state":"opened"}`,
+		"PUT " + gitlabProjectPath + "/merge_requests/12": `{"iid":12}`,
+	})
+
+	if _, err := g.CreatePR(PRRequest{Base: "main", Head: "feature", Title: "Title", Milestone: "Sprint 4"}); err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	if got := (*requests)[1].Query; got != "state=active&title=Sprint+4" {
+		t.Errorf("milestone query = %q", got)
+	}
+	if got := (*requests)[2].Body["milestone_id"]; got != float64(31) {
+		t.Errorf("milestone_id = %v, want 31", got)
+	}
+}
+
+func TestGitLabProjectsNotSupported(t *testing.T) {
+	g, _ := fakeGitLab(t, map[string]string{})
+
+	err := CheckMetadata(g, PRRequest{Base: "main", Head: "feature", Title: "Title", Labels: []string{"chore"}, Projects: []string{"7"}})
+	if !errors.Is(err, ErrNotSupported) || err.Error() != "projects are not supported by this forge" {
+		t.Errorf("CheckMetadata() error = %v, want projects not supported", err)
+	}
+}
+
+func TestGitLabEnableAutoMerge(t *testing.T) {
+	g, requests := fakeGitLab(t, map[string]string{
+		"PUT " + gitlabProjectPath + "/merge_requests/12/merge": `{}`,
+	})
+
+	if err := g.EnableAutoMerge(&PullRequest{Number: 12}, MergeMethodSquash); err != nil {
+		t.Fatalf("EnableAutoMerge() error = %v", err)
+	}
+	want := map[string]any{"merge_when_pipeline_succeeds": true, "squash": true}
+	if got := (*requests)[0].Body; !reflect.DeepEqual(got, want) {
+		t.Errorf("body = %v, want %v", got, want)
+	}
+
+	if err := g.EnableAutoMerge(&PullRequest{Number: 12}, MergeMethodRebase); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("EnableAutoMerge(rebase) error = %v, want ErrNotSupported", err)
+	}
+}
+
+func TestGitLabPRStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     PRStatus
+	}{
+		{
+			name:     "ready",
+			response: `{"iid":12,"has_conflicts":false,"detailed_merge_status":"mergeable","head_pipeline":{"status":"success"}}`,
+			want:     PRStatus{Checks: ChecksPassing},
+		},
+		{
+			name:     "pipeline running",
+			response: `{"iid":12,"detailed_merge_status":"ci_still_running","head_pipeline":{"status":"running"}}`,
+			want:     PRStatus{Checks: ChecksPending},
+		},
+		{
+			name:     "still checking",
+			response: `{"iid":12,"detailed_merge_status":"checking","head_pipeline":null}`,
+			want:     PRStatus{Checks: ChecksPending},
+		},
+		{
+			name:     "blocked",
+			response: `{"iid":12,"has_conflicts":true,"detailed_merge_status":"not_approved","head_pipeline":{"status":"failed"}}`,
+			want:     PRStatus{Checks: ChecksFailing, Conflicts: true, ReviewRequired: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := fakeGitLab(t, map[string]string{
+				"GET " + gitlabProjectPath + "/merge_requests/12": tt.response,
+			})
+
+			status, err := g.PRStatus(&PullRequest{Number: 12})
+			if err != nil {
+				t.Fatalf("PRStatus() error = %v", err)
+			}
+			if *status != tt.want {
+				t.Errorf("PRStatus() = %+v, want %+v", *status, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitLabMergePR(t *testing.T) {
+	g, requests := fakeGitLab(t, map[string]string{
+		"PUT " + gitlabProjectPath + "/merge_requests/12/merge": `{}`,
+	})
+
+	if err := g.MergePR(&PullRequest{Number: 12}, MergeMethodSquash); err != nil {
+		t.Fatalf("MergePR() error = %v", err)
+	}
+	want := map[string]any{"squash": true}
+	if got := (*requests)[0].Body; !reflect.DeepEqual(got, want) {
+		t.Errorf("body = %v, want %v", got, want)
+	}
+
+	if err := g.MergePR(&PullRequest{Number: 12}, MergeMethodRebase); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("MergePR(rebase) error = %v, want ErrNotSupported", err)
+	}
+}
+
+func TestGitLabFindIssue(t *testing.T) {
+	g, requests := fakeGitLab(t, map[string]string{
+		"GET " + gitlabProjectPath + "/issues": `[{"iid":2,"web_url":"https://gitlab.com/x/2","title":"Rollout of CI"},{"iid":3,"web_url":"https://gitlab.com/x/3","title":"Rollout","description":"entries"}]`,
+	})
+
+	issue, err := g.FindIssue("Rollout")
+	if err != nil {
+		t.Fatalf("FindIssue() error = %v", err)
+	}
+	want := &Issue{Number: 3, URL: "https://gitlab.com/x/3", Title: "Rollout", Body: "entries"}
+	if !reflect.DeepEqual(issue, want) {
+		t.Errorf("FindIssue() = %+v, want %+v", issue, want)
+	}
+	if got := (*requests)[0].Query; got != "state=opened&in=title&per_page=100&search=Rollout" {
+		t.Errorf("query = %q", got)
+	}
+}
+
+func TestGitLabUpdateIssue(t *testing.T) {
+	g, requests := fakeGitLab(t, map[string]string{
+		"PUT " + gitlabProjectPath + "/issues/3": `{}`,
+	})
+
+	if err := g.UpdateIssue(3, "New body"); err != nil {
+		t.Fatalf("UpdateIssue() error = %v", err)
+	}
+	want := map[string]any{"description": "New body"}
+	if got := (*requests)[0].Body; !reflect.DeepEqual(got, want) {
+		t.Errorf("body = %v, want %v", got, want)
+	}
+}
+
+func TestGitLabRequiresSignedCommits(t *testing.T) {
+	g, _ := fakeGitLab(t, map[string]string{
+		"GET " + gitlabProjectPath + "/push_rule": `{"id":1,"reject_unsigned_commits":true}`,
+	})
+	if required, err := g.RequiresSignedCommits("main"); err != nil || !required {
+		t.Errorf("RequiresSignedCommits() = %v, %v, want true", required, err)
+	}
+
+	// Projects without push rules answer 404
+	g, _ = fakeGitLab(t, map[string]string{})
+	if required, err := g.RequiresSignedCommits("main"); err != nil || required {
+		t.Errorf("RequiresSignedCommits() without push rules = %v, %v, want false", required, err)
+	}
+}
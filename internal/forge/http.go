@@ -0,0 +1,108 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIError is returned when a forge REST API responds with a non-success status.
+type APIError struct {
+	// Method is the HTTP method of the failed request.
+	Method string
+	// URL is the URL of the failed request.
+	URL string
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Body is the response body, which usually contains the provider's error message.
+	Body string
+}
+
+// Error returns a description of the failed request.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s failed: %d %s: %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode), strings.TrimSpace(e.Body))
+}
+
+// isStatus reports whether err is an APIError with one of the status codes.
+func isStatus(err error, codes ...int) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range codes {
+		if apiErr.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// apiClient is a minimal JSON REST client shared by the forge implementations.
+type apiClient struct {
+	// baseURL is prepended to every request path.
+	baseURL string
+	// headers are set on every request, typically for authentication.
+	headers map[string]string
+	// http is the underlying HTTP client.
+	http *http.Client
+}
+
+// newAPIClient creates an apiClient for the given base URL.
+func newAPIClient(baseURL string, headers map[string]string) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		headers: headers,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// do sends a request with in encoded as the JSON body (if non-nil) and decodes
+// the JSON response into out (if non-nil).
+func (c *apiClient) do(method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	url := c.baseURL + path
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{Method: method, URL: url, StatusCode: resp.StatusCode, Body: string(data)}
+	}
+	if out != nil && len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode response from %s %s: %w", method, url, err)
+		}
+	}
+	return nil
+}
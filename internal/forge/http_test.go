@@ -0,0 +1,43 @@
+package forge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// apiRequest records a request received by the fake API server.
+type apiRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   map[string]any
+}
+
+// fakeAPI starts a server that records requests and answers them from
+// responses, keyed by "METHOD path". Unknown requests get a 404.
+func fakeAPI(t *testing.T, responses map[string]string) (*httptest.Server, *[]apiRequest) {
+	t.Helper()
+	requests := []apiRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := apiRequest{
+			Method: r.Method,
+			Path:   r.URL.EscapedPath(),
+			Query:  r.URL.RawQuery,
+			Header: r.Header,
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req.Body)
+		requests = append(requests, req)
+
+		response, ok := responses[r.Method+" "+req.Path]
+		if !ok {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
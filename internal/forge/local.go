@@ -0,0 +1,360 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/git"
+)
+
+// LocalName is the name of the local forge.
+const LocalName = "local"
+
+// localRecordDir is the record directory used inside a local bare remote.
+const localRecordDir = "bulkfilepr/prs"
+
+// LocalRecord is a pull request recorded on disk by the local forge.
+type LocalRecord struct {
+	Number    int       `json:"number"`
+	State     string    `json:"state"`
+	Base      string    `json:"base"`
+	Head      string    `json:"head"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Draft     bool      `json:"draft"`
+	CommitSHA string    `json:"commit_sha"`
+	Labels    []string  `json:"labels,omitempty"`
+	Assignees []string  `json:"assignees,omitempty"`
+	Reviewers []string  `json:"reviewers,omitempty"`
+	Milestone string    `json:"milestone,omitempty"`
+	Projects  []string  `json:"projects,omitempty"`
+	AutoMerge string    `json:"auto_merge,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Local implements Forge by writing pull requests as JSON records into a
+// directory, so the full apply flow can run without a hosting provider.
+type Local struct {
+	// Dir is the directory records are written to.
+	Dir string
+	// git resolves head commits in the repository.
+	git git.Operations
+	// now returns the current time and is replaceable in tests.
+	now func() time.Time
+}
+
+// NewLocal creates a local forge. Records are written to BaseURL if set,
+// otherwise to bulkfilepr/prs inside the remote when it is a local path.
+func NewLocal(opts Options) (*Local, error) {
+	dir := opts.BaseURL
+	if dir == "" {
+		remote, ok := localPath(opts.RemoteURL, opts.RepoDir)
+		if !ok {
+			return nil, fmt.Errorf("local forge needs a record directory: set --forge-url or use a remote on the local filesystem")
+		}
+		dir = filepath.Join(remote, localRecordDir)
+	}
+	gitOps := opts.Git
+	if gitOps == nil {
+		gitOps = git.NewRealOperations(opts.RepoDir)
+	}
+	return &Local{Dir: dir, git: gitOps, now: time.Now}, nil
+}
+
+// localPath returns the filesystem path of a remote URL that is a plain path
+// or a file:// URL. Relative paths are relative to the repository, as they
+// are for git.
+func localPath(remoteURL, repoDir string) (string, bool) {
+	if strings.HasPrefix(remoteURL, "file://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", false
+		}
+		return u.Path, true
+	}
+	if remoteURL == "" || strings.Contains(remoteURL, "://") {
+		return "", false
+	}
+	if !filepath.IsAbs(remoteURL) {
+		remoteURL = filepath.Join(repoDir, remoteURL)
+	}
+	if info, err := os.Stat(remoteURL); err == nil && info.IsDir() {
+		return remoteURL, true
+	}
+	return "", false
+}
+
+// Name returns the name used to select the forge.
+func (l *Local) Name() string {
+	return LocalName
+}
+
+// DefaultBranch is not supported, so detection falls back to git.
+func (l *Local) DefaultBranch() (string, error) {
+	return "", ErrNotSupported
+}
+
+// SupportsMetadata reports whether the kind of metadata is recorded. Work
+// items are not.
+func (l *Local) SupportsMetadata(kind Metadata) bool {
+	return kind != MetadataWorkItems
+}
+
+// CreatePR records a pull request for the head branch's current commit.
+func (l *Local) CreatePR(req PRRequest) (*PullRequest, error) {
+	sha, err := l.git.BranchCommit(req.Head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+	records, err := l.Records()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+	number := 1
+	if len(records) > 0 {
+		number = records[len(records)-1].Number + 1
+	}
+
+	record := &LocalRecord{
+		Number:    number,
+		State:     "open",
+		Base:      req.Base,
+		Head:      req.Head,
+		Title:     req.Title,
+		Body:      req.Body,
+		Draft:     req.Draft,
+		CommitSHA: sha,
+		Labels:    req.Labels,
+		Assignees: req.Assignees,
+		Reviewers: req.Reviewers,
+		Milestone: req.Milestone,
+		Projects:  req.Projects,
+		CreatedAt: l.now().UTC(),
+	}
+	if err := l.create(record); err != nil {
+		return nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+	return l.pullRequest(record), nil
+}
+
+// FindPR returns the open pull request for the head branch, or nil if there is none.
+func (l *Local) FindPR(head string) (*PullRequest, error) {
+	records, err := l.Records()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find PR for %s: %w", head, err)
+	}
+	for _, record := range records {
+		if record.Head == head && record.State == "open" {
+			return l.pullRequest(record), nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdatePR updates the title and body of a pull request.
+func (l *Local) UpdatePR(number int, title, body string) error {
+	return l.update(number, func(record *LocalRecord) {
+		record.Title = title
+		record.Body = body
+	})
+}
+
+// ClosePR marks a pull request as closed.
+func (l *Local) ClosePR(number int) error {
+	return l.update(number, func(record *LocalRecord) {
+		record.State = "closed"
+	})
+}
+
+// AddLabels adds labels to a pull request.
+func (l *Local) AddLabels(number int, labels []string) error {
+	return l.update(number, func(record *LocalRecord) {
+		record.Labels = append(record.Labels, labels...)
+	})
+}
+
+// RequestReviewers adds reviewers to a pull request.
+func (l *Local) RequestReviewers(number int, reviewers []string) error {
+	return l.update(number, func(record *LocalRecord) {
+		record.Reviewers = append(record.Reviewers, reviewers...)
+	})
+}
+
+// EnableAutoMerge records the merge method auto-merge was enabled with.
+func (l *Local) EnableAutoMerge(pr *PullRequest, method MergeMethod) error {
+	return l.update(pr.Number, func(record *LocalRecord) {
+		record.AutoMerge = string(method)
+	})
+}
+
+// PRStatus reports every recorded pull request as ready to merge, since
+// there are no checks or reviews.
+func (l *Local) PRStatus(pr *PullRequest) (*PRStatus, error) {
+	return &PRStatus{Checks: ChecksPassing}, nil
+}
+
+// MergePR marks a pull request as merged.
+func (l *Local) MergePR(pr *PullRequest, method MergeMethod) error {
+	return l.update(pr.Number, func(record *LocalRecord) {
+		record.State = "merged"
+	})
+}
+
+// ListPRs returns every recorded pull request, ordered by number.
+func (l *Local) ListPRs() ([]*PullRequest, error) {
+	records, err := l.Records()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PRs: %w", err)
+	}
+	prs := make([]*PullRequest, 0, len(records))
+	for _, record := range records {
+		prs = append(prs, l.pullRequest(record))
+	}
+	return prs, nil
+}
+
+// DeletePR removes the record of a pull request.
+func (l *Local) DeletePR(number int) error {
+	if err := os.Remove(l.recordPath(number)); err != nil {
+		return fmt.Errorf("failed to delete PR %d: %w", number, err)
+	}
+	return nil
+}
+
+// Records reads every record in the directory, ordered by number. A missing
+// directory has no records.
+func (l *Local) Records() ([]*LocalRecord, error) {
+	entries, err := os.ReadDir(l.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+
+	records := []*LocalRecord{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		record, err := l.read(filepath.Join(l.Dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Number < records[j].Number })
+	return records, nil
+}
+
+// update applies fn to a record and writes it back.
+func (l *Local) update(number int, fn func(record *LocalRecord)) error {
+	record, err := l.read(l.recordPath(number))
+	if err != nil {
+		return fmt.Errorf("failed to update PR %d: %w", number, err)
+	}
+	fn(record)
+	if err := l.write(record); err != nil {
+		return fmt.Errorf("failed to update PR %d: %w", number, err)
+	}
+	return nil
+}
+
+// read reads a record file.
+func (l *Local) read(path string) (*LocalRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read record: %w", err)
+	}
+	var record LocalRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse record %s: %w", path, err)
+	}
+	return &record, nil
+}
+
+// write writes a record file, creating the directory if needed.
+func (l *Local) write(record *LocalRecord) error {
+	if err := os.MkdirAll(l.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create record directory: %w", err)
+	}
+	data, err := encodeRecord(record)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(l.recordPath(record.Number), data, 0644); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	return nil
+}
+
+// create writes a new record file, moving on to the next number while the
+// record's number is taken. Record files are created exclusively, so
+// concurrent runs that counted the same records never share a number.
+func (l *Local) create(record *LocalRecord) error {
+	if err := os.MkdirAll(l.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create record directory: %w", err)
+	}
+	for {
+		file, err := os.OpenFile(l.recordPath(record.Number), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			record.Number++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+		data, err := encodeRecord(record)
+		if err == nil {
+			_, err = file.Write(data)
+		}
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(file.Name())
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+		return nil
+	}
+}
+
+// encodeRecord returns the contents of a record file.
+func encodeRecord(record *LocalRecord) ([]byte, error) {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode record: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// recordPath returns the path of a record file.
+func (l *Local) recordPath(number int) string {
+	return filepath.Join(l.Dir, strconv.Itoa(number)+".json")
+}
+
+// pullRequest converts a record into a PullRequest whose URL is the record file.
+func (l *Local) pullRequest(record *LocalRecord) *PullRequest {
+	path, err := filepath.Abs(l.recordPath(record.Number))
+	if err != nil {
+		path = l.recordPath(record.Number)
+	}
+	return &PullRequest{
+		Number: record.Number,
+		ID:     strconv.Itoa(record.Number),
+		URL:    "file://" + filepath.ToSlash(path),
+		State:  record.State,
+		Base:   record.Base,
+		Head:   record.Head,
+		Title:  record.Title,
+		Body:   record.Body,
+		Draft:  record.Draft,
+	}
+}
@@ -0,0 +1,165 @@
+package forge
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/git"
+)
+
+func TestNewLocal(t *testing.T) {
+	l, err := NewLocal(Options{BaseURL: "/tmp/records"})
+	if err != nil || l.Dir != "/tmp/records" {
+		t.Errorf("NewLocal() with BaseURL = %+v, %v, want Dir /tmp/records", l, err)
+	}
+
+	remote := t.TempDir()
+	l, err = NewLocal(Options{RemoteURL: "file://" + remote})
+	if err != nil || l.Dir != filepath.Join(remote, localRecordDir) {
+		t.Errorf("NewLocal() with local remote = %+v, %v, want Dir inside the remote", l, err)
+	}
+
+	// Relative remotes, as added by 'git remote add origin ../upstream.git',
+	// are resolved against the repository rather than the working directory
+	parent := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(parent, "upstream.git"), 0755); err != nil {
+		t.Fatalf("failed to create remote: %v", err)
+	}
+	repoDir := filepath.Join(parent, "repo")
+	l, err = NewLocal(Options{RepoDir: repoDir, RemoteURL: "../upstream.git"})
+	if err != nil || l.Dir != filepath.Join(parent, "upstream.git", localRecordDir) {
+		t.Errorf("NewLocal() with relative remote = %+v, %v, want Dir inside the remote", l, err)
+	}
+
+	if _, err := NewLocal(Options{RemoteURL: "git@github.com:owner/repo.git"}); err == nil {
+		t.Error("NewLocal() expected error for a network remote without BaseURL, got nil")
+	}
+}
+
+func TestLocalCreateAndFindPR(t *testing.T) {
+	mock := git.NewMockOperations()
+	sha := "0123456789abcdef0123456789abcdef01234567"
+	mock.BranchHeads["feature"] = sha
+	l, err := NewLocal(Options{Git: mock, BaseURL: filepath.Join(t.TempDir(), "prs")})
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	l.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	pr, err := l.CreatePR(PRRequest{Base: "main", Head: "feature", Title: "Title", Body: "Body", Draft: true})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	if pr.Number != 1 || pr.State != "open" || !strings.HasPrefix(pr.URL, "file://") {
+		t.Errorf("CreatePR() = %+v, want open PR 1 with a file URL", pr)
+	}
+
+	records, err := l.Records()
+	if err != nil || len(records) != 1 {
+		t.Fatalf("Records() = %v, %v, want one record", records, err)
+	}
+	record := records[0]
+	if record.CommitSHA != sha || record.Base != "main" || record.Head != "feature" || !record.Draft {
+		t.Errorf("record = %+v, want feature -> main at %s", record, sha)
+	}
+	if !record.CreatedAt.Equal(l.now()) {
+		t.Errorf("CreatedAt = %v, want %v", record.CreatedAt, l.now())
+	}
+
+	found, err := l.FindPR("feature")
+	if err != nil || found == nil || found.Number != 1 {
+		t.Errorf("FindPR() = %+v, %v, want PR 1", found, err)
+	}
+
+	second, err := l.CreatePR(PRRequest{Base: "main", Head: "feature", Title: "Again"})
+	if err != nil || second.Number != 2 {
+		t.Errorf("CreatePR() second = %+v, %v, want PR 2", second, err)
+	}
+}
+
+func TestLocalCreatePRUnknownBranch(t *testing.T) {
+	l, _ := NewLocal(Options{Git: git.NewMockOperations(), BaseURL: t.TempDir()})
+
+	if _, err := l.CreatePR(PRRequest{Base: "main", Head: "missing"}); err == nil {
+		t.Error("CreatePR() expected error for missing branch, got nil")
+	}
+}
+
+func TestLocalCreateTakenNumber(t *testing.T) {
+	dir := t.TempDir()
+	l, _ := NewLocal(Options{BaseURL: dir})
+	if err := l.write(&LocalRecord{Number: 1, State: "open", Head: "other"}); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+
+	// A concurrent run that counted the same records picks the same number
+	record := &LocalRecord{Number: 1, State: "open", Head: "feature"}
+	if err := l.create(record); err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+	if record.Number != 2 {
+		t.Errorf("Number = %d, want 2", record.Number)
+	}
+
+	records, err := l.Records()
+	if err != nil || len(records) != 2 || records[0].Head != "other" || records[1].Head != "feature" || records[1].Number != 2 {
+		t.Errorf("Records() = %+v, %v, want both PRs kept", records, err)
+	}
+}
+
+func TestLocalClosePRAndDeletePR(t *testing.T) {
+	dir := t.TempDir()
+	l, _ := NewLocal(Options{BaseURL: dir})
+	if err := l.write(&LocalRecord{Number: 3, State: "open", Head: "feature"}); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+
+	if err := l.ClosePR(3); err != nil {
+		t.Fatalf("ClosePR() error = %v", err)
+	}
+	found, err := l.FindPR("feature")
+	if err != nil || found != nil {
+		t.Errorf("FindPR() after close = %+v, %v, want nil", found, err)
+	}
+
+	if err := l.DeletePR(3); err != nil {
+		t.Fatalf("DeletePR() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "3.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("record still exists after DeletePR(): %v", err)
+	}
+}
+
+func TestLocalEnableAutoMerge(t *testing.T) {
+	l, _ := NewLocal(Options{BaseURL: t.TempDir()})
+	if err := l.write(&LocalRecord{Number: 1, State: "open", Head: "feature"}); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+
+	if err := l.EnableAutoMerge(&PullRequest{Number: 1}, MergeMethodSquash); err != nil {
+		t.Fatalf("EnableAutoMerge() error = %v", err)
+	}
+	records, err := l.Records()
+	if err != nil || len(records) != 1 || records[0].AutoMerge != "squash" {
+		t.Errorf("Records() = %+v, %v, want auto_merge squash", records, err)
+	}
+}
+
+func TestLocalMergePR(t *testing.T) {
+	l, _ := NewLocal(Options{BaseURL: t.TempDir()})
+	if err := l.write(&LocalRecord{Number: 1, State: "open", Head: "feature"}); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+
+	if err := l.MergePR(&PullRequest{Number: 1}, MergeMethodMerge); err != nil {
+		t.Fatalf("MergePR() error = %v", err)
+	}
+	pr, err := l.FindPR("feature")
+	if err != nil || pr != nil {
+		t.Errorf("FindPR() = %+v, %v, want no open PR after merge", pr, err)
+	}
+}
@@ -0,0 +1,247 @@
+package forge
+
+import "fmt"
+
+// MockForge is a mock implementation of Forge for testing.
+type MockForge struct {
+	DefaultBranchName string
+	CreatedPRs        []PRRequest
+	PRURLToReturn     string
+	OpenPRs           map[string]*PullRequest // Map of head branches to open PRs
+	UpdatedPRs        []int
+	ClosedPRs         []int
+	Labels            map[int][]string
+	Reviewers         map[int][]string
+	AutoMerged        map[int]MergeMethod // Map of PR numbers to auto-merge methods
+	Statuses          []*PRStatus         // Returned by successive PRStatus calls, repeating the last
+	MergedPRs         map[int]MergeMethod // Map of PR numbers to merge methods
+	StatusCalls       int
+	Issues            []*Issue // Issues created or updated, in order of creation
+	Fork              *Fork
+	MetadataErrs      []error // Returned as a *MetadataError alongside created PRs
+	ForkCalls         int
+	SignedBranches    map[string]bool // Branches that require signed commits
+	Unsupported       []Metadata      // Metadata kinds SupportsMetadata rejects
+
+	// Error fields for simulating failures
+	DefaultBranchErr    error
+	CreatePRErr         error
+	FindPRErr           error
+	UpdatePRErr         error
+	ClosePRErr          error
+	AddLabelsErr        error
+	RequestReviewersErr error
+	EnsureForkErr       error
+	EnableAutoMergeErr  error
+	PRStatusErr         error
+	MergePRErr          error
+	FindIssueErr        error
+	CreateIssueErr      error
+	UpdateIssueErr      error
+	ProtectionErr       error
+}
+
+// NewMockForge creates a new MockForge with default successful behavior.
+func NewMockForge() *MockForge {
+	return &MockForge{
+		DefaultBranchName: "main",
+		PRURLToReturn:     "https://github.com/owner/repo/pull/1",
+		OpenPRs:           make(map[string]*PullRequest),
+		Labels:            make(map[int][]string),
+		Reviewers:         make(map[int][]string),
+		AutoMerged:        make(map[int]MergeMethod),
+		MergedPRs:         make(map[int]MergeMethod),
+		Fork:              &Fork{Owner: "me", CloneURL: "git@github.com:me/repo.git"},
+	}
+}
+
+// Name returns the mock forge name.
+func (m *MockForge) Name() string {
+	return "mock"
+}
+
+// SupportsMetadata reports whether the kind is not in Unsupported.
+func (m *MockForge) SupportsMetadata(kind Metadata) bool {
+	for _, unsupported := range m.Unsupported {
+		if unsupported == kind {
+			return false
+		}
+	}
+	return true
+}
+
+// EnableAutoMerge records the auto-merge method for the PR.
+func (m *MockForge) EnableAutoMerge(pr *PullRequest, method MergeMethod) error {
+	if m.EnableAutoMergeErr != nil {
+		return m.EnableAutoMergeErr
+	}
+	m.AutoMerged[pr.Number] = method
+	return nil
+}
+
+// PRStatus returns the next mock status, or passing checks if none are set.
+func (m *MockForge) PRStatus(pr *PullRequest) (*PRStatus, error) {
+	if m.PRStatusErr != nil {
+		return nil, m.PRStatusErr
+	}
+	m.StatusCalls++
+	if len(m.Statuses) == 0 {
+		return &PRStatus{Checks: ChecksPassing}, nil
+	}
+	status := m.Statuses[0]
+	if len(m.Statuses) > 1 {
+		m.Statuses = m.Statuses[1:]
+	}
+	return status, nil
+}
+
+// MergePR records the merge method for the PR.
+func (m *MockForge) MergePR(pr *PullRequest, method MergeMethod) error {
+	if m.MergePRErr != nil {
+		return m.MergePRErr
+	}
+	m.MergedPRs[pr.Number] = method
+	return nil
+}
+
+// RequiresSignedCommits reports whether the branch is in SignedBranches.
+func (m *MockForge) RequiresSignedCommits(branch string) (bool, error) {
+	if m.ProtectionErr != nil {
+		return false, m.ProtectionErr
+	}
+	return m.SignedBranches[branch], nil
+}
+
+// FindIssue returns the mock issue with the title.
+func (m *MockForge) FindIssue(title string) (*Issue, error) {
+	if m.FindIssueErr != nil {
+		return nil, m.FindIssueErr
+	}
+	for _, issue := range m.Issues {
+		if issue.Title == title {
+			copied := *issue
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateIssue records the issue creation.
+func (m *MockForge) CreateIssue(title, body string) (*Issue, error) {
+	if m.CreateIssueErr != nil {
+		return nil, m.CreateIssueErr
+	}
+	number := len(m.Issues) + 1
+	issue := &Issue{Number: number, URL: fmt.Sprintf("https://github.com/owner/tracking/issues/%d", number), Title: title, Body: body}
+	m.Issues = append(m.Issues, issue)
+	copied := *issue
+	return &copied, nil
+}
+
+// UpdateIssue records the new issue body.
+func (m *MockForge) UpdateIssue(number int, body string) error {
+	if m.UpdateIssueErr != nil {
+		return m.UpdateIssueErr
+	}
+	for _, issue := range m.Issues {
+		if issue.Number == number {
+			issue.Body = body
+			return nil
+		}
+	}
+	return fmt.Errorf("issue #%d not found", number)
+}
+
+// EnsureFork returns the mock fork and counts the call.
+func (m *MockForge) EnsureFork() (*Fork, error) {
+	if m.EnsureForkErr != nil {
+		return nil, m.EnsureForkErr
+	}
+	m.ForkCalls++
+	return m.Fork, nil
+}
+
+// DefaultBranch returns the mock default branch.
+func (m *MockForge) DefaultBranch() (string, error) {
+	if m.DefaultBranchErr != nil {
+		return "", m.DefaultBranchErr
+	}
+	return m.DefaultBranchName, nil
+}
+
+// CreatePR records the PR creation.
+func (m *MockForge) CreatePR(req PRRequest) (*PullRequest, error) {
+	if m.CreatePRErr != nil {
+		return nil, m.CreatePRErr
+	}
+	m.CreatedPRs = append(m.CreatedPRs, req)
+	pr := &PullRequest{
+		Number: len(m.CreatedPRs),
+		URL:    m.PRURLToReturn,
+		State:  "open",
+		Base:   req.Base,
+		Head:   req.Head,
+		Title:  req.Title,
+		Body:   req.Body,
+		Draft:  req.Draft,
+	}
+	m.OpenPRs[req.Head] = pr
+	return pr, metadataError(pr, m.MetadataErrs)
+}
+
+// FindPR returns the mock open PR for the head branch.
+func (m *MockForge) FindPR(head string) (*PullRequest, error) {
+	if m.FindPRErr != nil {
+		return nil, m.FindPRErr
+	}
+	return m.OpenPRs[head], nil
+}
+
+// UpdatePR records the PR update.
+func (m *MockForge) UpdatePR(number int, title, body string) error {
+	if m.UpdatePRErr != nil {
+		return m.UpdatePRErr
+	}
+	m.UpdatedPRs = append(m.UpdatedPRs, number)
+	return nil
+}
+
+// ClosePR records the PR being closed.
+func (m *MockForge) ClosePR(number int) error {
+	if m.ClosePRErr != nil {
+		return m.ClosePRErr
+	}
+	for head, pr := range m.OpenPRs {
+		if pr.Number == number {
+			delete(m.OpenPRs, head)
+		}
+	}
+	m.ClosedPRs = append(m.ClosedPRs, number)
+	return nil
+}
+
+// AddLabels records the labels added.
+func (m *MockForge) AddLabels(number int, labels []string) error {
+	if m.AddLabelsErr != nil {
+		return m.AddLabelsErr
+	}
+	m.Labels[number] = append(m.Labels[number], labels...)
+	return nil
+}
+
+// RequestReviewers records the reviewers requested.
+func (m *MockForge) RequestReviewers(number int, reviewers []string) error {
+	if m.RequestReviewersErr != nil {
+		return m.RequestReviewersErr
+	}
+	m.Reviewers[number] = append(m.Reviewers[number], reviewers...)
+	return nil
+}
+
+// Validate checks that exactly the expected number of PRs were created.
+func (m *MockForge) Validate(expectedPRs int) error {
+	if len(m.CreatedPRs) != expectedPRs {
+		return fmt.Errorf("expected %d PRs, got %d", expectedPRs, len(m.CreatedPRs))
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package forge
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// RemoteInfo describes the repository a git remote URL points at.
+type RemoteInfo struct {
+	// Host is the host name of the remote, without any port.
+	Host string
+	// Path is the repository path on the host, without leading slash or .git suffix
+	// (e.g. "group/subgroup/repo").
+	Path string
+}
+
+// ParseRemoteURL parses a git remote URL in URL form (https://host/path.git,
+// ssh://git@host:22/path.git) or scp-like form (git@host:path.git).
+func ParseRemoteURL(remoteURL string) (RemoteInfo, error) {
+	remoteURL = strings.TrimSpace(remoteURL)
+	if remoteURL == "" {
+		return RemoteInfo{}, fmt.Errorf("remote URL is empty")
+	}
+
+	var host, path string
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return RemoteInfo{}, fmt.Errorf("invalid remote URL %q: %w", remoteURL, err)
+		}
+		host = u.Hostname()
+		path = u.Path
+	} else {
+		// scp-like syntax: [user@]host:path
+		at := strings.Index(remoteURL, "@")
+		colon := strings.Index(remoteURL, ":")
+		if colon < 0 || colon < at {
+			return RemoteInfo{}, fmt.Errorf("unsupported remote URL %q", remoteURL)
+		}
+		host = remoteURL[at+1 : colon]
+		path = remoteURL[colon+1:]
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return RemoteInfo{}, fmt.Errorf("unsupported remote URL %q", remoteURL)
+	}
+	return RemoteInfo{Host: strings.ToLower(host), Path: path}, nil
+}
+
+// OwnerRepo splits the path into its owner (everything before the last
+// segment) and repository name.
+func (r RemoteInfo) OwnerRepo() (string, string, error) {
+	i := strings.LastIndex(r.Path, "/")
+	if i <= 0 || i == len(r.Path)-1 {
+		return "", "", fmt.Errorf("remote path %q is not in owner/repo form", r.Path)
+	}
+	return r.Path[:i], r.Path[i+1:], nil
+}
+
+// cloneURLFor picks the clone URL that uses the same protocol as the remote
+// URL, so pushes to a fork authenticate the same way as the upstream remote.
+func cloneURLFor(remoteURL, httpsURL, sshURL string) string {
+	lower := strings.ToLower(remoteURL)
+	if strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://") || sshURL == "" {
+		return httpsURL
+	}
+	return sshURL
+}
@@ -0,0 +1,48 @@
+package forge
+
+import "testing"
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    RemoteInfo
+		expectError bool
+	}{
+		{name: "https", input: "https://github.com/owner/repo.git", expected: RemoteInfo{Host: "github.com", Path: "owner/repo"}},
+		{name: "https without suffix", input: "https://gitlab.com/group/sub/repo", expected: RemoteInfo{Host: "gitlab.com", Path: "group/sub/repo"}},
+		{name: "ssh url with port", input: "ssh://git@Git.Example.com:2222/team/repo.git", expected: RemoteInfo{Host: "git.example.com", Path: "team/repo"}},
+		{name: "scp-like", input: "git@gitlab.example.com:group/repo.git", expected: RemoteInfo{Host: "gitlab.example.com", Path: "group/repo"}},
+		{name: "empty", input: "", expectError: true},
+		{name: "local path", input: "/srv/git/repo.git", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseRemoteURL(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseRemoteURL(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRemoteURL(%q) unexpected error: %v", tt.input, err)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseRemoteURL(%q) = %+v, want %+v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRemoteInfoOwnerRepo(t *testing.T) {
+	owner, repo, err := RemoteInfo{Host: "gitlab.com", Path: "group/sub/repo"}.OwnerRepo()
+	if err != nil || owner != "group/sub" || repo != "repo" {
+		t.Errorf("OwnerRepo() = %q, %q, %v, want %q, %q", owner, repo, err, "group/sub", "repo")
+	}
+
+	if _, _, err := (RemoteInfo{Host: "example.com", Path: "repo"}).OwnerRepo(); err == nil {
+		t.Error("OwnerRepo() expected error for single segment path, got nil")
+	}
+}
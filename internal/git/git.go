@@ -1,39 +1,160 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
-// Operations defines the interface for git and GitHub CLI operations.
+// Operations defines the interface for local git operations. Operations on
+// the hosting provider live in the forge package.
 // This allows for mocking in tests.
 type Operations interface {
-	// GetDefaultBranch returns the default branch name for the repository.
-	GetDefaultBranch() (string, error)
+	// GetDefaultBranch returns the default branch name for the repository and
+	// the source it was determined from.
+	GetDefaultBranch(remote string) (string, DefaultBranchSource, error)
 	// GetCurrentBranch returns the current branch name.
 	GetCurrentBranch() (string, error)
 	// IsWorkingTreeClean checks if the working tree is clean (no uncommitted changes).
 	IsWorkingTreeClean() (bool, error)
 	// BranchExists checks if a branch exists locally or on remote.
 	BranchExists(name, remote string) (bool, error)
+	// ListRemoteBranches returns the branch names known for the specified remote.
+	ListRemoteBranches(remote string) ([]string, error)
+	// RemoteURL returns the URL configured for the specified remote.
+	RemoteURL(remote string) (string, error)
+	// AddRemote adds a remote with the specified name and URL.
+	AddRemote(name, url string) error
 	// CreateBranch creates and switches to a new branch.
 	CreateBranch(name string) error
+	// DeleteBranch force-deletes a local branch.
+	DeleteBranch(name string) error
 	// SwitchBranch switches to an existing branch.
 	SwitchBranch(name string) error
 	// AddFile stages a file for commit.
 	AddFile(path string) error
-	// Commit commits staged changes with the given message.
-	Commit(message string) error
+	// Commit commits staged changes with the given message, appending the
+	// options' trailers and using their identity and signing settings.
+	Commit(message string, opts CommitOptions) error
+	// CheckIdentity returns ErrNoIdentity if git has no author or committer
+	// identity to commit with using the options.
+	CheckIdentity(opts CommitOptions) error
+	// CommitSigningEnabled reports whether git is configured to sign every
+	// commit (commit.gpgsign).
+	CommitSigningEnabled() (bool, error)
 	// Push pushes the current branch to the specified remote.
 	Push(remote, branch string) error
-	// CreatePR creates a pull request using GitHub CLI.
-	CreatePR(base, head, title, body string, draft bool) (string, error)
+	// PushWithLease pushes the current branch to the specified remote only if
+	// the remote branch is still at expectedSHA.
+	PushWithLease(remote, branch, expectedSHA string) error
+	// PushRefspec pushes the refspec to the specified remote and returns the
+	// push output, which some servers use to report what they created.
+	PushRefspec(remote, refspec string) (string, error)
+	// HeadCommit returns the commit SHA of HEAD.
+	HeadCommit() (string, error)
+	// BranchCommit returns the commit SHA a local branch points at.
+	BranchCommit(name string) (string, error)
+	// RemoteBranchHead returns the commit SHA of a branch on the remote, or an
+	// empty string if the branch does not exist there.
+	RemoteBranchHead(remote, branch string) (string, error)
+	// Stash stashes local changes, including untracked files, with the given message.
+	Stash(message string) error
+	// StashPop applies and drops the most recent stash.
+	StashPop() error
+	// StashedUntrackedFiles returns the untracked files saved in the most
+	// recent stash.
+	StashedUntrackedFiles() ([]string, error)
+	// ResetHard resets the current branch and working tree to the given revision.
+	ResetHard(rev string) error
+	// CleanFiles removes those of the given files that are untracked.
+	CleanFiles(paths []string) error
+	// SetBranchConfig sets a git config value in the branch's config section.
+	SetBranchConfig(branch, key, value string) error
+	// GetBranchConfig returns a git config value from the branch's config
+	// section, or an empty string if it is not set.
+	GetBranchConfig(branch, key string) (string, error)
+	// UnsetBranchConfig removes a git config value from the branch's config section.
+	UnsetBranchConfig(branch, key string) error
+	// ListBranchesWithConfig returns the local branches that have the given
+	// key set in their config section.
+	ListBranchesWithConfig(key string) ([]string, error)
+	// FormatPatch returns the commits in base..head as an mbox, preceded by a
+	// cover letter taken from head's branch description.
+	FormatPatch(base, head string) (string, error)
 }
 
-// RealOperations implements Operations using actual git and gh commands.
+// DefaultBranchSource identifies how the default branch was determined.
+type DefaultBranchSource string
+
+const (
+	// SourceSymbolicRef means the default branch was read from the locally
+	// cached refs/remotes/<remote>/HEAD.
+	SourceSymbolicRef DefaultBranchSource = "symbolic-ref"
+	// SourceLsRemote means the default branch was read from the remote's HEAD
+	// using `git ls-remote --symref`.
+	SourceLsRemote DefaultBranchSource = "ls-remote"
+	// SourceOverride means the default branch was provided by the user.
+	SourceOverride DefaultBranchSource = "override"
+)
+
+// Trailer is a git trailer, a "Key: value" line at the end of a commit message.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// String returns the trailer as it appears in a commit message.
+func (t Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+// ErrNoIdentity is returned when git has no name or email to commit with.
+var ErrNoIdentity = errors.New("no git identity to commit with")
+
+// CommitOptions are the extra settings for a commit.
+type CommitOptions struct {
+	// Trailers are appended to the message's trailer block, in order.
+	Trailers []Trailer
+	// Signoff adds a Signed-off-by trailer for the committer, as the
+	// Developer Certificate of Origin requires.
+	Signoff bool
+	// AuthorName and AuthorEmail override git's author identity when set.
+	AuthorName  string
+	AuthorEmail string
+	// CommitterName and CommitterEmail override git's committer identity when set.
+	CommitterName  string
+	CommitterEmail string
+	// Sign signs the commit with SigningKey, or with git's user.signingkey if
+	// SigningKey is empty.
+	Sign bool
+	// SigningKey is the key to sign with: a GPG key ID, or an SSH key path or
+	// "key::" literal when SigningFormat is ssh.
+	SigningKey string
+	// SigningFormat overrides git's gpg.format: openpgp, ssh or x509.
+	SigningFormat string
+}
+
+// env returns the environment variables that set the options' identity.
+func (o CommitOptions) env() []string {
+	var env []string
+	for _, v := range []struct{ name, value string }{
+		{"GIT_AUTHOR_NAME", o.AuthorName},
+		{"GIT_AUTHOR_EMAIL", o.AuthorEmail},
+		{"GIT_COMMITTER_NAME", o.CommitterName},
+		{"GIT_COMMITTER_EMAIL", o.CommitterEmail},
+	} {
+		if v.value != "" {
+			env = append(env, v.name+"="+v.value)
+		}
+	}
+	return env
+}
+
+// RealOperations implements Operations using actual git commands.
 type RealOperations struct {
 	// RepoDir is the repository directory to operate on.
 	RepoDir string
@@ -46,8 +167,17 @@ func NewRealOperations(repoDir string) *RealOperations {
 
 // runGit runs a git command in the repository directory.
 func (r *RealOperations) runGit(args ...string) (string, error) {
+	return r.runGitEnv(nil, args...)
+}
+
+// runGitEnv runs a git command in the repository directory with extra
+// environment variables.
+func (r *RealOperations) runGitEnv(env []string, args ...string) (string, error) {
 	cmd := exec.Command("git", args...)
 	cmd.Dir = r.RepoDir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return string(output), fmt.Errorf("git %s failed: %w\nOutput: %s", strings.Join(args, " "), err, output)
@@ -55,27 +185,55 @@ func (r *RealOperations) runGit(args ...string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-// runGH runs a gh command in the repository directory.
-func (r *RealOperations) runGH(args ...string) (string, error) {
-	cmd := exec.Command("gh", args...)
-	cmd.Dir = r.RepoDir
-	output, err := cmd.CombinedOutput()
+// GetDefaultBranch returns the default branch name using git alone. It reads
+// the locally cached remote HEAD first, then queries the remote's HEAD directly.
+func (r *RealOperations) GetDefaultBranch(remote string) (string, DefaultBranchSource, error) {
+	detectors := []struct {
+		source DefaultBranchSource
+		detect func(remote string) (string, error)
+	}{
+		{SourceSymbolicRef, r.defaultBranchFromSymbolicRef},
+		{SourceLsRemote, r.defaultBranchFromLsRemote},
+	}
+
+	errs := make([]error, 0, len(detectors))
+	for _, d := range detectors {
+		name, err := d.detect(remote)
+		if err == nil && name != "" {
+			return name, d.source, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("empty response")
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", d.source, err))
+	}
+	return "", "", fmt.Errorf("failed to get default branch: %w", errors.Join(errs...))
+}
+
+// defaultBranchFromSymbolicRef returns the default branch from the locally
+// cached refs/remotes/<remote>/HEAD, which is set by git clone.
+func (r *RealOperations) defaultBranchFromSymbolicRef(remote string) (string, error) {
+	output, err := r.runGit("symbolic-ref", "--short", fmt.Sprintf("refs/remotes/%s/HEAD", remote))
 	if err != nil {
-		return string(output), fmt.Errorf("gh %s failed: %w\nOutput: %s", strings.Join(args, " "), err, output)
+		return "", err
 	}
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimPrefix(output, remote+"/"), nil
 }
 
-// GetDefaultBranch returns the default branch name using GitHub CLI.
-func (r *RealOperations) GetDefaultBranch() (string, error) {
-	output, err := r.runGH("repo", "view", "--json", "defaultBranchRef", "--jq", ".defaultBranchRef.name")
+// defaultBranchFromLsRemote returns the branch the remote's HEAD points to.
+func (r *RealOperations) defaultBranchFromLsRemote(remote string) (string, error) {
+	output, err := r.runGit("ls-remote", "--symref", remote, "HEAD")
 	if err != nil {
-		return "", fmt.Errorf("failed to get default branch: %w", err)
+		return "", err
 	}
-	if output == "" {
-		return "", fmt.Errorf("failed to get default branch: empty response")
+	// The symref line looks like "ref: refs/heads/main\tHEAD"
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "ref:" {
+			return strings.TrimPrefix(fields[1], "refs/heads/"), nil
+		}
 	}
-	return output, nil
+	return "", nil
 }
 
 // GetCurrentBranch returns the current branch name.
@@ -117,6 +275,44 @@ func (r *RealOperations) BranchExists(name, remote string) (bool, error) {
 	return false, nil
 }
 
+// ListRemoteBranches returns the branch names known for the specified remote.
+// Like BranchExists, this uses locally cached remote refs and does not fetch.
+func (r *RealOperations) ListRemoteBranches(remote string) ([]string, error) {
+	prefix := fmt.Sprintf("refs/remotes/%s/", remote)
+	output, err := r.runGit("for-each-ref", "--format=%(refname)", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches for remote %s: %w", remote, err)
+	}
+
+	branches := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		name := strings.TrimPrefix(strings.TrimSpace(line), prefix)
+		if name == "" || name == "HEAD" {
+			continue
+		}
+		branches = append(branches, name)
+	}
+	return branches, nil
+}
+
+// RemoteURL returns the URL configured for the specified remote.
+func (r *RealOperations) RemoteURL(remote string) (string, error) {
+	output, err := r.runGit("remote", "get-url", remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to get URL of remote %s: %w", remote, err)
+	}
+	return output, nil
+}
+
+// AddRemote adds a remote with the specified name and URL.
+func (r *RealOperations) AddRemote(name, url string) error {
+	_, err := r.runGit("remote", "add", name, url)
+	if err != nil {
+		return fmt.Errorf("failed to add remote %s: %w", name, err)
+	}
+	return nil
+}
+
 // CreateBranch creates and switches to a new branch.
 func (r *RealOperations) CreateBranch(name string) error {
 	_, err := r.runGit("checkout", "-b", name)
@@ -126,6 +322,15 @@ func (r *RealOperations) CreateBranch(name string) error {
 	return nil
 }
 
+// DeleteBranch force-deletes a local branch.
+func (r *RealOperations) DeleteBranch(name string) error {
+	_, err := r.runGit("branch", "-D", name)
+	if err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w", name, err)
+	}
+	return nil
+}
+
 // SwitchBranch switches to an existing branch.
 func (r *RealOperations) SwitchBranch(name string) error {
 	_, err := r.runGit("checkout", name)
@@ -144,15 +349,62 @@ func (r *RealOperations) AddFile(path string) error {
 	return nil
 }
 
-// Commit commits staged changes with the given message.
-func (r *RealOperations) Commit(message string) error {
-	_, err := r.runGit("commit", "-m", message)
+// Commit commits staged changes with the given message and options.
+func (r *RealOperations) Commit(message string, opts CommitOptions) error {
+	var args []string
+	if opts.SigningFormat != "" {
+		args = append(args, "-c", "gpg.format="+opts.SigningFormat)
+	}
+	args = append(args, "commit", "-m", message)
+	// git commit --trailer merges the trailers into an existing trailer block,
+	// such as a Change-Id, instead of starting a new paragraph
+	for _, trailer := range opts.Trailers {
+		args = append(args, "--trailer", trailer.String())
+	}
+	if opts.Signoff {
+		args = append(args, "--signoff")
+	}
+	switch {
+	case opts.SigningKey != "":
+		args = append(args, "--gpg-sign="+opts.SigningKey)
+	case opts.Sign:
+		args = append(args, "--gpg-sign")
+	}
+	_, err := r.runGitEnv(opts.env(), args...)
 	if err != nil {
 		return fmt.Errorf("failed to commit: %w", err)
 	}
 	return nil
 }
 
+// CheckIdentity returns ErrNoIdentity if git has no author or committer
+// identity to commit with using the options. git var fails in the same cases
+// git commit would.
+func (r *RealOperations) CheckIdentity(opts CommitOptions) error {
+	for _, role := range []string{"author", "committer"} {
+		if _, err := r.runGitEnv(opts.env(), "var", "GIT_"+strings.ToUpper(role)+"_IDENT"); err != nil {
+			return fmt.Errorf("%w: the %s name or email is not set", ErrNoIdentity, role)
+		}
+	}
+	return nil
+}
+
+// CommitSigningEnabled reports whether git is configured to sign every commit
+// (commit.gpgsign).
+func (r *RealOperations) CommitSigningEnabled() (bool, error) {
+	cmd := exec.Command("git", "config", "--type=bool", "--get", "commit.gpgsign")
+	cmd.Dir = r.RepoDir
+	output, err := cmd.Output()
+	if err != nil {
+		// git config exits with status 1 when the key is not set
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read commit.gpgsign: %w", err)
+	}
+	return strings.TrimSpace(string(output)) == "true", nil
+}
+
 // Push pushes the current branch to the specified remote.
 func (r *RealOperations) Push(remote, branch string) error {
 	_, err := r.runGit("push", "-u", remote, branch)
@@ -162,19 +414,193 @@ func (r *RealOperations) Push(remote, branch string) error {
 	return nil
 }
 
-// CreatePR creates a pull request using GitHub CLI.
-func (r *RealOperations) CreatePR(base, head, title, body string, draft bool) (string, error) {
-	args := []string{"pr", "create", "--base", base, "--head", head, "--title", title, "--body", body}
-	if draft {
-		args = append(args, "--draft")
+// PushWithLease pushes the current branch to the specified remote only if the
+// remote branch is still at expectedSHA.
+func (r *RealOperations) PushWithLease(remote, branch, expectedSHA string) error {
+	lease := fmt.Sprintf("--force-with-lease=refs/heads/%s:%s", branch, expectedSHA)
+	_, err := r.runGit("push", lease, remote, fmt.Sprintf("HEAD:refs/heads/%s", branch))
+	if err != nil {
+		return fmt.Errorf("failed to push to %s/%s: %w", remote, branch, err)
 	}
-	output, err := r.runGH(args...)
+	return nil
+}
+
+// PushRefspec pushes the refspec to the specified remote and returns the push output.
+func (r *RealOperations) PushRefspec(remote, refspec string) (string, error) {
+	output, err := r.runGit("push", remote, refspec)
 	if err != nil {
-		return "", fmt.Errorf("failed to create PR: %w", err)
+		return "", fmt.Errorf("failed to push %s to %s: %w", refspec, remote, err)
 	}
 	return output, nil
 }
 
+// HeadCommit returns the commit SHA of HEAD.
+func (r *RealOperations) HeadCommit() (string, error) {
+	output, err := r.runGit("rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+	return output, nil
+}
+
+// BranchCommit returns the commit SHA a local branch points at.
+func (r *RealOperations) BranchCommit(name string) (string, error) {
+	output, err := r.runGit("rev-parse", "--verify", fmt.Sprintf("refs/heads/%s^{commit}", name))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve branch %s: %w", name, err)
+	}
+	return output, nil
+}
+
+// RemoteBranchHead returns the commit SHA of a branch on the remote, or an
+// empty string if the branch does not exist there. Unlike BranchExists, this
+// queries the remote directly.
+func (r *RealOperations) RemoteBranchHead(remote, branch string) (string, error) {
+	output, err := r.runGit("ls-remote", remote, fmt.Sprintf("refs/heads/%s", branch))
+	if err != nil {
+		return "", fmt.Errorf("failed to query %s/%s: %w", remote, branch, err)
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return fields[0], nil
+}
+
+// Stash stashes local changes, including untracked files, with the given message.
+func (r *RealOperations) Stash(message string) error {
+	_, err := r.runGit("stash", "push", "--include-untracked", "-m", message)
+	if err != nil {
+		return fmt.Errorf("failed to stash changes: %w", err)
+	}
+	return nil
+}
+
+// StashPop applies and drops the most recent stash. If applying conflicts, git
+// keeps the stash entry.
+func (r *RealOperations) StashPop() error {
+	_, err := r.runGit("stash", "pop")
+	if err != nil {
+		return fmt.Errorf("failed to pop stash: %w", err)
+	}
+	return nil
+}
+
+// StashedUntrackedFiles returns the untracked files saved in the most recent
+// stash. They are kept in the stash commit's third parent, which only exists
+// when there were any.
+func (r *RealOperations) StashedUntrackedFiles() ([]string, error) {
+	if _, err := r.runGit("rev-parse", "--quiet", "--verify", "stash@{0}^3"); err != nil {
+		return nil, nil
+	}
+	output, err := r.runGit("ls-tree", "-r", "-z", "--name-only", "stash@{0}^3")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stashed untracked files: %w", err)
+	}
+	output = strings.TrimRight(output, "\x00")
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(output, "\x00"), nil
+}
+
+// ResetHard resets the current branch and working tree to the given revision.
+func (r *RealOperations) ResetHard(rev string) error {
+	_, err := r.runGit("reset", "--hard", rev)
+	if err != nil {
+		return fmt.Errorf("failed to reset to %s: %w", rev, err)
+	}
+	return nil
+}
+
+// CleanFiles removes those of the given files that are untracked. Paths are
+// taken literally rather than as patterns.
+func (r *RealOperations) CleanFiles(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	args := append([]string{"--literal-pathspecs", "clean", "-f", "-q", "--"}, paths...)
+	if _, err := r.runGit(args...); err != nil {
+		return fmt.Errorf("failed to remove untracked files: %w", err)
+	}
+	return nil
+}
+
+// branchConfigKey returns the git config key for a branch-scoped setting.
+func branchConfigKey(branch, key string) string {
+	return fmt.Sprintf("branch.%s.%s", branch, key)
+}
+
+// SetBranchConfig sets a git config value in the branch's config section.
+func (r *RealOperations) SetBranchConfig(branch, key, value string) error {
+	_, err := r.runGit("config", branchConfigKey(branch, key), value)
+	if err != nil {
+		return fmt.Errorf("failed to set %s for branch %s: %w", key, branch, err)
+	}
+	return nil
+}
+
+// GetBranchConfig returns a git config value from the branch's config section,
+// or an empty string if it is not set.
+func (r *RealOperations) GetBranchConfig(branch, key string) (string, error) {
+	cmd := exec.Command("git", "config", "--get", branchConfigKey(branch, key))
+	cmd.Dir = r.RepoDir
+	output, err := cmd.Output()
+	if err != nil {
+		// git config exits with status 1 when the key is not set
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get %s for branch %s: %w", key, branch, err)
+	}
+	return strings.TrimSuffix(string(output), "\n"), nil
+}
+
+// UnsetBranchConfig removes a git config value from the branch's config section.
+func (r *RealOperations) UnsetBranchConfig(branch, key string) error {
+	_, err := r.runGit("config", "--unset", branchConfigKey(branch, key))
+	if err != nil {
+		return fmt.Errorf("failed to unset %s for branch %s: %w", key, branch, err)
+	}
+	return nil
+}
+
+// ListBranchesWithConfig returns the local branches that have the given key
+// set in their config section.
+func (r *RealOperations) ListBranchesWithConfig(key string) ([]string, error) {
+	cmd := exec.Command("git", "config", "--name-only", "--get-regexp", fmt.Sprintf(`^branch\..*\.%s$`, regexp.QuoteMeta(key)))
+	cmd.Dir = r.RepoDir
+	output, err := cmd.Output()
+	if err != nil {
+		// git config exits with status 1 when no keys match
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to list branches with %s: %w", key, err)
+	}
+
+	branches := []string{}
+	suffix := "." + strings.ToLower(key)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		name := strings.TrimSpace(line)
+		if !strings.HasPrefix(name, "branch.") || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		branches = append(branches, strings.TrimSuffix(strings.TrimPrefix(name, "branch."), suffix))
+	}
+	return branches, nil
+}
+
+// FormatPatch returns the commits in base..head as an mbox, preceded by a cover
+// letter whose subject and body come from head's branch description.
+func (r *RealOperations) FormatPatch(base, head string) (string, error) {
+	output, err := r.runGit("format-patch", "--stdout", "--cover-letter", "--cover-from-description=subject", fmt.Sprintf("%s..%s", base, head))
+	if err != nil {
+		return "", fmt.Errorf("failed to format patch for %s..%s: %w", base, head, err)
+	}
+	return output + "\n", nil
+}
+
 // FileExists checks if a file exists in the repository.
 func FileExists(repoDir, filePath string) bool {
 	fullPath := filepath.Join(repoDir, filePath)
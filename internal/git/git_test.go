@@ -1,8 +1,11 @@
 package git
 
 import (
+	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -21,13 +24,16 @@ func TestMockOperations(t *testing.T) {
 	}
 
 	// Test GetDefaultBranch
-	branch, err := mock.GetDefaultBranch()
+	branch, source, err := mock.GetDefaultBranch("origin")
 	if err != nil {
 		t.Errorf("GetDefaultBranch() error = %v", err)
 	}
 	if branch != "main" {
 		t.Errorf("GetDefaultBranch() = %q, want %q", branch, "main")
 	}
+	if source != SourceSymbolicRef {
+		t.Errorf("GetDefaultBranch() source = %q, want %q", source, SourceSymbolicRef)
+	}
 
 	// Test GetCurrentBranch
 	branch, err = mock.GetCurrentBranch()
@@ -75,7 +81,7 @@ func TestMockOperations(t *testing.T) {
 	}
 
 	// Test Commit
-	err = mock.Commit("test commit")
+	err = mock.Commit("test commit", CommitOptions{})
 	if err != nil {
 		t.Errorf("Commit() error = %v", err)
 	}
@@ -91,18 +97,6 @@ func TestMockOperations(t *testing.T) {
 	if len(mock.Pushes) != 1 || mock.Pushes[0].Remote != "origin" || mock.Pushes[0].Branch != "feature/test" {
 		t.Errorf("Pushes = %v, want [{origin feature/test}]", mock.Pushes)
 	}
-
-	// Test CreatePR
-	url, err := mock.CreatePR("main", "feature/test", "Test PR", "Test body", false)
-	if err != nil {
-		t.Errorf("CreatePR() error = %v", err)
-	}
-	if url != mock.PRURLToReturn {
-		t.Errorf("CreatePR() = %q, want %q", url, mock.PRURLToReturn)
-	}
-	if len(mock.CreatedPRs) != 1 {
-		t.Errorf("CreatedPRs length = %d, want 1", len(mock.CreatedPRs))
-	}
 }
 
 func TestFileOperations(t *testing.T) {
@@ -165,3 +159,396 @@ func TestWriteFilePermissionError(t *testing.T) {
 		t.Error("WriteFile() expected error for read-only directory, got nil")
 	}
 }
+
+func TestRealOperationsBranchConfig(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	tmpDir := t.TempDir()
+	ops := NewRealOperations(tmpDir)
+	if _, err := ops.runGit("init", "-q"); err != nil {
+		t.Fatalf("git init error = %v", err)
+	}
+
+	if err := ops.SetBranchConfig("bulkfilepr/Docs.v1", "bulkfilepr-body", "line one\nline two"); err != nil {
+		t.Fatalf("SetBranchConfig() error = %v", err)
+	}
+	value, err := ops.GetBranchConfig("bulkfilepr/Docs.v1", "bulkfilepr-body")
+	if err != nil {
+		t.Fatalf("GetBranchConfig() error = %v", err)
+	}
+	if value != "line one\nline two" {
+		t.Errorf("GetBranchConfig() = %q, want %q", value, "line one\nline two")
+	}
+
+	branches, err := ops.ListBranchesWithConfig("bulkfilepr-body")
+	if err != nil {
+		t.Fatalf("ListBranchesWithConfig() error = %v", err)
+	}
+	if len(branches) != 1 || branches[0] != "bulkfilepr/Docs.v1" {
+		t.Errorf("ListBranchesWithConfig() = %v, want [bulkfilepr/Docs.v1]", branches)
+	}
+
+	if err := ops.UnsetBranchConfig("bulkfilepr/Docs.v1", "bulkfilepr-body"); err != nil {
+		t.Fatalf("UnsetBranchConfig() error = %v", err)
+	}
+	value, err = ops.GetBranchConfig("bulkfilepr/Docs.v1", "bulkfilepr-body")
+	if err != nil || value != "" {
+		t.Errorf("GetBranchConfig() after unset = %q, %v, want empty", value, err)
+	}
+	branches, err = ops.ListBranchesWithConfig("bulkfilepr-body")
+	if err != nil || len(branches) != 0 {
+		t.Errorf("ListBranchesWithConfig() after unset = %v, %v, want empty", branches, err)
+	}
+}
+
+func TestRealOperationsBranchCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	tmpDir := t.TempDir()
+	ops := NewRealOperations(tmpDir)
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+		{"branch", "feature"},
+	} {
+		if _, err := ops.runGit(args...); err != nil {
+			t.Fatalf("git %v error = %v", args, err)
+		}
+	}
+	head, err := ops.HeadCommit()
+	if err != nil {
+		t.Fatalf("HeadCommit() error = %v", err)
+	}
+
+	sha, err := ops.BranchCommit("feature")
+	if err != nil || sha != head {
+		t.Errorf("BranchCommit() = %q, %v, want %q", sha, err, head)
+	}
+	if _, err := ops.BranchCommit("missing"); err == nil {
+		t.Error("BranchCommit() expected error for a missing branch, got nil")
+	}
+}
+
+func TestRealOperationsUndoConflictingStashPop(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	tmpDir := t.TempDir()
+	ops := NewRealOperations(tmpDir)
+	commit := []string{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-am", "change"}
+	if _, err := ops.runGit("init", "-q", "-b", "main"); err != nil {
+		t.Fatalf("git init error = %v", err)
+	}
+	if err := WriteFile(tmpDir, "file.txt", []byte("base\n")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := ops.runGit("add", "file.txt"); err != nil {
+		t.Fatalf("git add error = %v", err)
+	}
+	if _, err := ops.runGit(commit...); err != nil {
+		t.Fatalf("git commit error = %v", err)
+	}
+
+	// Stash a tracked edit and an untracked file, then commit a conflicting edit
+	if err := WriteFile(tmpDir, "file.txt", []byte("stashed\n")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := WriteFile(tmpDir, "notes.txt", []byte("notes\n")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := ops.Stash("test"); err != nil {
+		t.Fatalf("Stash() error = %v", err)
+	}
+	if err := WriteFile(tmpDir, "file.txt", []byte("committed\n")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := ops.runGit(commit...); err != nil {
+		t.Fatalf("git commit error = %v", err)
+	}
+
+	if err := ops.StashPop(); err == nil {
+		t.Fatal("StashPop() expected a conflict, got nil")
+	}
+	if err := ops.ResetHard("HEAD"); err != nil {
+		t.Fatalf("ResetHard() error = %v", err)
+	}
+	untracked, err := ops.StashedUntrackedFiles()
+	if err != nil || len(untracked) != 1 || untracked[0] != "notes.txt" {
+		t.Fatalf("StashedUntrackedFiles() = %v, %v, want [notes.txt]", untracked, err)
+	}
+	if err := ops.CleanFiles(untracked); err != nil {
+		t.Fatalf("CleanFiles() error = %v", err)
+	}
+
+	if FileExists(tmpDir, "notes.txt") {
+		t.Error("notes.txt still exists after CleanFiles()")
+	}
+	if clean, err := ops.IsWorkingTreeClean(); err != nil || !clean {
+		t.Errorf("IsWorkingTreeClean() = %v, %v, want clean", clean, err)
+	}
+	if list, err := ops.runGit("stash", "list"); err != nil || strings.Count(list, "\n") != 0 || list == "" {
+		t.Errorf("stash list = %q, %v, want the stash kept", list, err)
+	}
+}
+
+func TestRealOperationsCommitTrailers(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	tmpDir := t.TempDir()
+	ops := NewRealOperations(tmpDir)
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		if _, err := ops.runGit(args...); err != nil {
+			t.Fatalf("git %v error = %v", args, err)
+		}
+	}
+	if err := WriteFile(tmpDir, "file.txt", []byte("content\n")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := ops.AddFile("file.txt"); err != nil {
+		t.Fatalf("AddFile() error = %v", err)
+	}
+
+	opts := CommitOptions{
+		Trailers: []Trailer{{Key: "Refs", Value: "ENG-42"}, {Key: "Co-authored-by", Value: "Jane Doe <jane@example.com>"}},
+		Signoff:  true,
+	}
+	if err := ops.Commit("chore: add file.txt\n\nChange-Id: I0123456789abcdef", opts); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	message, err := ops.runGit("log", "-1", "--format=%B")
+	if err != nil {
+		t.Fatalf("git log error = %v", err)
+	}
+	// git adds the sign-off before the --trailer trailers
+	want := "chore: add file.txt\n\n" +
+		"Change-Id: I0123456789abcdef\n" +
+		"Signed-off-by: Test <test@example.com>\n" +
+		"Refs: ENG-42\n" +
+		"Co-authored-by: Jane Doe <jane@example.com>"
+	if message != want {
+		t.Errorf("commit message =\n%s\nwant\n%s", message, want)
+	}
+}
+
+func TestRealOperationsFormatPatch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	tmpDir := t.TempDir()
+	ops := NewRealOperations(tmpDir)
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if _, err := ops.runGit(args...); err != nil {
+			t.Fatalf("git %v error = %v", args, err)
+		}
+	}
+
+	if err := ops.CreateBranch("feature"); err != nil {
+		t.Fatalf("CreateBranch() error = %v", err)
+	}
+	if err := WriteFile(tmpDir, "file.txt", []byte("content\n")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := ops.AddFile("file.txt"); err != nil {
+		t.Fatalf("AddFile() error = %v", err)
+	}
+	if err := ops.Commit("chore: add file.txt", CommitOptions{}); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if err := ops.SetBranchConfig("feature", "description", "Add file\n\nCover body"); err != nil {
+		t.Fatalf("SetBranchConfig() error = %v", err)
+	}
+
+	patch, err := ops.FormatPatch("main", "feature")
+	if err != nil {
+		t.Fatalf("FormatPatch() error = %v", err)
+	}
+	for _, want := range []string{"Subject: [PATCH 0/1] Add file", "Cover body", "Subject: [PATCH 1/1] chore: add file.txt", "+content"} {
+		if !strings.Contains(patch, want) {
+			t.Errorf("FormatPatch() output missing %q:\n%s", want, patch)
+		}
+	}
+}
+
+func TestRealOperationsGetDefaultBranchWithoutGH(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	remoteDir := filepath.Join(t.TempDir(), "remote.git")
+	cloneDir := filepath.Join(t.TempDir(), "clone")
+	setup := NewRealOperations(filepath.Dir(remoteDir))
+	for _, args := range [][]string{
+		{"init", "-q", "--bare", "-b", "trunk", remoteDir},
+		{"clone", "-q", remoteDir, cloneDir},
+	} {
+		if _, err := setup.runGit(args...); err != nil {
+			t.Fatalf("git %v error = %v", args, err)
+		}
+	}
+	ops := NewRealOperations(cloneDir)
+	for _, args := range [][]string{
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+		{"push", "-q", "origin", "trunk"},
+		{"remote", "set-head", "origin", "trunk"},
+	} {
+		if _, err := ops.runGit(args...); err != nil {
+			t.Fatalf("git %v error = %v", args, err)
+		}
+	}
+
+	// The clone has refs/remotes/origin/HEAD, so the cached symbolic ref is used
+	branch, source, err := ops.GetDefaultBranch("origin")
+	if err != nil {
+		t.Fatalf("GetDefaultBranch() error = %v", err)
+	}
+	if branch != "trunk" || source != SourceSymbolicRef {
+		t.Errorf("GetDefaultBranch() = %q, %q, want %q, %q", branch, source, "trunk", SourceSymbolicRef)
+	}
+
+	// Without the cached symbolic ref, the remote is queried directly
+	if _, err := ops.runGit("remote", "set-head", "origin", "--delete"); err != nil {
+		t.Fatalf("git remote set-head --delete error = %v", err)
+	}
+	branch, source, err = ops.GetDefaultBranch("origin")
+	if err != nil {
+		t.Fatalf("GetDefaultBranch() error = %v", err)
+	}
+	if branch != "trunk" || source != SourceLsRemote {
+		t.Errorf("GetDefaultBranch() = %q, %q, want %q, %q", branch, source, "trunk", SourceLsRemote)
+	}
+
+	// With no way to detect it, all attempts are reported
+	_, _, err = ops.GetDefaultBranch("missing")
+	if err == nil {
+		t.Fatal("GetDefaultBranch() expected error for unknown remote, got nil")
+	}
+	if !strings.Contains(err.Error(), string(SourceLsRemote)) {
+		t.Errorf("GetDefaultBranch() error = %v, want it to mention %s", err, SourceLsRemote)
+	}
+}
+
+// initIsolatedRepo creates a repository that sees no user or system git
+// config, so identity and signing settings come only from the test.
+func initIsolatedRepo(t *testing.T) (*RealOperations, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, name := range []string{"GIT_AUTHOR_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_NAME", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+
+	tmpDir := t.TempDir()
+	ops := NewRealOperations(tmpDir)
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		// Stop git from guessing an identity from the host
+		{"config", "user.useConfigOnly", "true"},
+	} {
+		if _, err := ops.runGit(args...); err != nil {
+			t.Fatalf("git %v error = %v", args, err)
+		}
+	}
+	if err := WriteFile(tmpDir, "file.txt", []byte("content\n")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := ops.AddFile("file.txt"); err != nil {
+		t.Fatalf("AddFile() error = %v", err)
+	}
+	return ops, tmpDir
+}
+
+func TestRealOperationsCommitIdentity(t *testing.T) {
+	ops, _ := initIsolatedRepo(t)
+
+	if err := ops.CheckIdentity(CommitOptions{}); !errors.Is(err, ErrNoIdentity) {
+		t.Errorf("CheckIdentity() without identity error = %v, want ErrNoIdentity", err)
+	}
+	opts := CommitOptions{
+		AuthorName:     "Standards Bot",
+		AuthorEmail:    "bot@example.com",
+		CommitterName:  "CI",
+		CommitterEmail: "ci@example.com",
+	}
+	if err := ops.CheckIdentity(opts); err != nil {
+		t.Errorf("CheckIdentity() error = %v", err)
+	}
+	if err := ops.Commit("chore: add file.txt", opts); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	idents, err := ops.runGit("log", "-1", "--format=%an <%ae>|%cn <%ce>")
+	if err != nil {
+		t.Fatalf("git log error = %v", err)
+	}
+	if want := "Standards Bot <bot@example.com>|CI <ci@example.com>"; idents != want {
+		t.Errorf("author|committer = %q, want %q", idents, want)
+	}
+}
+
+func TestRealOperationsCommitSSHSigning(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	ops, _ := initIsolatedRepo(t)
+	key := filepath.Join(t.TempDir(), "id_ed25519")
+	if output, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen error = %v\n%s", err, output)
+	}
+
+	opts := CommitOptions{
+		AuthorName:     "Standards Bot",
+		AuthorEmail:    "bot@example.com",
+		CommitterName:  "Standards Bot",
+		CommitterEmail: "bot@example.com",
+		Sign:           true,
+		SigningKey:     key,
+		SigningFormat:  "ssh",
+	}
+	if err := ops.Commit("chore: add file.txt", opts); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	commit, err := ops.runGit("cat-file", "commit", "HEAD")
+	if err != nil {
+		t.Fatalf("git cat-file error = %v", err)
+	}
+	if !strings.Contains(commit, "-----BEGIN SSH SIGNATURE-----") {
+		t.Errorf("commit is not SSH-signed:\n%s", commit)
+	}
+
+	// gpg.format is only set for the commit
+	if value, err := ops.runGit("config", "--get", "gpg.format"); err == nil {
+		t.Errorf("gpg.format was written to the repository config: %q", value)
+	}
+}
+
+func TestRealOperationsCommitSigningEnabled(t *testing.T) {
+	ops, _ := initIsolatedRepo(t)
+
+	if enabled, err := ops.CommitSigningEnabled(); err != nil || enabled {
+		t.Errorf("CommitSigningEnabled() = %v, %v, want false when unset", enabled, err)
+	}
+	if _, err := ops.runGit("config", "commit.gpgsign", "yes"); err != nil {
+		t.Fatalf("git config error = %v", err)
+	}
+	if enabled, err := ops.CommitSigningEnabled(); err != nil || !enabled {
+		t.Errorf("CommitSigningEnabled() = %v, %v, want true", enabled, err)
+	}
+}
@@ -1,51 +1,90 @@
 package git
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 // MockOperations is a mock implementation of Operations for testing.
 type MockOperations struct {
-	DefaultBranch     string
-	CurrentBranch     string
-	IsClean           bool
-	BranchExistsMap   map[string]bool // Map of branch names to whether they exist
-	CreatedBranches   []string
-	SwitchedBranches  []string
-	AddedFiles        []string
-	Commits           []string
-	Pushes            []struct{ Remote, Branch string }
-	CreatedPRs        []struct{ Base, Head, Title, Body string; Draft bool }
-	PRURLToReturn     string
+	DefaultBranch    string
+	DefaultSource    DefaultBranchSource
+	CurrentBranch    string
+	IsClean          bool
+	BranchExistsMap  map[string]bool // Map of branch names to whether they exist
+	RemoteBranches   []string
+	RemoteURLs       map[string]string // Map of remote names to URLs
+	AddedRemotes     []struct{ Name, URL string }
+	CreatedBranches  []string
+	DeletedBranches  []string
+	SwitchedBranches []string
+	AddedFiles       []string
+	Commits          []string
+	CommitOpts       []CommitOptions
+	Pushes           []struct{ Remote, Branch string }
+	LeasePushes      []struct{ Remote, Branch, ExpectedSHA string }
+	RefspecPushes    []struct{ Remote, Refspec string }
+	PushOutput       string
+	HeadSHA          string
+	BranchHeads      map[string]string // Map of local branch names to commit SHAs
+	RemoteHeads      map[string]string // Map of remote branch names to commit SHAs
+	Resets           []string
+	Stashes          []string
+	StashPops        int
+	StashedUntracked []string // Untracked files saved in the most recent stash
+	CleanedFiles     []string
+	BranchConfig     map[string]map[string]string // Map of branch names to config values
+	PatchToReturn    string
+	SigningEnabled   bool // Whether commit.gpgsign is set
+
+	// OnSwitchBranch, if set, is called after each successful branch switch so
+	// tests can mimic the working tree changing between branches.
+	OnSwitchBranch func(name string)
 
 	// Error fields for simulating failures
-	DefaultBranchErr     error
-	CurrentBranchErr     error
-	IsCleanErr           error
-	BranchExistsErr      error
-	CreateBranchErr      error
-	SwitchBranchErr      error
-	AddFileErr           error
-	CommitErr            error
-	PushErr              error
-	CreatePRErr          error
+	DefaultBranchErr error
+	CurrentBranchErr error
+	IsCleanErr       error
+	BranchExistsErr  error
+	ListBranchesErr  error
+	RemoteURLErr     error
+	AddRemoteErr     error
+	CreateBranchErr  error
+	SwitchBranchErr  error
+	AddFileErr       error
+	CommitErr        error
+	IdentityErr      error
+	PushErr          error
+	RemoteHeadErr    error
+	// RemoteHeadFailures, if set, limits RemoteHeadErr to the first calls.
+	RemoteHeadFailures int
+	RemoteHeadCalls    int
+	ResetErr           error
+	StashErr           error
+	StashPopErr        error
 }
 
 // NewMockOperations creates a new MockOperations with default successful behavior.
 func NewMockOperations() *MockOperations {
 	return &MockOperations{
 		DefaultBranch:   "main",
+		DefaultSource:   SourceSymbolicRef,
 		CurrentBranch:   "main",
 		IsClean:         true,
 		BranchExistsMap: make(map[string]bool),
-		PRURLToReturn:   "https://github.com/owner/repo/pull/1",
+		HeadSHA:         mockSHA(0),
+		BranchHeads:     make(map[string]string),
+		RemoteHeads:     make(map[string]string),
+		BranchConfig:    make(map[string]map[string]string),
 	}
 }
 
-// GetDefaultBranch returns the mock default branch.
-func (m *MockOperations) GetDefaultBranch() (string, error) {
+// GetDefaultBranch returns the mock default branch and source.
+func (m *MockOperations) GetDefaultBranch(remote string) (string, DefaultBranchSource, error) {
 	if m.DefaultBranchErr != nil {
-		return "", m.DefaultBranchErr
+		return "", "", m.DefaultBranchErr
 	}
-	return m.DefaultBranch, nil
+	return m.DefaultBranch, m.DefaultSource, nil
 }
 
 // GetCurrentBranch returns the mock current branch.
@@ -81,6 +120,42 @@ func (m *MockOperations) BranchExists(name, remote string) (bool, error) {
 	return false, nil
 }
 
+// ListRemoteBranches returns the mock remote branches.
+func (m *MockOperations) ListRemoteBranches(remote string) ([]string, error) {
+	if m.ListBranchesErr != nil {
+		return nil, m.ListBranchesErr
+	}
+	return m.RemoteBranches, nil
+}
+
+// RemoteURL returns the mock URL for the remote.
+func (m *MockOperations) RemoteURL(remote string) (string, error) {
+	if m.RemoteURLErr != nil {
+		return "", m.RemoteURLErr
+	}
+	url, ok := m.RemoteURLs[remote]
+	if !ok {
+		return "", fmt.Errorf("no such remote '%s'", remote)
+	}
+	return url, nil
+}
+
+// AddRemote records the added remote and makes it resolvable by RemoteURL.
+func (m *MockOperations) AddRemote(name, url string) error {
+	if m.AddRemoteErr != nil {
+		return m.AddRemoteErr
+	}
+	if _, ok := m.RemoteURLs[name]; ok {
+		return fmt.Errorf("remote %s already exists", name)
+	}
+	if m.RemoteURLs == nil {
+		m.RemoteURLs = make(map[string]string)
+	}
+	m.RemoteURLs[name] = url
+	m.AddedRemotes = append(m.AddedRemotes, struct{ Name, URL string }{name, url})
+	return nil
+}
+
 // CreateBranch records the created branch.
 func (m *MockOperations) CreateBranch(name string) error {
 	if m.CreateBranchErr != nil {
@@ -91,6 +166,13 @@ func (m *MockOperations) CreateBranch(name string) error {
 	return nil
 }
 
+// DeleteBranch records the deleted branch.
+func (m *MockOperations) DeleteBranch(name string) error {
+	m.DeletedBranches = append(m.DeletedBranches, name)
+	delete(m.BranchConfig, name)
+	return nil
+}
+
 // SwitchBranch records the branch switch.
 func (m *MockOperations) SwitchBranch(name string) error {
 	if m.SwitchBranchErr != nil {
@@ -98,6 +180,9 @@ func (m *MockOperations) SwitchBranch(name string) error {
 	}
 	m.SwitchedBranches = append(m.SwitchedBranches, name)
 	m.CurrentBranch = name
+	if m.OnSwitchBranch != nil {
+		m.OnSwitchBranch(name)
+	}
 	return nil
 }
 
@@ -111,14 +196,26 @@ func (m *MockOperations) AddFile(path string) error {
 }
 
 // Commit records the commit.
-func (m *MockOperations) Commit(message string) error {
+func (m *MockOperations) Commit(message string, opts CommitOptions) error {
 	if m.CommitErr != nil {
 		return m.CommitErr
 	}
 	m.Commits = append(m.Commits, message)
+	m.CommitOpts = append(m.CommitOpts, opts)
+	m.HeadSHA = mockSHA(len(m.Commits))
 	return nil
 }
 
+// CheckIdentity returns IdentityErr.
+func (m *MockOperations) CheckIdentity(opts CommitOptions) error {
+	return m.IdentityErr
+}
+
+// CommitSigningEnabled returns whether the mock is set up to sign commits.
+func (m *MockOperations) CommitSigningEnabled() (bool, error) {
+	return m.SigningEnabled, nil
+}
+
 // Push records the push.
 func (m *MockOperations) Push(remote, branch string) error {
 	if m.PushErr != nil {
@@ -128,13 +225,132 @@ func (m *MockOperations) Push(remote, branch string) error {
 	return nil
 }
 
-// CreatePR records the PR creation.
-func (m *MockOperations) CreatePR(base, head, title, body string, draft bool) (string, error) {
-	if m.CreatePRErr != nil {
-		return "", m.CreatePRErr
+// PushRefspec records the push and returns the mock push output.
+func (m *MockOperations) PushRefspec(remote, refspec string) (string, error) {
+	if m.PushErr != nil {
+		return "", m.PushErr
+	}
+	m.RefspecPushes = append(m.RefspecPushes, struct{ Remote, Refspec string }{remote, refspec})
+	return m.PushOutput, nil
+}
+
+// PushWithLease records the push, failing if the mock remote head has moved.
+func (m *MockOperations) PushWithLease(remote, branch, expectedSHA string) error {
+	if m.PushErr != nil {
+		return m.PushErr
+	}
+	if m.RemoteHeads[branch] != expectedSHA {
+		return fmt.Errorf("stale info: %s/%s is at %q, expected %q", remote, branch, m.RemoteHeads[branch], expectedSHA)
+	}
+	m.LeasePushes = append(m.LeasePushes, struct{ Remote, Branch, ExpectedSHA string }{remote, branch, expectedSHA})
+	m.RemoteHeads[branch] = m.HeadSHA
+	return nil
+}
+
+// HeadCommit returns the mock HEAD commit SHA.
+func (m *MockOperations) HeadCommit() (string, error) {
+	return m.HeadSHA, nil
+}
+
+// BranchCommit returns the mock commit of a local branch.
+func (m *MockOperations) BranchCommit(name string) (string, error) {
+	sha, ok := m.BranchHeads[name]
+	if !ok {
+		return "", fmt.Errorf("failed to resolve branch %s: unknown revision", name)
+	}
+	return sha, nil
+}
+
+// RemoteBranchHead returns the mock remote head for the branch.
+func (m *MockOperations) RemoteBranchHead(remote, branch string) (string, error) {
+	m.RemoteHeadCalls++
+	if m.RemoteHeadErr != nil && (m.RemoteHeadFailures == 0 || m.RemoteHeadCalls <= m.RemoteHeadFailures) {
+		return "", m.RemoteHeadErr
+	}
+	return m.RemoteHeads[branch], nil
+}
+
+// Stash records the stash and marks the mock working tree clean.
+func (m *MockOperations) Stash(message string) error {
+	if m.StashErr != nil {
+		return m.StashErr
+	}
+	m.Stashes = append(m.Stashes, message)
+	m.IsClean = true
+	return nil
+}
+
+// StashPop records the pop and marks the mock working tree dirty again.
+func (m *MockOperations) StashPop() error {
+	if m.StashPopErr != nil {
+		m.IsClean = false
+		return m.StashPopErr
+	}
+	m.StashPops++
+	m.Stashes = m.Stashes[:len(m.Stashes)-1]
+	m.IsClean = false
+	return nil
+}
+
+// StashedUntrackedFiles returns the mock untracked files of the stash.
+func (m *MockOperations) StashedUntrackedFiles() ([]string, error) {
+	return m.StashedUntracked, nil
+}
+
+// CleanFiles records the removed files.
+func (m *MockOperations) CleanFiles(paths []string) error {
+	m.CleanedFiles = append(m.CleanedFiles, paths...)
+	return nil
+}
+
+// ResetHard records the reset and moves the mock HEAD.
+func (m *MockOperations) ResetHard(rev string) error {
+	if m.ResetErr != nil {
+		return m.ResetErr
 	}
-	m.CreatedPRs = append(m.CreatedPRs, struct{ Base, Head, Title, Body string; Draft bool }{base, head, title, body, draft})
-	return m.PRURLToReturn, nil
+	m.Resets = append(m.Resets, rev)
+	if rev != "HEAD" {
+		m.HeadSHA = rev
+	}
+	m.IsClean = true
+	return nil
+}
+
+// SetBranchConfig records a branch config value.
+func (m *MockOperations) SetBranchConfig(branch, key, value string) error {
+	if m.BranchConfig[branch] == nil {
+		m.BranchConfig[branch] = make(map[string]string)
+	}
+	m.BranchConfig[branch][key] = value
+	return nil
+}
+
+// GetBranchConfig returns a recorded branch config value.
+func (m *MockOperations) GetBranchConfig(branch, key string) (string, error) {
+	return m.BranchConfig[branch][key], nil
+}
+
+// UnsetBranchConfig removes a recorded branch config value.
+func (m *MockOperations) UnsetBranchConfig(branch, key string) error {
+	delete(m.BranchConfig[branch], key)
+	return nil
+}
+
+// ListBranchesWithConfig returns the branches with the key recorded, sorted by name.
+func (m *MockOperations) ListBranchesWithConfig(key string) ([]string, error) {
+	branches := []string{}
+	for branch, values := range m.BranchConfig {
+		if _, ok := values[key]; ok {
+			branches = append(branches, branch)
+		}
+	}
+	sort.Strings(branches)
+	return branches, nil
+}
+
+// FormatPatch returns the mock patch, prefixed with head's branch description.
+func (m *MockOperations) FormatPatch(base, head string) (string, error) {
+	return m.BranchConfig[head]["description"] + "\n" + m.PatchToReturn, nil
 }
 
 // Validate checks that all expected operations were performed.
@@ -144,3 +360,8 @@ func (m *MockOperations) Validate(expectedBranch string) error {
 	}
 	return nil
 }
+
+// mockSHA returns a deterministic fake commit SHA for the nth mock commit.
+func mockSHA(n int) string {
+	return fmt.Sprintf("%040x", n)
+}
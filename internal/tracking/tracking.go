@@ -0,0 +1,189 @@
+package tracking
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/apply"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
+)
+
+// Entry is the state of one target repository in a tracking issue.
+type Entry struct {
+	// Repo identifies the repository, usually its path on the forge (e.g. acme/service).
+	Repo string `json:"repo"`
+	// PRURL is the URL of the repository's PR (empty if there is none).
+	PRURL string `json:"pr,omitempty"`
+	// State describes where the repository is in the rollout (e.g. open, merged).
+	State string `json:"state"`
+	// Done reports whether the repository needs no further work, which checks
+	// its box.
+	Done bool `json:"done,omitempty"`
+}
+
+// entryRe matches a rendered entry line. The entry itself is kept in the
+// trailing HTML comment, so the visible text can change without breaking parsing.
+var entryRe = regexp.MustCompile(`^- \[[ xX]\] .*<!-- bulkfilepr-entry (\{.*\}) -->$`)
+
+// Parse returns the entries listed in a tracking issue body. Lines that are
+// not entries are ignored.
+func Parse(body string) []Entry {
+	var entries []Entry
+	for _, line := range strings.Split(body, "\n") {
+		match := entryRe.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if match == nil {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(match[1]), &entry); err != nil || entry.Repo == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Merge returns the entries with the updates applied: an update replaces the
+// entry for the same repository or is added. The result is sorted by repository.
+func Merge(entries, updates []Entry) []Entry {
+	byRepo := map[string]Entry{}
+	for _, entry := range entries {
+		byRepo[entry.Repo] = entry
+	}
+	for _, update := range updates {
+		byRepo[update.Repo] = update
+	}
+
+	merged := make([]Entry, 0, len(byRepo))
+	for _, entry := range byRepo {
+		merged = append(merged, entry)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Repo < merged[j].Repo })
+	return merged
+}
+
+// Render returns the tracking issue body for the entries.
+func Render(entries []Entry) string {
+	done := 0
+	for _, entry := range entries {
+		if entry.Done {
+			done++
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("This issue is updated by bulkfilepr each time it runs for the campaign; manual edits to the list are overwritten.\n\n")
+	fmt.Fprintf(&b, "**Progress:** %d of %d done\n\n", done, len(entries))
+	for _, entry := range entries {
+		check := " "
+		if entry.Done {
+			check = "x"
+		}
+		fmt.Fprintf(&b, "- [%s] `%s`", check, entry.Repo)
+		if entry.PRURL != "" {
+			fmt.Fprintf(&b, " %s", entry.PRURL)
+		}
+		// json.Marshal escapes <, > and &, so the entry cannot end the comment
+		// early, and newlines, so it stays on one line
+		data, _ := json.Marshal(entry)
+		state := strings.Join(strings.Fields(entry.State), " ")
+		fmt.Fprintf(&b, " - %s <!-- bulkfilepr-entry %s -->\n", state, data)
+	}
+	return b.String()
+}
+
+// Update adds the updates to the open issue with the title, creating the
+// issue if there is none, and returns the issue. Forges have no conditional
+// issue updates, so the read, merge and write are not atomic: concurrent
+// updates of the same issue can overwrite each other's entries, and two
+// first runs can each create an issue.
+func Update(tracker forge.IssueTracker, title string, updates []Entry) (*forge.Issue, error) {
+	issue, err := tracker.FindIssue(title)
+	if err != nil {
+		return nil, err
+	}
+	if issue == nil {
+		return tracker.CreateIssue(title, Render(Merge(nil, updates)))
+	}
+
+	body := Render(Merge(Parse(issue.Body), updates))
+	if body == issue.Body {
+		return issue, nil
+	}
+	if err := tracker.UpdateIssue(issue.Number, body); err != nil {
+		return nil, err
+	}
+	issue.Body = body
+	return issue, nil
+}
+
+// Title returns the tracking issue title, defaulting to one derived from the
+// campaign branch.
+func Title(title, branch string) string {
+	if title != "" {
+		return title
+	}
+	return "bulkfilepr campaign: " + branch
+}
+
+// ResultEntry returns the entry for an apply result, or false if the result
+// does not change the repository's state, such as in dry-run mode.
+func ResultEntry(repo string, result *apply.Result, draft bool) (Entry, bool) {
+	entry := Entry{Repo: repo}
+	switch result.Action {
+	case "updated":
+		entry.PRURL = result.PRURL
+		entry.State = "open"
+		if draft {
+			entry.State = "draft"
+		}
+	case "pushed":
+		entry.State = "pushed directly"
+		entry.Done = true
+	case "committed":
+		entry.State = "committed locally, not published"
+	case "exported":
+		entry.State = "patch exported"
+	case "no action taken":
+		entry.State = "no change needed: " + result.NoActionReason
+		entry.Done = true
+	default:
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// RecordEntry returns the entry for a PR recorded by a forge, such as the
+// local forge.
+func RecordEntry(repo string, pr *forge.PullRequest) Entry {
+	state := pr.State
+	if pr.Draft && state == "open" {
+		state = "draft"
+	}
+	return Entry{Repo: repo, PRURL: pr.URL, State: state, Done: pr.State == "merged"}
+}
+
+// MergeEntry returns the entry for a merge result, or false if the result
+// does not change the repository's state. A repository without an open PR is
+// left as it was, since its PR may have been merged by an earlier run.
+func MergeEntry(repo string, result *apply.MergeResult) (Entry, bool) {
+	entry := Entry{Repo: repo, PRURL: result.PRURL}
+	switch result.Action {
+	case "merged":
+		entry.State = "merged"
+		entry.Done = true
+	case "blocked":
+		entry.State = "blocked: " + result.Reason
+	case "failed":
+		if result.PRURL == "" {
+			return Entry{}, false
+		}
+		entry.State = "merge failed: " + result.Reason
+	default:
+		return Entry{}, false
+	}
+	return entry, true
+}
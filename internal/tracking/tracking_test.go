@@ -0,0 +1,146 @@
+package tracking
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/apply"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
+)
+
+func TestRenderParse(t *testing.T) {
+	entries := []Entry{
+		{Repo: "acme/api", PRURL: "https://github.com/acme/api/pull/4", State: "merged", Done: true},
+		{Repo: "acme/web", PRURL: "https://github.com/acme/web/pull/9", State: "blocked: checks failing --> <b>\nsee logs"},
+		{Repo: "acme/worker", State: "no change needed: file already matches"},
+	}
+
+	body := Render(entries)
+	if !strings.Contains(body, "**Progress:** 1 of 3 done") {
+		t.Errorf("Render() missing progress:\n%s", body)
+	}
+	if !strings.Contains(body, "- [x] `acme/api` https://github.com/acme/api/pull/4 - merged <!-- bulkfilepr-entry ") {
+		t.Errorf("Render() missing checked entry:\n%s", body)
+	}
+	if !strings.Contains(body, "- [ ] `acme/web` https://github.com/acme/web/pull/9 - blocked: checks failing --> <b> see logs <!--") {
+		t.Errorf("Render() missing single-line state:\n%s", body)
+	}
+
+	if got := Parse(body); !reflect.DeepEqual(got, entries) {
+		t.Errorf("Parse(Render()) = %+v, want %+v", got, entries)
+	}
+}
+
+func TestParseIgnoresOtherLines(t *testing.T) {
+	body := "Notes from the team\n\n- [ ] a manual item\n- [x] `acme/api` - merged <!-- bulkfilepr-entry {\"repo\":\"acme/api\",\"state\":\"merged\",\"done\":true} -->\r\n- [ ] `x` <!-- bulkfilepr-entry {broken} -->\n"
+	want := []Entry{{Repo: "acme/api", State: "merged", Done: true}}
+	if got := Parse(body); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	entries := []Entry{{Repo: "b", State: "open"}, {Repo: "a", State: "open"}}
+	updates := []Entry{{Repo: "b", State: "merged", Done: true}, {Repo: "c", State: "draft"}}
+
+	want := []Entry{{Repo: "a", State: "open"}, {Repo: "b", State: "merged", Done: true}, {Repo: "c", State: "draft"}}
+	if got := Merge(entries, updates); !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tracker := forge.NewMockForge()
+
+	issue, err := Update(tracker, "Rollout", []Entry{{Repo: "acme/api", State: "open"}})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if len(tracker.Issues) != 1 || issue.URL != tracker.Issues[0].URL {
+		t.Fatalf("Issues = %+v, want one created issue", tracker.Issues)
+	}
+
+	// A later run for another repository keeps the first entry
+	if _, err := Update(tracker, "Rollout", []Entry{{Repo: "acme/web", State: "open"}}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	want := []Entry{{Repo: "acme/api", State: "open"}, {Repo: "acme/web", State: "open"}}
+	if len(tracker.Issues) != 1 {
+		t.Fatalf("Issues = %d, want 1", len(tracker.Issues))
+	}
+	if got := Parse(tracker.Issues[0].Body); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %+v, want %+v", got, want)
+	}
+}
+
+func TestUpdateUnchanged(t *testing.T) {
+	tracker := forge.NewMockForge()
+	if _, err := Update(tracker, "Rollout", []Entry{{Repo: "acme/api", State: "open"}}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	// An unchanged body is not written again
+	tracker.UpdateIssueErr = errors.New("unexpected update")
+	if _, err := Update(tracker, "Rollout", []Entry{{Repo: "acme/api", State: "open"}}); err != nil {
+		t.Errorf("Update() error = %v, want no update", err)
+	}
+}
+
+func TestTitle(t *testing.T) {
+	if got := Title("", "bulkfilepr/ci-abc"); got != "bulkfilepr campaign: bulkfilepr/ci-abc" {
+		t.Errorf("Title() = %q", got)
+	}
+	if got := Title("CI rollout", "bulkfilepr/ci-abc"); got != "CI rollout" {
+		t.Errorf("Title() = %q, want %q", got, "CI rollout")
+	}
+}
+
+func TestResultEntry(t *testing.T) {
+	tests := []struct {
+		name   string
+		result apply.Result
+		draft  bool
+		want   Entry
+		ok     bool
+	}{
+		{name: "pr", result: apply.Result{Action: "updated", PRURL: "https://x/pull/1"}, want: Entry{Repo: "r", PRURL: "https://x/pull/1", State: "open"}, ok: true},
+		{name: "draft", result: apply.Result{Action: "updated", PRURL: "https://x/pull/1"}, draft: true, want: Entry{Repo: "r", PRURL: "https://x/pull/1", State: "draft"}, ok: true},
+		{name: "pushed", result: apply.Result{Action: "pushed"}, want: Entry{Repo: "r", State: "pushed directly", Done: true}, ok: true},
+		{name: "no action", result: apply.Result{Action: "no action taken", NoActionReason: "file does not exist"}, want: Entry{Repo: "r", State: "no change needed: file does not exist", Done: true}, ok: true},
+		{name: "dry run", result: apply.Result{Action: "would update"}, ok: false},
+		{name: "branch exists", result: apply.Result{Action: "branch already exists"}, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ResultEntry("r", &tt.result, tt.draft)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("ResultEntry() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMergeEntry(t *testing.T) {
+	tests := []struct {
+		name   string
+		result apply.MergeResult
+		want   Entry
+		ok     bool
+	}{
+		{name: "merged", result: apply.MergeResult{Action: "merged", PRURL: "u"}, want: Entry{Repo: "r", PRURL: "u", State: "merged", Done: true}, ok: true},
+		{name: "blocked", result: apply.MergeResult{Action: "blocked", PRURL: "u", Reason: "review required"}, want: Entry{Repo: "r", PRURL: "u", State: "blocked: review required"}, ok: true},
+		{name: "failed", result: apply.MergeResult{Action: "failed", PRURL: "u", Reason: "409"}, want: Entry{Repo: "r", PRURL: "u", State: "merge failed: 409"}, ok: true},
+		{name: "failed without PR", result: apply.MergeResult{Action: "failed", Reason: "no forge"}, ok: false},
+		{name: "no open PR", result: apply.MergeResult{Action: "no open PR"}, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MergeEntry("r", &tt.result)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("MergeEntry() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
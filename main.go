@@ -11,7 +11,9 @@ import (
 
 	"github.com/UnitVectorY-Labs/bulkfilepr/internal/apply"
 	"github.com/UnitVectorY-Labs/bulkfilepr/internal/config"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
 	"github.com/UnitVectorY-Labs/bulkfilepr/internal/git"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/tracking"
 )
 
 var Version = "dev" // Set by the build system to the release version
@@ -36,7 +38,37 @@ func main() {
 }
 
 func run(args []string) int {
-	fs := flag.NewFlagSet("bulkfilepr", flag.ContinueOnError)
+	// Check for subcommand
+	if len(args) == 0 {
+		printUsage(nil)
+		return exitInvalidUsage
+	}
+
+	switch args[0] {
+	case "apply":
+		return runApply(args[1:])
+	case "publish":
+		return runPublish(args[1:])
+	case "status":
+		return runStatus(args[1:])
+	case "prune":
+		return runPrune(args[1:])
+	case "merge":
+		return runMerge(args[1:])
+	case "-version", "--version":
+		fmt.Println(versionString())
+		return exitSuccess
+	case "-h", "--help", "-help":
+		return runApply(args)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q\n", args[0])
+		fmt.Fprintf(os.Stderr, "Usage: bulkfilepr <apply|publish|status|prune|merge> [options]\n")
+		return exitInvalidUsage
+	}
+}
+
+func runApply(args []string) int {
+	fs := flag.NewFlagSet("bulkfilepr apply", flag.ContinueOnError)
 
 	// Define flags
 	var (
@@ -45,33 +77,70 @@ func run(args []string) int {
 		newFile       = fs.String("new-file", "", "Path to the new file content (required)")
 		repo          = fs.String("repo", ".", "Repository directory")
 		branch        = fs.String("branch", "", "Branch name (auto-generated if empty)")
+		branchTmpl    = fs.String("branch-template", config.DefaultBranchTemplate, "Template for auto-generated branch names")
+		branchPrefix  = fs.String("branch-prefix", config.DefaultBranchPrefix, "Value for {prefix} in the branch template")
 		commitMessage = fs.String("commit-message", "", "Commit message")
+		commitMsgFile = fs.String("commit-message-file", "", "Go template file rendered into the commit message")
 		prTitle       = fs.String("pr-title", "", "PR title")
-		prBody        = fs.String("pr-body", "", "PR body")
+		prBody        = fs.String("pr-body", "", "PR body (default: a summary of the change)")
+		prBodyFile    = fs.String("pr-body-file", "", "Go template file rendered into the PR body")
+		appendPRTmpl  = fs.Bool("append-pr-template", false, "Append the repository's own PR template to the PR body")
+		signoff       = fs.Bool("signoff", false, "Add a Signed-off-by trailer to the commit (DCO)")
+		noContentTrl  = fs.Bool("no-content-trailer", false, "Do not add the "+apply.ContentTrailer+" trailer to the commit")
+		authorName    = fs.String("author-name", "", "Commit author name (default: git's user.name)")
+		authorEmail   = fs.String("author-email", "", "Commit author email (default: git's user.email)")
+		committerName = fs.String("committer-name", "", "Commit committer name (default: the author)")
+		committerMail = fs.String("committer-email", "", "Commit committer email (default: the author)")
+		sign          = fs.Bool("sign", false, "Sign the commit with git's configured signing key")
+		signingKey    = fs.String("signing-key", "", "Key to sign the commit with: a GPG key ID or an SSH key path")
+		signingFormat = fs.String("signing-format", "", "Signature format: openpgp, ssh or x509 (default: git's gpg.format)")
 		draft         = fs.Bool("draft", false, "Create PR as draft")
 		dryRun        = fs.Bool("dry-run", false, "Perform checks only, no changes")
 		remote        = fs.String("remote", "origin", "Git remote name")
+		defaultBranch = fs.String("default-branch", "", "Default branch name (skips detection)")
+		forgeName     = fs.String("forge", forge.DefaultName, "Hosting provider for PRs")
 		expectSHA256  = fs.String("expect-sha256", "", "Expected SHA-256 hash (required for match mode)")
 		showVersion   = fs.Bool("version", false, "Print version")
+		noPush        = fs.Bool("no-push", false, "Commit on the new branch but do not push or open a PR")
+		autoStash     = fs.Bool("autostash", false, "Stash local changes before applying and restore them afterwards")
+		exportPatch   = fs.String("export-patch", "", "Write the change as an mbox into this directory instead of pushing")
+		forgeURL      = fs.String("forge-url", "", "Forge API base URL (default: derived from the remote)")
+		topic         = fs.String("topic", "", "Topic to group the change under, where supported")
+		autoComplete  = fs.Bool("auto-complete", false, "Complete the PR once its policies pass, where supported")
+		removeSource  = fs.Bool("remove-source-branch", false, "Delete the PR branch once merged, where supported")
+		noCodeOwners  = fs.Bool("no-codeowners", false, "Do not request reviews from the file's CODEOWNERS")
+		milestone     = fs.String("milestone", "", "Milestone title or number to add the PR to")
+		autoMerge     = fs.Bool("auto-merge", false, "Enable auto-merge on the created PR")
+		mergeMethod   = fs.String("merge-method", config.DefaultMergeMethod, "Merge method for auto-merge: merge, squash or rebase")
+		fork          = fs.Bool("fork", false, "Push the branch to your fork and open the PR from there")
+		forkRemote    = fs.String("fork-remote", config.DefaultForkRemote, "Git remote name for the fork")
+		trackingRepo  = fs.String("tracking-repo", "", "URL of the repository holding the campaign's tracking issue")
+		trackingTitle = fs.String("tracking-title", "", "Title of the tracking issue (default: derived from the branch)")
+		trackingForge = fs.String("tracking-forge", "", "Hosting provider of the tracking repository (default: auto)")
+		direct        bool
+		bases         stringSliceFlag
+		forgeHosts    stringSliceFlag
+		labels        stringSliceFlag
+		assignees     stringSliceFlag
+		reviewers     stringSliceFlag
+		projects      stringSliceFlag
+		hashtags      stringSliceFlag
+		workItems     stringSliceFlag
+		trailers      stringSliceFlag
+		coAuthors     stringSliceFlag
 	)
-
-	// Check for subcommand
-	if len(args) == 0 {
-		printUsage(fs)
-		return exitInvalidUsage
-	}
-
-	// Handle "apply" subcommand
-	if args[0] == "apply" {
-		args = args[1:]
-	} else if args[0] == "-version" || args[0] == "--version" {
-		fmt.Println(versionString())
-		return exitSuccess
-	} else if args[0] != "-h" && args[0] != "--help" && args[0] != "-help" {
-		fmt.Fprintf(os.Stderr, "Error: unknown command %q\n", args[0])
-		fmt.Fprintf(os.Stderr, "Usage: bulkfilepr apply [options]\n")
-		return exitInvalidUsage
-	}
+	fs.BoolVar(&direct, "direct", false, "Commit onto the base branch and push it without a PR")
+	fs.BoolVar(&direct, "no-pr", false, "Alias for --direct")
+	fs.Var(&bases, "base", "Base branch or glob to branch from and target (repeatable)")
+	fs.Var(&forgeHosts, "forge-host", "Map a remote host to a forge as host=forge (repeatable)")
+	fs.Var(&labels, "label", "Label to add to the PR (repeatable)")
+	fs.Var(&assignees, "assignee", "User to assign to the PR (repeatable)")
+	fs.Var(&reviewers, "reviewer", "User to request a review from (repeatable)")
+	fs.Var(&projects, "project", "Project board number, or owner/number, to add the PR to (repeatable)")
+	fs.Var(&trailers, "trailer", "Commit trailer as key=value (repeatable)")
+	fs.Var(&coAuthors, "co-author", "Co-author as \"Name <email>\" (repeatable)")
+	fs.Var(&workItems, "work-item", "Work item ID to link to the PR, where supported (repeatable)")
+	fs.Var(&hashtags, "hashtag", "Hashtag to attach to the change, where supported (repeatable)")
 
 	// Parse flags
 	if err := fs.Parse(args); err != nil {
@@ -111,20 +180,74 @@ func run(args []string) int {
 		return exitInvalidUsage
 	}
 
+	// Read the message templates, which are validated with the config
+	commitMessageTemplate, err := readTemplateFile(*commitMsgFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to read commit message file: %v\n", err)
+		return exitOperational
+	}
+	prBodyTemplate, err := readTemplateFile(*prBodyFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to read PR body file: %v\n", err)
+		return exitOperational
+	}
+
 	// Build config
 	cfg := &config.Config{
-		Mode:          parsedMode,
-		RepoPath:      *repoPath,
-		NewFile:       *newFile,
-		Repo:          *repo,
-		Branch:        *branch,
-		CommitMessage: *commitMessage,
-		PRTitle:       *prTitle,
-		PRBody:        *prBody,
-		Draft:         *draft,
-		DryRun:        *dryRun,
-		Remote:        *remote,
-		ExpectSHA256:  *expectSHA256,
+		Mode:           parsedMode,
+		RepoPath:       *repoPath,
+		NewFile:        *newFile,
+		Repo:           *repo,
+		Branch:         *branch,
+		CommitMessage:  *commitMessage,
+		PRTitle:        *prTitle,
+		PRBody:         *prBody,
+		Draft:          *draft,
+		DryRun:         *dryRun,
+		Remote:         *remote,
+		DefaultBranch:  *defaultBranch,
+		ExpectSHA256:   *expectSHA256,
+		BranchTemplate: *branchTmpl,
+		BranchPrefix:   *branchPrefix,
+		Direct:         direct,
+		NoPush:         *noPush,
+		ExportPatch:    *exportPatch,
+		AutoStash:      *autoStash,
+		Bases:          bases,
+		Labels:         labels,
+		Assignees:      assignees,
+		Reviewers:      reviewers,
+		NoCodeOwners:   *noCodeOwners,
+		Milestone:      *milestone,
+		Projects:       projects,
+		WorkItems:      workItems,
+		AutoComplete:   *autoComplete,
+		Topic:          *topic,
+		Hashtags:       hashtags,
+		AutoMerge:      *autoMerge,
+		MergeMethod:    *mergeMethod,
+		Fork:           *fork,
+		ForkRemote:     *forkRemote,
+		TrackingRepo:   *trackingRepo,
+		TrackingTitle:  *trackingTitle,
+		TrackingForge:  *trackingForge,
+		Version:        versionString(),
+
+		RemoveSourceBranch:    *removeSource,
+		CommitMessageTemplate: commitMessageTemplate,
+		PRBodyTemplate:        prBodyTemplate,
+		AppendPRTemplate:      *appendPRTmpl,
+		Signoff:               *signoff,
+		Trailers:              trailers,
+		CoAuthors:             coAuthors,
+		NoContentTrailer:      *noContentTrl,
+		AuthorName:            *authorName,
+		AuthorEmail:           *authorEmail,
+		CommitterName:         *committerName,
+		CommitterEmail:        *committerMail,
+		Sign:                  *sign,
+		SigningKey:            *signingKey,
+		SigningFormat:         *signingFormat,
 	}
 
 	// Validate config
@@ -143,19 +266,429 @@ func run(args []string) int {
 	// Create git operations
 	gitOps := git.NewRealOperations(*repo)
 
+	// Create forge
+	forgeOps, err := newForge(gitOps, *forgeName, forgeHosts, forge.Options{RepoDir: *repo, Remote: *remote, BaseURL: *forgeURL})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitInvalidUsage
+	}
+
 	// Create and run applier
-	applier := apply.NewApplier(cfg, gitOps, newContent)
-	result, err := applier.Run()
+	applier := apply.NewApplier(cfg, gitOps, forgeOps, newContent)
+	results, err := applier.RunAll()
+
+	// Print results, including those for bases completed before any failure
+	for i, result := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		printResult(cfg, result)
+	}
+
+	// Record the repository's state in the tracking issue
+	if cfg.TrackingRepo != "" && len(results) > 0 {
+		name := apply.RepoName(gitOps, *remote, *repo)
+		var entries []tracking.Entry
+		for _, result := range results {
+			entryName := name
+			if result.BaseBranch != result.DefaultBranch {
+				entryName = fmt.Sprintf("%s (%s)", name, result.BaseBranch)
+			}
+			if entry, ok := tracking.ResultEntry(entryName, result, cfg.Draft); ok {
+				entries = append(entries, entry)
+			}
+		}
+		updateTracking(cfg, forgeHosts, tracking.Title(cfg.TrackingTitle, applier.CampaignBranch()), entries)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitOperational
+	}
+	return exitSuccess
+}
+
+func runPublish(args []string) int {
+	fs := flag.NewFlagSet("bulkfilepr publish", flag.ContinueOnError)
+
+	// Define flags
+	var (
+		repo       = fs.String("repo", ".", "Repository directory")
+		branch     = fs.String("branch", "", "Only publish this branch (default: all pending branches)")
+		dryRun     = fs.Bool("dry-run", false, "List pending branches only, no changes")
+		remote     = fs.String("remote", "origin", "Git remote name")
+		forgeName  = fs.String("forge", forge.DefaultName, "Hosting provider for PRs")
+		forgeURL   = fs.String("forge-url", "", "Forge API base URL (default: derived from the remote)")
+		forkRemote = fs.String("fork-remote", config.DefaultForkRemote, "Git remote name for the fork")
+
+		forgeHosts stringSliceFlag
+	)
+	fs.Var(&forgeHosts, "forge-host", "Map a remote host to a forge as host=forge (repeatable)")
+	fs.Usage = printPublishUsage
+
+	// Parse flags
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitSuccess
+		}
+		return exitInvalidUsage
+	}
+
+	cfg := &config.Config{
+		Repo:       *repo,
+		Branch:     *branch,
+		DryRun:     *dryRun,
+		Remote:     *remote,
+		ForkRemote: *forkRemote,
+	}
+
+	// Create git operations
+	gitOps := git.NewRealOperations(*repo)
+
+	// Create forge
+	forgeOps, err := newForge(gitOps, *forgeName, forgeHosts, forge.Options{RepoDir: *repo, Remote: *remote, BaseURL: *forgeURL})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitInvalidUsage
+	}
+
+	// Create and run publisher
+	publisher := apply.NewPublisher(cfg, gitOps, forgeOps)
+	results, err := publisher.Run()
+
+	// Print results, including those for branches published before any failure
+	if err == nil && len(results) == 0 {
+		fmt.Println("No pending branches to publish")
+	}
+	for i, result := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		printPublishResult(result)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitOperational
+	}
+	return exitSuccess
+}
+
+func runStatus(args []string) int {
+	fs := flag.NewFlagSet("bulkfilepr status", flag.ContinueOnError)
+
+	// Define flags
+	var (
+		repo          = fs.String("repo", ".", "Repository directory")
+		branch        = fs.String("branch", "", "Campaign branch to report PRs for")
+		remote        = fs.String("remote", "origin", "Git remote name")
+		forgeName     = fs.String("forge", forge.DefaultName, "Hosting provider for PRs")
+		forgeURL      = fs.String("forge-url", "", "Forge API base URL (default: derived from the remote)")
+		trackingRepo  = fs.String("tracking-repo", "", "URL of the repository holding the campaign's tracking issue")
+		trackingTitle = fs.String("tracking-title", "", "Title of the tracking issue (default: derived from the branch)")
+		trackingForge = fs.String("tracking-forge", "", "Hosting provider of the tracking repository (default: auto)")
+
+		forgeHosts stringSliceFlag
+	)
+	fs.Var(&forgeHosts, "forge-host", "Map a remote host to a forge as host=forge (repeatable)")
+	fs.Usage = printRecordsUsage
+
+	// Parse flags
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitSuccess
+		}
+		return exitInvalidUsage
+	}
+
+	cfg := &config.Config{
+		Repo:          *repo,
+		Branch:        *branch,
+		Remote:        *remote,
+		TrackingRepo:  *trackingRepo,
+		TrackingTitle: *trackingTitle,
+		TrackingForge: *trackingForge,
+	}
+	if err := cfg.ValidateStatus(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitInvalidUsage
+	}
+
+	// Create git operations and forge
+	gitOps := git.NewRealOperations(*repo)
+	forgeOps, err := newForge(gitOps, *forgeName, forgeHosts, forge.Options{RepoDir: *repo, Remote: *remote, BaseURL: *forgeURL})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitInvalidUsage
+	}
+
+	prs, err := apply.Status(forgeOps, *branch)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return exitOperational
 	}
+	if len(prs) == 0 {
+		fmt.Println("No PRs found")
+	}
+	for _, pr := range prs {
+		printPullRequest(pr)
+	}
+
+	// Record the latest matching PR in the tracking issue
+	if cfg.TrackingRepo != "" {
+		name := apply.RepoName(gitOps, *remote, *repo)
+		var entries []tracking.Entry
+		for _, pr := range prs {
+			if cfg.Branch == "" || pr.Head == cfg.Branch {
+				entries = append(entries, tracking.RecordEntry(name, pr))
+			}
+		}
+		updateTracking(cfg, forgeHosts, tracking.Title(cfg.TrackingTitle, cfg.Branch), entries)
+	}
+	return exitSuccess
+}
+
+func runPrune(args []string) int {
+	fs := flag.NewFlagSet("bulkfilepr prune", flag.ContinueOnError)
+
+	// Define flags
+	var (
+		repo      = fs.String("repo", ".", "Repository directory")
+		dryRun    = fs.Bool("dry-run", false, "List stale records only, no changes")
+		remote    = fs.String("remote", "origin", "Git remote name")
+		forgeName = fs.String("forge", forge.DefaultName, "Hosting provider for PRs")
+		forgeURL  = fs.String("forge-url", "", "Forge API base URL (default: derived from the remote)")
+
+		forgeHosts stringSliceFlag
+	)
+	fs.Var(&forgeHosts, "forge-host", "Map a remote host to a forge as host=forge (repeatable)")
+	fs.Usage = printRecordsUsage
+
+	// Parse flags
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitSuccess
+		}
+		return exitInvalidUsage
+	}
+
+	cfg := &config.Config{
+		Repo:   *repo,
+		DryRun: *dryRun,
+		Remote: *remote,
+	}
+
+	// Create git operations and forge
+	gitOps := git.NewRealOperations(*repo)
+	forgeOps, err := newForge(gitOps, *forgeName, forgeHosts, forge.Options{RepoDir: *repo, Remote: *remote, BaseURL: *forgeURL})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitInvalidUsage
+	}
 
-	// Print result
-	printResult(cfg, result)
+	// Print records, including those pruned before any failure
+	pruned, err := apply.NewPruner(cfg, gitOps, forgeOps).Run()
+	if err == nil && len(pruned) == 0 {
+		fmt.Println("No stale records to prune")
+	}
+	verb := "Pruned"
+	if cfg.DryRun {
+		verb = "Would prune"
+	}
+	for _, pr := range pruned {
+		fmt.Printf("%s: ", verb)
+		printPullRequest(pr)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitOperational
+	}
 	return exitSuccess
 }
 
+func runMerge(args []string) int {
+	fs := flag.NewFlagSet("bulkfilepr merge", flag.ContinueOnError)
+
+	// Define flags
+	var (
+		branch         = fs.String("branch", "", "Head branch of the campaign PRs (required)")
+		dryRun         = fs.Bool("dry-run", false, "Report which PRs are ready only, no merges")
+		remote         = fs.String("remote", "origin", "Git remote name")
+		forgeName      = fs.String("forge", forge.DefaultName, "Hosting provider for PRs")
+		forgeURL       = fs.String("forge-url", "", "Forge API base URL (default: derived from the remote)")
+		mergeMethod    = fs.String("merge-method", config.DefaultMergeMethod, "Merge method: merge, squash or rebase")
+		maxConcurrency = fs.Int("max-concurrency", config.DefaultMaxConcurrency, "Number of repositories to work on at a time")
+		pause          = fs.Duration("pause", 0, "Minimum time between two merges")
+		pollInterval   = fs.Duration("poll-interval", config.DefaultPollInterval, "How often to check pending PRs again")
+		timeout        = fs.Duration("timeout", config.DefaultCheckTimeout, "How long to wait for pending checks")
+		trackingRepo   = fs.String("tracking-repo", "", "URL of the repository holding the campaign's tracking issue")
+		trackingTitle  = fs.String("tracking-title", "", "Title of the tracking issue (default: derived from the branch)")
+		trackingForge  = fs.String("tracking-forge", "", "Hosting provider of the tracking repository (default: auto)")
+
+		repos      stringSliceFlag
+		forgeHosts stringSliceFlag
+	)
+	fs.Var(&repos, "repo", "Repository directory (repeatable, default: .)")
+	fs.Var(&forgeHosts, "forge-host", "Map a remote host to a forge as host=forge (repeatable)")
+	fs.Usage = printMergeUsage
+
+	// Parse flags
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitSuccess
+		}
+		return exitInvalidUsage
+	}
+	if len(repos) == 0 {
+		repos = stringSliceFlag{"."}
+	}
+
+	cfg := &config.Config{
+		Branch:         *branch,
+		DryRun:         *dryRun,
+		Remote:         *remote,
+		MergeMethod:    *mergeMethod,
+		MaxConcurrency: *maxConcurrency,
+		MergePause:     *pause,
+		PollInterval:   *pollInterval,
+		CheckTimeout:   *timeout,
+		TrackingRepo:   *trackingRepo,
+		TrackingTitle:  *trackingTitle,
+		TrackingForge:  *trackingForge,
+	}
+	if err := cfg.ValidateMerge(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		printMergeUsage()
+		return exitInvalidUsage
+	}
+
+	// Each repository gets its own forge, since they may be hosted on different forges
+	newRepoForge := func(repo string) (forge.Forge, error) {
+		gitOps := git.NewRealOperations(repo)
+		return newForge(gitOps, *forgeName, forgeHosts, forge.Options{RepoDir: repo, Remote: *remote, BaseURL: *forgeURL})
+	}
+	results := apply.NewMerger(cfg, repos, newRepoForge).Run()
+
+	exitCode := exitSuccess
+	for i, result := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		printMergeResult(result)
+		if result.Action == "failed" {
+			exitCode = exitOperational
+		}
+	}
+
+	// Record the outcomes in the tracking issue, unless this was a dry run
+	if cfg.TrackingRepo != "" && !cfg.DryRun {
+		var entries []tracking.Entry
+		for _, result := range results {
+			name := apply.RepoName(git.NewRealOperations(result.Repo), *remote, result.Repo)
+			if entry, ok := tracking.MergeEntry(name, result); ok {
+				entries = append(entries, entry)
+			}
+		}
+		fmt.Println()
+		updateTracking(cfg, forgeHosts, tracking.Title(cfg.TrackingTitle, cfg.Branch), entries)
+	}
+	return exitCode
+}
+
+// printPullRequest prints a one-line summary of a PR.
+func printPullRequest(pr *forge.PullRequest) {
+	draft := ""
+	if pr.Draft {
+		draft = " (draft)"
+	}
+	fmt.Printf("#%d %s%s %s -> %s: %s %s\n", pr.Number, pr.State, draft, pr.Head, pr.Base, pr.Title, pr.URL)
+}
+
+// stringSliceFlag is a flag.Value that collects the values of a repeatable flag.
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// readTemplateFile returns the content of a template file, or "" if no file
+// was given.
+func readTemplateFile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// newForge creates the named forge for the repository, filling in the remote
+// URL and parsing host=forge mappings used by auto-selection.
+func newForge(gitOps git.Operations, name string, hosts []string, opts forge.Options) (forge.Forge, error) {
+	var err error
+	if opts.Hosts, err = parseForgeHosts(hosts); err != nil {
+		return nil, err
+	}
+
+	// A missing remote URL is not fatal: auto-selection falls back to GitHub
+	// and forges that need the URL report their own error
+	if url, err := gitOps.RemoteURL(opts.Remote); err == nil {
+		opts.RemoteURL = url
+	}
+	opts.Git = gitOps
+	return forge.New(name, opts)
+}
+
+// parseForgeHosts parses host=forge mappings used by forge auto-selection.
+func parseForgeHosts(hosts []string) (map[string]string, error) {
+	mappings := map[string]string{}
+	for _, mapping := range hosts {
+		host, forgeName, ok := strings.Cut(mapping, "=")
+		if !ok || host == "" || forgeName == "" {
+			return nil, fmt.Errorf("invalid forge-host: %q, must be host=forge", mapping)
+		}
+		mappings[strings.ToLower(host)] = forgeName
+	}
+	return mappings, nil
+}
+
+// updateTracking records the entries in the campaign's tracking issue. A
+// failure is reported as a warning, since the changes themselves were made.
+func updateTracking(cfg *config.Config, hosts []string, title string, entries []tracking.Entry) {
+	if len(entries) == 0 {
+		return
+	}
+	issue, err := trackIssue(cfg, hosts, title, entries)
+	if err != nil {
+		fmt.Printf("Warning: failed to update tracking issue: %v\n", err)
+		return
+	}
+	fmt.Printf("Tracking issue: %s\n", issue.URL)
+}
+
+// trackIssue creates the forge of the tracking repository and updates the issue.
+func trackIssue(cfg *config.Config, hosts []string, title string, entries []tracking.Entry) (*forge.Issue, error) {
+	mappings, err := parseForgeHosts(hosts)
+	if err != nil {
+		return nil, err
+	}
+	forgeOps, err := forge.New(cfg.GetTrackingForge(), forge.Options{RemoteURL: cfg.TrackingRepo, Hosts: mappings})
+	if err != nil {
+		return nil, err
+	}
+	tracker, ok := forgeOps.(forge.IssueTracker)
+	if !ok {
+		return nil, fmt.Errorf("forge %s does not support issues: %w", forgeOps.Name(), forge.ErrNotSupported)
+	}
+	return tracking.Update(tracker, title, entries)
+}
+
 var semverRe = regexp.MustCompile(`^\d+\.\d+\.\d+`)
 
 func versionString() string {
@@ -168,6 +701,10 @@ func versionString() string {
 
 func printUsage(fs *flag.FlagSet) {
 	fmt.Fprintln(os.Stderr, "Usage: bulkfilepr apply [options]")
+	fmt.Fprintln(os.Stderr, "       bulkfilepr publish [options]")
+	fmt.Fprintln(os.Stderr, "       bulkfilepr status [options]")
+	fmt.Fprintln(os.Stderr, "       bulkfilepr prune [options]")
+	fmt.Fprintln(os.Stderr, "       bulkfilepr merge --branch <name> [options]")
 	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintln(os.Stderr, "Batch-update standardized files across repositories.")
 	fmt.Fprintln(os.Stderr, "")
@@ -179,12 +716,64 @@ func printUsage(fs *flag.FlagSet) {
 	fmt.Fprintln(os.Stderr, "Optional options:")
 	fmt.Fprintln(os.Stderr, "  --repo <dir>          Repository directory (default: .)")
 	fmt.Fprintln(os.Stderr, "  --branch <name>       Branch name (auto-generated if empty)")
-	fmt.Fprintln(os.Stderr, "  --commit-message <msg> Commit message")
-	fmt.Fprintln(os.Stderr, "  --pr-title <title>    PR title")
-	fmt.Fprintln(os.Stderr, "  --pr-body <body>      PR body")
+	fmt.Fprintln(os.Stderr, "  --branch-template <tmpl> Template for auto-generated branch names")
+	fmt.Fprintln(os.Stderr, "                        (default: {prefix}/{path-slug}-{hash})")
+	fmt.Fprintln(os.Stderr, "  --branch-prefix <prefix> Value for {prefix} (default: bulkfilepr)")
+	fmt.Fprintln(os.Stderr, "  --commit-message <msg> Commit message, a Go template such as \"chore: update {{.Path}}\"")
+	fmt.Fprintln(os.Stderr, "  --commit-message-file <path> Go template file rendered into the commit message")
+	fmt.Fprintln(os.Stderr, "  --signoff             Add a Signed-off-by trailer to the commit (DCO)")
+	fmt.Fprintln(os.Stderr, "  --trailer <key=value> Commit trailer to add (repeatable)")
+	fmt.Fprintln(os.Stderr, "  --co-author <\"Name <email>\"> Add a Co-authored-by trailer (repeatable)")
+	fmt.Fprintln(os.Stderr, "  --no-content-trailer  Do not add the "+apply.ContentTrailer+" trailer")
+	fmt.Fprintln(os.Stderr, "  --author-name <name>  Commit author name (default: git's user.name)")
+	fmt.Fprintln(os.Stderr, "  --author-email <email> Commit author email (default: git's user.email)")
+	fmt.Fprintln(os.Stderr, "  --committer-name <name> Commit committer name (default: the author)")
+	fmt.Fprintln(os.Stderr, "  --committer-email <email> Commit committer email (default: the author)")
+	fmt.Fprintln(os.Stderr, "  --sign                Sign the commit with git's configured signing key")
+	fmt.Fprintln(os.Stderr, "  --signing-key <key>   Sign the commit with a GPG key ID or an SSH key path")
+	fmt.Fprintln(os.Stderr, "  --signing-format <format> Signature format: openpgp, ssh or x509")
+	fmt.Fprintln(os.Stderr, "                        (default: git's gpg.format)")
+	fmt.Fprintln(os.Stderr, "  --pr-title <title>    PR title, a Go template with the same fields as --pr-body-file")
+	fmt.Fprintln(os.Stderr, "  --pr-body <body>      PR body, a Go template with the same fields as --pr-body-file")
+	fmt.Fprintln(os.Stderr, "                        (default: a summary of the change)")
+	fmt.Fprintln(os.Stderr, "  --pr-body-file <path> Go template file rendered into the PR body, with {{.Repo}},")
+	fmt.Fprintln(os.Stderr, "                        {{.Path}}, {{.NewSHA256}}, {{.PRTemplate}} and other fields")
+	fmt.Fprintln(os.Stderr, "  --append-pr-template  Append the repository's own PR template to the PR body")
+	fmt.Fprintln(os.Stderr, "  --base <branch>       Base branch or glob to branch from and target (repeatable,")
+	fmt.Fprintln(os.Stderr, "                        default: the repository's default branch)")
 	fmt.Fprintln(os.Stderr, "  --draft               Create PR as draft")
+	fmt.Fprintln(os.Stderr, "  --direct, --no-pr     Commit onto the base branch and push it without a PR")
+	fmt.Fprintln(os.Stderr, "  --no-push             Commit on the new branch but do not push or open a PR")
+	fmt.Fprintln(os.Stderr, "                        (publish later with 'bulkfilepr publish')")
+	fmt.Fprintln(os.Stderr, "  --autostash           Stash local changes (including untracked files) before")
+	fmt.Fprintln(os.Stderr, "                        applying and restore them afterwards")
+	fmt.Fprintln(os.Stderr, "  --export-patch <dir>  Write the change as an mbox into <dir> instead of pushing")
 	fmt.Fprintln(os.Stderr, "  --dry-run             Perform checks only, no changes")
 	fmt.Fprintln(os.Stderr, "  --remote <name>       Git remote name (default: origin)")
+	fmt.Fprintln(os.Stderr, "  --default-branch <name> Default branch name (skips detection)")
+	fmt.Fprintln(os.Stderr, "  --forge <name>        Hosting provider for PRs (default: auto)")
+	fmt.Fprintln(os.Stderr, "  --forge-host <host=forge> Map a remote host to a forge for auto (repeatable)")
+	fmt.Fprintln(os.Stderr, "  --forge-url <url>     Forge API base URL (default: derived from the remote)")
+	fmt.Fprintln(os.Stderr, "  --label <name>        Label to add to the PR (repeatable)")
+	fmt.Fprintln(os.Stderr, "  --assignee <user>     User to assign to the PR (repeatable)")
+	fmt.Fprintln(os.Stderr, "  --reviewer <user>     User to request a review from (repeatable)")
+	fmt.Fprintln(os.Stderr, "  --no-codeowners       Do not request reviews from the file's CODEOWNERS")
+	fmt.Fprintln(os.Stderr, "  --milestone <name>    Milestone title or number to add the PR to")
+	fmt.Fprintln(os.Stderr, "  --project <project>   Project board <number> or <owner>/<number> to add the PR to (repeatable)")
+	fmt.Fprintln(os.Stderr, "  --work-item <id>      Work item to link to the PR (Azure DevOps, repeatable)")
+	fmt.Fprintln(os.Stderr, "  --auto-complete       Complete the PR once its policies pass (Azure DevOps)")
+	fmt.Fprintln(os.Stderr, "  --topic <name>        Topic to group the change under (Gerrit)")
+	fmt.Fprintln(os.Stderr, "  --hashtag <tag>       Hashtag to attach to the change (Gerrit, repeatable)")
+	fmt.Fprintln(os.Stderr, "  --remove-source-branch Delete the PR branch once merged, where supported")
+	fmt.Fprintln(os.Stderr, "  --auto-merge          Merge the PR automatically once its checks pass")
+	fmt.Fprintln(os.Stderr, "  --merge-method <method> Merge method for auto-merge: merge, squash or rebase")
+	fmt.Fprintln(os.Stderr, "                        (default: merge)")
+	fmt.Fprintln(os.Stderr, "  --fork                Push the branch to your fork (created if needed) and open")
+	fmt.Fprintln(os.Stderr, "                        the PR from there (GitHub, Gitea, Forgejo)")
+	fmt.Fprintln(os.Stderr, "  --fork-remote <name>  Git remote name for the fork (default: fork)")
+	fmt.Fprintln(os.Stderr, "  --tracking-repo <url> Record the result in a tracking issue in this repository")
+	fmt.Fprintln(os.Stderr, "  --tracking-title <title> Title of the tracking issue (default: derived from the branch)")
+	fmt.Fprintln(os.Stderr, "  --tracking-forge <name> Hosting provider of the tracking repository (default: auto)")
 	fmt.Fprintln(os.Stderr, "  --expect-sha256 <hex> Expected SHA-256 (required for match mode)")
 	fmt.Fprintln(os.Stderr, "                        Multiple hashes can be comma-separated")
 	fmt.Fprintln(os.Stderr, "  --version             Print version")
@@ -195,8 +784,77 @@ func printUsage(fs *flag.FlagSet) {
 	fmt.Fprintln(os.Stderr, "  match   - Only update if file exists and matches expected hash")
 }
 
+func printPublishUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: bulkfilepr publish [options]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Push branches created with 'bulkfilepr apply --no-push' and open their PRs.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Optional options:")
+	fmt.Fprintln(os.Stderr, "  --repo <dir>          Repository directory (default: .)")
+	fmt.Fprintln(os.Stderr, "  --branch <name>       Only publish this branch (default: all pending branches)")
+	fmt.Fprintln(os.Stderr, "  --dry-run             List pending branches only, no changes")
+	fmt.Fprintln(os.Stderr, "  --remote <name>       Git remote name (default: origin)")
+	fmt.Fprintln(os.Stderr, "  --forge <name>        Hosting provider for PRs (default: auto)")
+	fmt.Fprintln(os.Stderr, "  --forge-host <host=forge> Map a remote host to a forge for auto (repeatable)")
+	fmt.Fprintln(os.Stderr, "  --forge-url <url>     Forge API base URL (default: derived from the remote)")
+	fmt.Fprintln(os.Stderr, "  --fork-remote <name>  Git remote name for the fork (default: fork)")
+}
+
+func printMergeUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: bulkfilepr merge --branch <name> [options]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Wait for the checks of a campaign's PRs and merge those that are ready.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Required options:")
+	fmt.Fprintln(os.Stderr, "  --branch <name>       Head branch of the campaign PRs")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Optional options:")
+	fmt.Fprintln(os.Stderr, "  --repo <dir>          Repository directory (repeatable, default: .)")
+	fmt.Fprintln(os.Stderr, "  --dry-run             Report which PRs are ready only, no merges")
+	fmt.Fprintln(os.Stderr, "  --merge-method <m>    Merge method: merge, squash or rebase (default: merge)")
+	fmt.Fprintln(os.Stderr, "  --max-concurrency <n> Number of repositories to work on at a time (default: 1)")
+	fmt.Fprintln(os.Stderr, "  --pause <duration>    Minimum time between two merges (default: 0s)")
+	fmt.Fprintln(os.Stderr, "  --poll-interval <duration> How often to check pending PRs again (default: 30s)")
+	fmt.Fprintln(os.Stderr, "  --timeout <duration>  How long to wait for pending checks (default: 30m)")
+	fmt.Fprintln(os.Stderr, "  --remote <name>       Git remote name (default: origin)")
+	fmt.Fprintln(os.Stderr, "  --forge <name>        Hosting provider for PRs (default: auto)")
+	fmt.Fprintln(os.Stderr, "  --forge-host <host=forge> Map a remote host to a forge for auto (repeatable)")
+	fmt.Fprintln(os.Stderr, "  --forge-url <url>     Forge API base URL (default: derived from the remote)")
+	fmt.Fprintln(os.Stderr, "  --tracking-repo <url> Record the results in a tracking issue in this repository")
+	fmt.Fprintln(os.Stderr, "  --tracking-title <title> Title of the tracking issue (default: derived from the branch)")
+	fmt.Fprintln(os.Stderr, "  --tracking-forge <name> Hosting provider of the tracking repository (default: auto)")
+}
+
+func printRecordsUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: bulkfilepr status [options]")
+	fmt.Fprintln(os.Stderr, "       bulkfilepr prune [options]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "List the PRs recorded by a forge that keeps records (such as --forge local), or")
+	fmt.Fprintln(os.Stderr, "the open PR for --branch on other forges. Prune removes records that are closed")
+	fmt.Fprintln(os.Stderr, "or merged, or whose branch no longer exists.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Optional options:")
+	fmt.Fprintln(os.Stderr, "  --repo <dir>          Repository directory (default: .)")
+	fmt.Fprintln(os.Stderr, "  --dry-run             List stale records only, no changes (prune only)")
+	fmt.Fprintln(os.Stderr, "  --remote <name>       Git remote name (default: origin)")
+	fmt.Fprintln(os.Stderr, "  --forge <name>        Hosting provider for PRs (default: auto)")
+	fmt.Fprintln(os.Stderr, "  --forge-host <host=forge> Map a remote host to a forge for auto (repeatable)")
+	fmt.Fprintln(os.Stderr, "  --forge-url <url>     Forge API base URL, or record directory for local")
+	fmt.Fprintln(os.Stderr, "  --branch <name>       Campaign branch to report PRs for (status only, required")
+	fmt.Fprintln(os.Stderr, "                        for forges that do not keep records)")
+	fmt.Fprintln(os.Stderr, "  --tracking-repo <url> Record the PRs in a tracking issue in this repository (status only)")
+	fmt.Fprintln(os.Stderr, "  --tracking-title <title> Title of the tracking issue (default: derived from the branch)")
+	fmt.Fprintln(os.Stderr, "  --tracking-forge <name> Hosting provider of the tracking repository (default: auto)")
+}
+
 func printResult(cfg *config.Config, result *apply.Result) {
 	fmt.Printf("Default branch: %s\n", result.DefaultBranch)
+	if result.DefaultBranchSource != "" {
+		fmt.Printf("Default branch source: %s\n", result.DefaultBranchSource)
+	}
+	if result.BaseBranch != "" && result.BaseBranch != result.DefaultBranch {
+		fmt.Printf("Base branch: %s\n", result.BaseBranch)
+	}
 
 	switch result.Action {
 	case "no action taken":
@@ -212,10 +870,84 @@ func printResult(cfg *config.Config, result *apply.Result) {
 		fmt.Printf("Action: branch already exists (idempotent - no action taken)\n")
 		fmt.Printf("Branch: %s\n", result.BranchName)
 		fmt.Printf("Reason: branch already exists, assuming previous successful run\n")
+	case "would push":
+		fmt.Printf("Mode: %s\n", cfg.Mode)
+		fmt.Printf("Action: would push directly (dry run)\n")
+		fmt.Printf("Branch: %s\n", result.BranchName)
+	case "pushed":
+		fmt.Printf("Mode: %s\n", cfg.Mode)
+		fmt.Printf("Action: pushed directly\n")
+		fmt.Printf("Branch: %s\n", result.BranchName)
+		fmt.Printf("Commit: %s\n", result.CommitSHA)
+	case "would export":
+		fmt.Printf("Mode: %s\n", cfg.Mode)
+		fmt.Printf("Action: would export patch (dry run)\n")
+		fmt.Printf("Branch: %s\n", result.BranchName)
+		fmt.Printf("Patch: %s\n", result.PatchFile)
+	case "exported":
+		fmt.Printf("Mode: %s\n", cfg.Mode)
+		fmt.Printf("Action: exported patch\n")
+		fmt.Printf("Branch: %s (deleted after export)\n", result.BranchName)
+		fmt.Printf("Patch: %s\n", result.PatchFile)
+	case "committed":
+		fmt.Printf("Mode: %s\n", cfg.Mode)
+		fmt.Printf("Action: committed (not pushed)\n")
+		fmt.Printf("Branch: %s\n", result.BranchName)
+		fmt.Printf("Commit: %s\n", result.CommitSHA)
+		fmt.Printf("Next: run 'bulkfilepr publish' to push and open the PR\n")
 	case "updated":
 		fmt.Printf("Mode: %s\n", cfg.Mode)
 		fmt.Printf("Action: updated\n")
 		fmt.Printf("Branch: %s\n", result.BranchName)
 		fmt.Printf("PR URL: %s\n", result.PRURL)
 	}
+	printWarnings(result)
+	if result.Stashed {
+		fmt.Printf("Autostash: local changes were stashed and restored\n")
+	}
+}
+
+func printPublishResult(result *apply.Result) {
+	fmt.Printf("Branch: %s\n", result.BranchName)
+	fmt.Printf("Base branch: %s\n", result.BaseBranch)
+
+	switch result.Action {
+	case "would publish":
+		fmt.Printf("Action: would publish (dry run)\n")
+	case "updated":
+		fmt.Printf("Action: published\n")
+		fmt.Printf("PR URL: %s\n", result.PRURL)
+	}
+	printWarnings(result)
+}
+
+func printMergeResult(result *apply.MergeResult) {
+	fmt.Printf("Repo: %s\n", result.Repo)
+	fmt.Printf("Branch: %s\n", result.Branch)
+	if result.PRURL != "" {
+		fmt.Printf("PR URL: %s\n", result.PRURL)
+	}
+
+	switch result.Action {
+	case "would merge":
+		fmt.Printf("Action: would merge (dry run)\n")
+	default:
+		fmt.Printf("Action: %s\n", result.Action)
+	}
+	if result.Reason != "" {
+		fmt.Printf("Reason: %s\n", result.Reason)
+	}
+}
+
+// printWarnings prints the auto-merge outcome and the PR metadata that could
+// not be applied.
+func printWarnings(result *apply.Result) {
+	if result.AutoMerge {
+		fmt.Printf("Auto-merge: enabled\n")
+	} else if result.AutoMergeReason != "" {
+		fmt.Printf("Auto-merge: not enabled (%s)\n", result.AutoMergeReason)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
 }
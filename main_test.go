@@ -1,9 +1,15 @@
 package main
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/forge"
+	"github.com/UnitVectorY-Labs/bulkfilepr/internal/git"
 )
 
 func TestRunMissingCommand(t *testing.T) {
@@ -107,3 +113,81 @@ func TestVersionStringAddsVPrefixWhenMissing(t *testing.T) {
 		t.Errorf("versionString() = %q, want prefix %q", got, "bulkfilepr version v1.2.3 ")
 	}
 }
+
+func TestRunPublishHelp(t *testing.T) {
+	exitCode := run([]string{"publish", "-h"})
+	if exitCode != exitSuccess {
+		t.Errorf("run([publish -h]) = %d, want %d", exitCode, exitSuccess)
+	}
+}
+
+func TestRunApplyDirectWithNoPush(t *testing.T) {
+	exitCode := run([]string{"apply", "--mode", "upsert", "--repo-path", "test.txt", "--new-file", "test.txt", "--direct", "--no-push"})
+	if exitCode != exitInvalidUsage {
+		t.Errorf("run() = %d, want %d", exitCode, exitInvalidUsage)
+	}
+}
+
+func TestNewForgeHostMapping(t *testing.T) {
+	gitOps := git.NewMockOperations()
+	gitOps.RemoteURLs = map[string]string{"origin": "git@git.example.com:group/repo.git"}
+
+	f, err := newForge(gitOps, forge.AutoName, []string{"Git.Example.com=gitlab"}, forge.Options{Remote: "origin"})
+	if err != nil {
+		t.Fatalf("newForge() error = %v", err)
+	}
+	if f.Name() != forge.GitLabName {
+		t.Errorf("Name() = %q, want %q", f.Name(), forge.GitLabName)
+	}
+
+	if _, err := newForge(gitOps, forge.AutoName, []string{"git.example.com"}, forge.Options{Remote: "origin"}); err == nil {
+		t.Error("newForge() expected error for mapping without forge, got nil")
+	}
+}
+
+func TestRunApplyWithLocalForge(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	remoteDir := filepath.Join(t.TempDir(), "remote.git")
+	cloneDir := filepath.Join(t.TempDir(), "clone")
+	gitCmds := [][]string{
+		{"init", "-q", "--bare", "-b", "main", remoteDir},
+		{"clone", "-q", remoteDir, cloneDir},
+		{"-C", cloneDir, "config", "user.name", "Test"},
+		{"-C", cloneDir, "config", "user.email", "test@example.com"},
+		{"-C", cloneDir, "commit", "-q", "--allow-empty", "-m", "initial"},
+		{"-C", cloneDir, "push", "-q", "origin", "main"},
+		{"-C", cloneDir, "remote", "set-head", "origin", "main"},
+	}
+	for _, args := range gitCmds {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v error = %v\n%s", args, err, output)
+		}
+	}
+	newFile := filepath.Join(t.TempDir(), "LICENSE")
+	if err := os.WriteFile(newFile, []byte("license\n"), 0644); err != nil {
+		t.Fatalf("failed to write new file: %v", err)
+	}
+
+	exitCode := run([]string{"apply", "--mode", "upsert", "--repo-path", "LICENSE", "--new-file", newFile, "--repo", cloneDir, "--forge", "local"})
+	if exitCode != exitSuccess {
+		t.Fatalf("run(apply) = %d, want %d", exitCode, exitSuccess)
+	}
+
+	// The PR is recorded inside the bare remote
+	record, err := os.ReadFile(filepath.Join(remoteDir, "bulkfilepr", "prs", "1.json"))
+	if err != nil {
+		t.Fatalf("failed to read PR record: %v", err)
+	}
+	if !strings.Contains(string(record), `"base": "main"`) || !strings.Contains(string(record), `"commit_sha"`) {
+		t.Errorf("record = %s, want base main and a commit SHA", record)
+	}
+
+	if exitCode := run([]string{"status", "--repo", cloneDir, "--forge", "local"}); exitCode != exitSuccess {
+		t.Errorf("run(status) = %d, want %d", exitCode, exitSuccess)
+	}
+	if exitCode := run([]string{"prune", "--repo", cloneDir, "--forge", "local", "--dry-run"}); exitCode != exitSuccess {
+		t.Errorf("run(prune) = %d, want %d", exitCode, exitSuccess)
+	}
+}